@@ -0,0 +1,288 @@
+// Package ast declares the node types of the JavaScript Abstract Syntax Tree
+package ast
+
+// Node is an interface representing any node in our Abstract Syntax Tree
+// Every AST node type must implement the Type method
+// Range comes from the embedded Span and locates the node in the source
+type Node interface {
+	Type() string
+	Range() Span
+}
+
+// Span is the range of source text a node was parsed from
+// Start and End are byte offsets, both zero for nodes built by hand
+type Span struct {
+	Start int // Offset of the first character
+	End   int // Offset just past the last character
+}
+
+// Range returns the span itself, giving every node embedding a Span its Range method
+func (s Span) Range() Span {
+	return s
+}
+
+// IsValid reports whether the span points at actual source text
+func (s Span) IsValid() bool {
+	return s.End > s.Start
+}
+
+// Program is the root node of our Abstract Syntax Tree
+// It contains all the top-level statements in the source file
+type Program struct {
+	Span
+	Body []Node // Array of top-level statements
+}
+
+func (p *Program) Type() string {
+	return "Program"
+}
+
+// FunctionDeclaration represents a JavaScript function definition
+// Example: function name(param1, param2 = defaultValue) { ... }
+type FunctionDeclaration struct {
+	Span
+	NameSpan Span        // Location of the function name
+	Name     string      // Function name
+	Params   []Parameter // Parameter names and default values
+	Body     []Node      // Function body statements
+	BodySpan Span        // Location of the braces around the body
+}
+
+func (f *FunctionDeclaration) Type() string {
+	return "FunctionDeclaration"
+}
+
+// Parameter represents a function parameter with optional default value
+// Its Span covers the name and the default value, NameSpan only the name
+type Parameter struct {
+	Span
+	NameSpan     Span   // Location of the parameter name
+	Name         string // Parameter name
+	DefaultValue Node   // Default value (nil if no default)
+}
+
+// Parameter is not a statement or expression, but implementing Node lets
+// traversals visit it through a *Parameter pointer into FunctionDeclaration.Params
+func (p *Parameter) Type() string {
+	return "Parameter"
+}
+
+// ReturnStatement represents a 'return' statement in JavaScript
+// Example: return expression;
+type ReturnStatement struct {
+	Span
+	Argument Node // The value being returned (can be nil)
+}
+
+func (r *ReturnStatement) Type() string {
+	return "ReturnStatement"
+}
+
+// Identifier represents a variable or function name
+// Examples: x, myFunction, etc.
+type Identifier struct {
+	Span
+	Name string // The name of the identifier
+}
+
+func (i *Identifier) Type() string {
+	return "Identifier"
+}
+
+// StringLiteral represents a string value in the code
+// Examples: "hello", 'world'
+type StringLiteral struct {
+	Span
+	Value string // The actual string value without quotes
+}
+
+func (s *StringLiteral) Type() string {
+	return "StringLiteral"
+}
+
+// VariableDeclaration represents a variable declaration
+// Examples: const x = 5; let name = "value";
+type VariableDeclaration struct {
+	Span
+	NameSpan Span   // Location of the variable name
+	Kind     string // Declaration type: "const", "let", or "var"
+	Name     string // Variable name
+	Value    Node   // Initial value (can be nil)
+}
+
+func (v *VariableDeclaration) Type() string {
+	return "VariableDeclaration"
+}
+
+// Comment represents a code comment
+// Example: // This is a comment
+type Comment struct {
+	Span
+	Text string // The full text of the comment including //
+}
+
+func (c *Comment) Type() string {
+	return "Comment"
+}
+
+// IfStatement represents an if conditional statement
+// Example: if (condition) { ... }
+type IfStatement struct {
+	Span
+	Test           Node   // The condition being tested
+	Consequent     []Node // Statements to execute if condition is true
+	ConsequentSpan Span   // Location of the braces around the consequent
+}
+
+func (i *IfStatement) Type() string {
+	return "IfStatement"
+}
+
+// BinaryExpression represents expressions with two operands and an operator
+// Examples: a == b, x + y
+type BinaryExpression struct {
+	Span
+	Left     Node   // Left operand
+	Operator string // Operator (e.g., "==", "+")
+	Right    Node   // Right operand
+}
+
+func (b *BinaryExpression) Type() string {
+	return "BinaryExpression"
+}
+
+// CallExpression represents a function call
+// Example: checkAge(21)
+type CallExpression struct {
+	Span
+	Callee    Node   // Expression evaluating to the function, usually an Identifier
+	Arguments []Node // Argument expressions in order
+}
+
+func (c *CallExpression) Type() string {
+	return "CallExpression"
+}
+
+// MemberExpression represents reading a property, named after a dot or computed in brackets
+// Examples: console.log, Math.PI, items[i]
+type MemberExpression struct {
+	Span
+	Object       Node   // Expression evaluating to the object
+	Property     string // Property name after a dot
+	PropertySpan Span   // Location of the property name
+	Index        Node   // Expression in brackets giving the property name, nil after a dot
+}
+
+func (m *MemberExpression) Type() string {
+	return "MemberExpression"
+}
+
+// NewExpression represents a constructor call
+// Example: new Map(), new Date(2024, 0, 1)
+type NewExpression struct {
+	Span
+	Callee    Node   // Expression evaluating to the constructor
+	Arguments []Node // Argument expressions in order
+}
+
+func (n *NewExpression) Type() string {
+	return "NewExpression"
+}
+
+// ArrayExpression represents an array literal
+// Example: [1, "two", x]
+type ArrayExpression struct {
+	Span
+	Elements []Node
+}
+
+func (a *ArrayExpression) Type() string {
+	return "ArrayExpression"
+}
+
+// ObjectExpression represents an object literal
+// Example: { name: "Ada", "full name": x, age }
+type ObjectExpression struct {
+	Span
+	Properties []Property
+}
+
+func (o *ObjectExpression) Type() string {
+	return "ObjectExpression"
+}
+
+// Property is a key and value of an object literal
+// Its Span covers the key and the value; a shorthand property like { age } has an
+// Identifier value sharing the span of the key
+type Property struct {
+	Span
+	KeySpan Span   // Location of the key
+	Key     string // Property name, from an identifier, a string or a number
+	Value   Node
+}
+
+// Property is not an expression, but implementing Node lets traversals visit it through a
+// *Property pointer into ObjectExpression.Properties
+func (p *Property) Type() string {
+	return "Property"
+}
+
+// ExpressionStatement represents an expression evaluated for its effect
+// Examples: greet("Ada"); total = total + 1;
+type ExpressionStatement struct {
+	Span
+	Expression Node
+}
+
+func (e *ExpressionStatement) Type() string {
+	return "ExpressionStatement"
+}
+
+// BooleanLiteral represents true or false
+type BooleanLiteral struct {
+	Span
+	Value bool
+}
+
+func (b *BooleanLiteral) Type() string {
+	return "BooleanLiteral"
+}
+
+// NullLiteral represents null
+type NullLiteral struct {
+	Span
+}
+
+func (n *NullLiteral) Type() string {
+	return "NullLiteral"
+}
+
+// NumericLiteral represents numeric values in the code
+// Example: 1, 3.14
+type NumericLiteral struct {
+	Span
+	Value string // The numeric value
+}
+
+func (n *NumericLiteral) Type() string {
+	return "NumericLiteral"
+}
+
+// ErrorNode is a placeholder for a statement that could not be parsed
+type ErrorNode struct {
+	Span           // Source skipped during error recovery
+	Message string // Description of the syntax error
+}
+
+func (e *ErrorNode) Type() string {
+	return "ErrorNode"
+}
+
+// InvalidExpression is a placeholder for an expression that could not be parsed
+type InvalidExpression struct {
+	Span // Offending token, empty when nothing was consumed
+}
+
+func (i *InvalidExpression) Type() string {
+	return "InvalidExpression"
+}
@@ -0,0 +1,29 @@
+package ast
+
+import "sort"
+
+// Lines maps the byte offsets of spans to line and column numbers
+// It holds the offset at which each line of a source text starts
+type Lines []int
+
+// NewLines indexes the lines of src
+func NewLines(src string) Lines {
+	lines := Lines{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// Position converts an offset into a 1-based line and a 1-based column counted in bytes
+func (l Lines) Position(offset int) (line, column int) {
+	i := sort.SearchInts(l, offset+1) - 1
+	return i + 1, offset - l[i] + 1
+}
+
+// Start returns the offset at which a 1-based line starts
+func (l Lines) Start(line int) int {
+	return l[line-1]
+}
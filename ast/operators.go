@@ -0,0 +1,29 @@
+package ast
+
+// CallPrecedence is the binding power of a call or a property access, tighter than any binary operator
+// A callee or object binding looser, such as a + b in (a + b)() or (a + b).c, needs parentheses
+const CallPrecedence = 17
+
+// Precedence returns the binding power of a binary operator, higher binds tighter
+// The values follow the JavaScript operator precedence table, 0 means not a binary operator
+func Precedence(operator string) int {
+	switch operator {
+	case "*", "/", "%":
+		return 13
+	case "+", "-":
+		return 12
+	case "<", ">", "<=", ">=":
+		return 10
+	case "==", "!=", "===", "!==":
+		return 9
+	case "=":
+		return 2
+	}
+	return 0
+}
+
+// IsRightAssociative reports whether a chain of operator groups from the right
+// a = b = c means a = (b = c), while a - b - c means (a - b) - c
+func IsRightAssociative(operator string) bool {
+	return operator == "="
+}
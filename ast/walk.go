@@ -0,0 +1,133 @@
+package ast
+
+// Visitor is called by Walk for every node it encounters
+// If Visit returns a non-nil visitor w, Walk visits each child of node with w,
+// followed by a call of w.Visit(nil) once all children are done
+// Returning nil skips the children of node
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order, in the same order as the source
+// Children are visited in field order: FunctionDeclaration parameters (as *Parameter,
+// whose child is the default value) come before its body, IfStatement test before its consequent
+// BinaryExpression left before right, CallExpression and NewExpression callee before their
+// arguments, MemberExpression object before its index and ObjectExpression properties
+// (as *Property, whose child is the value) in order;
+// nil children are skipped
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		walkList(v, n.Body)
+	case *FunctionDeclaration:
+		for i := range n.Params {
+			Walk(v, &n.Params[i])
+		}
+		walkList(v, n.Body)
+	case *Parameter:
+		walkChild(v, n.DefaultValue)
+	case *IfStatement:
+		walkChild(v, n.Test)
+		walkList(v, n.Consequent)
+	case *ReturnStatement:
+		walkChild(v, n.Argument)
+	case *VariableDeclaration:
+		walkChild(v, n.Value)
+	case *BinaryExpression:
+		walkChild(v, n.Left)
+		walkChild(v, n.Right)
+	case *CallExpression:
+		walkChild(v, n.Callee)
+		walkList(v, n.Arguments)
+	case *MemberExpression:
+		walkChild(v, n.Object)
+		walkChild(v, n.Index)
+	case *NewExpression:
+		walkChild(v, n.Callee)
+		walkList(v, n.Arguments)
+	case *ArrayExpression:
+		walkList(v, n.Elements)
+	case *ObjectExpression:
+		for i := range n.Properties {
+			Walk(v, &n.Properties[i])
+		}
+	case *Property:
+		walkChild(v, n.Value)
+	case *ExpressionStatement:
+		walkChild(v, n.Expression)
+	case *Identifier, *StringLiteral, *NumericLiteral, *BooleanLiteral, *NullLiteral,
+		*Comment, *ErrorNode, *InvalidExpression:
+		// Leaf nodes have no children
+	}
+
+	v.Visit(nil)
+}
+
+// walkList walks every node of a statement list
+func walkList(v Visitor, list []Node) {
+	for _, node := range list {
+		walkChild(v, node)
+	}
+}
+
+// walkChild walks node unless it is nil
+func walkChild(v Visitor, node Node) {
+	if node != nil {
+		Walk(v, node)
+	}
+}
+
+// inspector adapts a plain function to the Visitor interface
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order
+// It starts by calling f(node); if f returns true, Inspect visits the children of node
+// and then calls f(nil)
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Traverse walks an AST calling enter before the children of each node and leave after them
+// Returning false from enter skips the children; leave is still called for that node
+// Either callback may be nil
+func Traverse(node Node, enter func(Node) bool, leave func(Node)) {
+	Walk(&traverser{enter: enter, leave: leave}, node)
+}
+
+// traverser implements Traverse on top of Walk by remembering the entered nodes
+type traverser struct {
+	enter func(Node) bool
+	leave func(Node)
+	stack []Node // Nodes whose children are being visited
+}
+
+func (t *traverser) Visit(node Node) Visitor {
+	if node == nil {
+		// All children of the innermost entered node are done
+		last := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		if t.leave != nil {
+			t.leave(last)
+		}
+		return nil
+	}
+	if t.enter != nil && !t.enter(node) {
+		if t.leave != nil {
+			t.leave(node)
+		}
+		return nil
+	}
+	t.stack = append(t.stack, node)
+	return t
+}
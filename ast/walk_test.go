@@ -0,0 +1,141 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"goast/ast"
+	"goast/parser"
+)
+
+// src declares one of each kind of node that has children
+const src = `function f(a, b = 1) {
+  if (a < b) {
+    return g(a, [b]);
+  }
+  let o = { k: a.x, v: a[0] };
+  return new C(o);
+}
+`
+
+// label names a node by its type, or by its name or value for leaves
+func label(node ast.Node) string {
+	switch n := node.(type) {
+	case nil:
+		return "end"
+	case *ast.Identifier:
+		return n.Name
+	case *ast.NumericLiteral:
+		return n.Value
+	case *ast.Parameter:
+		return "param " + n.Name
+	case *ast.Property:
+		return "prop " + n.Key
+	case *ast.BinaryExpression:
+		return n.Operator
+	}
+	return node.Type()
+}
+
+// parse parses src, failing the test on syntax errors
+func parse(t *testing.T) *ast.Program {
+	t.Helper()
+	program, err := parser.ParseFile("walk.js", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program
+}
+
+// TestInspectOrder checks that nodes are visited in source order, each followed by its
+// children
+func TestInspectOrder(t *testing.T) {
+	var got []string
+	ast.Inspect(parse(t), func(node ast.Node) bool {
+		if node != nil {
+			got = append(got, label(node))
+		}
+		return true
+	})
+	want := "Program FunctionDeclaration param a param b 1 IfStatement < a b ReturnStatement CallExpression g a ArrayExpression b " +
+		"VariableDeclaration ObjectExpression prop k MemberExpression a prop v MemberExpression a 0 ReturnStatement NewExpression C o"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got  %s\nwant %s", strings.Join(got, " "), want)
+	}
+}
+
+// TestInspectSkip checks that returning false skips the children of a node, and that every
+// visited node is closed by a call with nil
+func TestInspectSkip(t *testing.T) {
+	var got []string
+	ast.Inspect(parse(t), func(node ast.Node) bool {
+		got = append(got, label(node))
+		switch node.(type) {
+		case *ast.IfStatement, *ast.VariableDeclaration, *ast.ReturnStatement:
+			return false
+		}
+		return true
+	})
+	want := "Program FunctionDeclaration param a end param b 1 end end IfStatement VariableDeclaration ReturnStatement end end"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got  %s\nwant %s", strings.Join(got, " "), want)
+	}
+}
+
+// recorder is a Visitor recording the nodes it visits
+type recorder struct {
+	visits *[]string
+	depth  int
+}
+
+func (r recorder) Visit(node ast.Node) ast.Visitor {
+	*r.visits = append(*r.visits, strings.Repeat(".", r.depth)+label(node))
+	if _, ok := node.(*ast.FunctionDeclaration); ok {
+		return nil
+	}
+	return recorder{r.visits, r.depth + 1}
+}
+
+// TestWalkVisitor checks that the visitor returned for a node visits its children, and that
+// the nil call that closes the node goes to that visitor too
+func TestWalkVisitor(t *testing.T) {
+	program, err := parser.ParseFile("walk.js", "f(1);\nfunction g() {\n  h();\n}\n", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	ast.Walk(recorder{visits: &got}, program)
+	want := "Program .ExpressionStatement ..CallExpression ...f ....end ...1 ....end ...end ..end .FunctionDeclaration .end"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got  %s\nwant %s", strings.Join(got, " "), want)
+	}
+}
+
+// TestTraverse checks that leave is called after the children of each node, and also for
+// the nodes whose children enter skipped
+func TestTraverse(t *testing.T) {
+	program, err := parser.ParseFile("walk.js", "x = a + b;\nif (c) {\n  d;\n}\n", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	ast.Traverse(program, func(node ast.Node) bool {
+		got = append(got, "+"+label(node))
+		_, skip := node.(*ast.IfStatement)
+		return !skip
+	}, func(node ast.Node) {
+		got = append(got, "-"+label(node))
+	})
+	want := "+Program +ExpressionStatement += +x -x ++ +a -a +b -b -+ -= -ExpressionStatement +IfStatement -IfStatement -Program"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got  %s\nwant %s", strings.Join(got, " "), want)
+	}
+
+	// Either callback may be nil
+	count := 0
+	ast.Traverse(program, nil, func(ast.Node) { count++ })
+	ast.Traverse(program, func(ast.Node) bool { count++; return true }, nil)
+	if count != 2*11 {
+		t.Errorf("visited %d nodes twice, want 11 twice", count)
+	}
+}
@@ -0,0 +1,184 @@
+package astutil
+
+import (
+	"fmt"
+	"slices"
+
+	"goast/ast"
+)
+
+// list gives uniform access to the slice fields of the AST
+// Statement lists hold ast.Node values while parameters and properties are stored as
+// ast.Parameter and ast.Property values
+type list interface {
+	len() int
+	at(i int) ast.Node
+	set(i int, n ast.Node)
+	insert(i int, n ast.Node)
+	remove(i int)
+}
+
+// listOf returns the slice field name of parent
+func listOf(parent ast.Node, name string) list {
+	switch p := parent.(type) {
+	case *ast.Program:
+		if name == "Body" {
+			return nodeList{&p.Body}
+		}
+	case *ast.FunctionDeclaration:
+		switch name {
+		case "Body":
+			return nodeList{&p.Body}
+		case "Params":
+			return paramList{&p.Params}
+		}
+	case *ast.IfStatement:
+		if name == "Consequent" {
+			return nodeList{&p.Consequent}
+		}
+	case *ast.CallExpression:
+		if name == "Arguments" {
+			return nodeList{&p.Arguments}
+		}
+	case *ast.NewExpression:
+		if name == "Arguments" {
+			return nodeList{&p.Arguments}
+		}
+	case *ast.ArrayExpression:
+		if name == "Elements" {
+			return nodeList{&p.Elements}
+		}
+	case *ast.ObjectExpression:
+		if name == "Properties" {
+			return propertyList{&p.Properties}
+		}
+	}
+	panic(fmt.Sprintf("astutil: %s has no slice field %s", parent.Type(), name))
+}
+
+// nodeList is a slice of statements, call arguments or array elements
+type nodeList struct {
+	nodes *[]ast.Node
+}
+
+func (l nodeList) len() int              { return len(*l.nodes) }
+func (l nodeList) at(i int) ast.Node     { return (*l.nodes)[i] }
+func (l nodeList) set(i int, n ast.Node) { (*l.nodes)[i] = n }
+func (l nodeList) remove(i int)          { *l.nodes = slices.Delete(*l.nodes, i, i+1) }
+func (l nodeList) insert(i int, n ast.Node) {
+	*l.nodes = slices.Insert(*l.nodes, i, n)
+}
+
+// paramList is the parameter list of a function
+// Elements are handed out as pointers so edits through the cursor land in the slice
+type paramList struct {
+	params *[]ast.Parameter
+}
+
+func (l paramList) len() int              { return len(*l.params) }
+func (l paramList) at(i int) ast.Node     { return &(*l.params)[i] }
+func (l paramList) set(i int, n ast.Node) { (*l.params)[i] = *asParameter(n) }
+func (l paramList) remove(i int)          { *l.params = slices.Delete(*l.params, i, i+1) }
+func (l paramList) insert(i int, n ast.Node) {
+	*l.params = slices.Insert(*l.params, i, *asParameter(n))
+}
+
+// asParameter checks that a node stored into a parameter list is a parameter
+func asParameter(n ast.Node) *ast.Parameter {
+	param, ok := n.(*ast.Parameter)
+	if !ok {
+		panic(fmt.Sprintf("astutil: cannot store %T in FunctionDeclaration.Params", n))
+	}
+	return param
+}
+
+// propertyList is the property list of an object literal
+// Like parameters, elements are handed out as pointers into the slice
+type propertyList struct {
+	properties *[]ast.Property
+}
+
+func (l propertyList) len() int              { return len(*l.properties) }
+func (l propertyList) at(i int) ast.Node     { return &(*l.properties)[i] }
+func (l propertyList) set(i int, n ast.Node) { (*l.properties)[i] = *asProperty(n) }
+func (l propertyList) remove(i int)          { *l.properties = slices.Delete(*l.properties, i, i+1) }
+func (l propertyList) insert(i int, n ast.Node) {
+	*l.properties = slices.Insert(*l.properties, i, *asProperty(n))
+}
+
+// asProperty checks that a node stored into a property list is a property
+func asProperty(n ast.Node) *ast.Property {
+	prop, ok := n.(*ast.Property)
+	if !ok {
+		panic(fmt.Sprintf("astutil: cannot store %T in ObjectExpression.Properties", n))
+	}
+	return prop
+}
+
+// setField stores n in the single-node field name of parent
+func setField(parent ast.Node, name string, n ast.Node) {
+	switch p := parent.(type) {
+	case *rootNode:
+		p.Node = n
+		return
+	case *ast.Parameter:
+		if name == "DefaultValue" {
+			p.DefaultValue = n
+			return
+		}
+	case *ast.IfStatement:
+		if name == "Test" {
+			p.Test = n
+			return
+		}
+	case *ast.ReturnStatement:
+		if name == "Argument" {
+			p.Argument = n
+			return
+		}
+	case *ast.VariableDeclaration:
+		if name == "Value" {
+			p.Value = n
+			return
+		}
+	case *ast.BinaryExpression:
+		switch name {
+		case "Left":
+			p.Left = n
+			return
+		case "Right":
+			p.Right = n
+			return
+		}
+	case *ast.CallExpression:
+		if name == "Callee" {
+			p.Callee = n
+			return
+		}
+	case *ast.MemberExpression:
+		switch name {
+		case "Object":
+			p.Object = n
+			return
+		case "Index":
+			p.Index = n
+			return
+		}
+	case *ast.NewExpression:
+		if name == "Callee" {
+			p.Callee = n
+			return
+		}
+	case *ast.Property:
+		if name == "Value" {
+			p.Value = n
+			return
+		}
+	case *ast.ExpressionStatement:
+		if name == "Expression" {
+			p.Expression = n
+			return
+		}
+	}
+	panic(fmt.Sprintf("astutil: %s has no node field %s", parent.Type(), name))
+}
@@ -0,0 +1,218 @@
+// Package astutil rewrites ASTs in place with a cursor-based traversal
+// It mirrors Apply from golang.org/x/tools/go/ast/astutil
+package astutil
+
+import (
+	"fmt"
+
+	"goast/ast"
+)
+
+// ApplyFunc is called by Apply for each node with a Cursor describing it
+// Its result controls the traversal, see Apply
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses the AST rooted at root, calling pre before and post after the children of each node
+// Either function may be nil
+//
+// If pre returns false, the children of the node are skipped and post is not called for it
+// If post returns false, the whole traversal stops and Apply returns immediately
+//
+// Children are visited in the same order as ast.Walk, nil children are skipped
+// The Cursor passed to pre and post can replace, delete or insert nodes; nodes inserted or
+// used as replacements are not walked. Apply returns the possibly replaced root
+func Apply(root ast.Node, pre, post ApplyFunc) (result ast.Node) {
+	parent := &rootNode{Node: root}
+	defer func() {
+		if r := recover(); r != nil && r != errAbort {
+			panic(r)
+		}
+		result = parent.Node
+	}()
+	a := &application{pre: pre, post: post}
+	a.apply(parent, "Node", nil, root)
+	return
+}
+
+// errAbort is the panic value used to unwind when post returns false
+var errAbort = fmt.Errorf("astutil: traversal aborted")
+
+// rootNode holds the root so that it can be replaced like any other child
+type rootNode struct {
+	ast.Node
+}
+
+// Cursor describes a node encountered during Apply
+// It is only valid during the call of pre or post that received it
+type Cursor struct {
+	parent ast.Node  // Node holding the current node
+	name   string    // Name of the parent field holding the current node
+	iter   *iterator // Position in the parent slice, nil if the field is not a slice
+	node   ast.Node  // Current node
+}
+
+// iterator tracks the position in a slice while its elements are being changed
+type iterator struct {
+	index int // Index of the current element
+	step  int // How far to move after the current element, adjusted by Delete and Insert
+}
+
+// Node returns the current node
+func (c *Cursor) Node() ast.Node {
+	return c.node
+}
+
+// Parent returns the node holding the current node
+// For the root it is an internal wrapper, not an actual AST node
+func (c *Cursor) Parent() ast.Node {
+	return c.parent
+}
+
+// Name returns the name of the parent field holding the current node
+// For example "Body", "Params", "Test" or "Left"; the root is held by the field "Node"
+func (c *Cursor) Name() string {
+	return c.name
+}
+
+// Index returns the index of the current node in the parent slice, or -1 if it is not in a slice
+// The slices are Program.Body, FunctionDeclaration.Params and Body, IfStatement.Consequent
+// and CallExpression.Arguments
+func (c *Cursor) Index() int {
+	if c.iter == nil {
+		return -1
+	}
+	return c.iter.index
+}
+
+// Replace replaces the current node with n
+// In FunctionDeclaration.Params the replacement must be a *ast.Parameter, in
+// ObjectExpression.Properties a *ast.Property
+func (c *Cursor) Replace(n ast.Node) {
+	if c.iter != nil {
+		c.list().set(c.iter.index, n)
+	} else {
+		setField(c.parent, c.name, n)
+	}
+	c.node = n
+}
+
+// Delete deletes the current node from its parent slice
+// It panics if the current node is not part of a slice
+func (c *Cursor) Delete() {
+	i := c.checkSlice("Delete")
+	c.list().remove(i)
+	c.iter.step--
+}
+
+// InsertAfter inserts n after the current node in its parent slice
+// It panics if the current node is not part of a slice
+func (c *Cursor) InsertAfter(n ast.Node) {
+	i := c.checkSlice("InsertAfter")
+	c.list().insert(i+1, n)
+	c.iter.step++
+}
+
+// InsertBefore inserts n before the current node in its parent slice
+// It panics if the current node is not part of a slice
+func (c *Cursor) InsertBefore(n ast.Node) {
+	i := c.checkSlice("InsertBefore")
+	c.list().insert(i, n)
+	c.iter.index++
+}
+
+// checkSlice returns the index of the current node, panicking outside of slices
+func (c *Cursor) checkSlice(operation string) int {
+	if c.iter == nil {
+		panic(fmt.Sprintf("astutil: %s: %s.%s is not a slice", operation, c.parent.Type(), c.name))
+	}
+	return c.iter.index
+}
+
+// list returns the slice holding the current node
+func (c *Cursor) list() list {
+	return listOf(c.parent, c.name)
+}
+
+// application holds the state of one Apply call
+type application struct {
+	pre, post ApplyFunc
+	cursor    Cursor
+	iter      iterator
+}
+
+// apply visits n, held by the field name of parent
+func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.Node) {
+	// Reuse a single cursor, restoring the caller's view once this node is done
+	saved := a.cursor
+	a.cursor.parent = parent
+	a.cursor.name = name
+	a.cursor.iter = iter
+	a.cursor.node = n
+
+	if a.pre != nil && !a.pre(&a.cursor) {
+		a.cursor = saved
+		return
+	}
+
+	switch n := n.(type) {
+	case *ast.Program:
+		a.applyList(n, "Body")
+	case *ast.FunctionDeclaration:
+		a.applyList(n, "Params")
+		a.applyList(n, "Body")
+	case *ast.Parameter:
+		a.applyChild(n, "DefaultValue", n.DefaultValue)
+	case *ast.IfStatement:
+		a.applyChild(n, "Test", n.Test)
+		a.applyList(n, "Consequent")
+	case *ast.ReturnStatement:
+		a.applyChild(n, "Argument", n.Argument)
+	case *ast.VariableDeclaration:
+		a.applyChild(n, "Value", n.Value)
+	case *ast.BinaryExpression:
+		a.applyChild(n, "Left", n.Left)
+		a.applyChild(n, "Right", n.Right)
+	case *ast.CallExpression:
+		a.applyChild(n, "Callee", n.Callee)
+		a.applyList(n, "Arguments")
+	case *ast.MemberExpression:
+		a.applyChild(n, "Object", n.Object)
+		a.applyChild(n, "Index", n.Index)
+	case *ast.NewExpression:
+		a.applyChild(n, "Callee", n.Callee)
+		a.applyList(n, "Arguments")
+	case *ast.ArrayExpression:
+		a.applyList(n, "Elements")
+	case *ast.ObjectExpression:
+		a.applyList(n, "Properties")
+	case *ast.Property:
+		a.applyChild(n, "Value", n.Value)
+	case *ast.ExpressionStatement:
+		a.applyChild(n, "Expression", n.Expression)
+	}
+
+	if a.post != nil && !a.post(&a.cursor) {
+		panic(errAbort)
+	}
+	a.cursor = saved
+}
+
+// applyChild visits a single child unless it is nil
+func (a *application) applyChild(parent ast.Node, name string, n ast.Node) {
+	if n != nil {
+		a.apply(parent, name, nil, n)
+	}
+}
+
+// applyList visits every element of a slice field
+// The slice is looked up again on every step because the callbacks may change it
+func (a *application) applyList(parent ast.Node, name string) {
+	saved := a.iter
+	a.iter.index = 0
+	for a.iter.index < listOf(parent, name).len() {
+		a.iter.step = 1
+		a.apply(parent, name, &a.iter, listOf(parent, name).at(a.iter.index))
+		a.iter.index += a.iter.step
+	}
+	a.iter = saved
+}
@@ -0,0 +1,193 @@
+package astutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"goast/ast"
+	"goast/codegen"
+	"goast/parser"
+)
+
+// parse parses a program, failing the test on syntax errors
+func parse(t *testing.T, src string) *ast.Program {
+	t.Helper()
+	program, err := parser.ParseFile("rewrite.js", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program
+}
+
+// TestApplyOrder checks that pre and post see every node in the order of ast.Walk, with the
+// field and index holding it
+func TestApplyOrder(t *testing.T) {
+	program := parse(t, "function f(a, b = 1) {\n  return g(a, b);\n}\n")
+	var got []string
+	Apply(program, func(c *Cursor) bool {
+		got = append(got, fmt.Sprintf("+%s.%s[%d]", c.Node().Type(), c.Name(), c.Index()))
+		return true
+	}, func(c *Cursor) bool {
+		got = append(got, "-"+c.Node().Type())
+		return true
+	})
+	want := []string{
+		"+Program.Node[-1]",
+		"+FunctionDeclaration.Body[0]",
+		"+Parameter.Params[0]", "-Parameter",
+		"+Parameter.Params[1]", "+NumericLiteral.DefaultValue[-1]", "-NumericLiteral", "-Parameter",
+		"+ReturnStatement.Body[0]",
+		"+CallExpression.Argument[-1]",
+		"+Identifier.Callee[-1]", "-Identifier",
+		"+Identifier.Arguments[0]", "-Identifier",
+		"+Identifier.Arguments[1]", "-Identifier",
+		"-CallExpression", "-ReturnStatement", "-FunctionDeclaration", "-Program",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got  %s\nwant %s", strings.Join(got, " "), strings.Join(want, " "))
+	}
+}
+
+// TestApplySkip checks that a false pre skips the children and the post of a node
+func TestApplySkip(t *testing.T) {
+	program := parse(t, "if (a) {\n  b();\n}\nc();\n")
+	var got []string
+	Apply(program, func(c *Cursor) bool {
+		got = append(got, "+"+c.Node().Type())
+		_, skip := c.Node().(*ast.IfStatement)
+		return !skip
+	}, func(c *Cursor) bool {
+		got = append(got, "-"+c.Node().Type())
+		return true
+	})
+	want := "+Program +IfStatement +ExpressionStatement +CallExpression +Identifier -Identifier -CallExpression -ExpressionStatement -Program"
+	if strings.Join(got, " ") != want {
+		t.Errorf("got  %s\nwant %s", strings.Join(got, " "), want)
+	}
+}
+
+// TestApplyAbort checks that a false post stops the traversal
+func TestApplyAbort(t *testing.T) {
+	program := parse(t, "a;\nb;\nc;\n")
+	var seen []string
+	result := Apply(program, nil, func(c *Cursor) bool {
+		if id, ok := c.Node().(*ast.Identifier); ok {
+			seen = append(seen, id.Name)
+			return id.Name != "b"
+		}
+		return true
+	})
+	if strings.Join(seen, " ") != "a b" {
+		t.Errorf("saw %v, want a b", seen)
+	}
+	if result != program {
+		t.Error("aborted Apply did not return the root")
+	}
+}
+
+// TestApplyRewrite checks Replace, Delete, InsertBefore and InsertAfter, and that the nodes
+// they add are not walked
+func TestApplyRewrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		pre     ApplyFunc
+		want    string
+		visited string // Identifiers seen by pre, in order; "" to skip the check
+	}{
+		{
+			"replace expressions",
+			"x = a + b;\nf(a, b);\n",
+			func(c *Cursor) bool {
+				if id, ok := c.Node().(*ast.Identifier); ok && id.Name == "a" {
+					c.Replace(&ast.NumericLiteral{Value: "1"})
+				}
+				return true
+			},
+			"x = 1 + b;\nf(1, b);\n", "",
+		},
+		{
+			"replace a parameter",
+			"function f(a) {\n  return a;\n}\n",
+			func(c *Cursor) bool {
+				if p, ok := c.Node().(*ast.Parameter); ok {
+					c.Replace(&ast.Parameter{Name: p.Name, DefaultValue: &ast.NumericLiteral{Value: "0"}})
+				}
+				return true
+			},
+			"function f(a = 0) {\n  return a;\n}\n", "",
+		},
+		{
+			"delete statements",
+			"a;\nb;\nc;\nd;\n",
+			func(c *Cursor) bool {
+				if s, ok := c.Node().(*ast.ExpressionStatement); ok {
+					if name := s.Expression.(*ast.Identifier).Name; name == "b" || name == "c" {
+						c.Delete()
+						return false
+					}
+				}
+				return true
+			},
+			"a;\nd;\n", "a d",
+		},
+		{
+			"insert statements",
+			"a;\nb;\n",
+			func(c *Cursor) bool {
+				if s, ok := c.Node().(*ast.ExpressionStatement); ok && s.Expression.(*ast.Identifier).Name == "a" {
+					c.InsertBefore(&ast.ExpressionStatement{Expression: &ast.Identifier{Name: "before"}})
+					c.InsertAfter(&ast.ExpressionStatement{Expression: &ast.Identifier{Name: "after"}})
+				}
+				return true
+			},
+			"before;\na;\nafter;\nb;\n", "a b",
+		},
+		{
+			"replace the root",
+			"a;\n",
+			func(c *Cursor) bool {
+				if _, ok := c.Node().(*ast.Program); ok {
+					c.Replace(&ast.Program{Body: []ast.Node{&ast.ExpressionStatement{Expression: &ast.Identifier{Name: "z"}}}})
+					return false
+				}
+				return true
+			},
+			"z;\n", "",
+		},
+	}
+	for _, test := range tests {
+		program := parse(t, test.src)
+		var visits []string
+		pre := func(c *Cursor) bool {
+			if id, ok := c.Node().(*ast.Identifier); ok {
+				visits = append(visits, id.Name)
+			}
+			return test.pre(c)
+		}
+		result := Apply(program, pre, nil)
+		if got := codegen.Generate(result, nil); got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, got, test.want)
+		}
+		if test.visited != "" && strings.Join(visits, " ") != test.visited {
+			t.Errorf("%s: pre saw %v, want %s", test.name, visits, test.visited)
+		}
+	}
+}
+
+// TestCursorOutsideSlice checks that slice operations panic on a node held by a plain field
+func TestCursorOutsideSlice(t *testing.T) {
+	program := parse(t, "x = 1;\n")
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "BinaryExpression.Left is not a slice") {
+			t.Errorf("recovered %v, want a panic about the field", r)
+		}
+	}()
+	Apply(program, func(c *Cursor) bool {
+		if c.Name() == "Left" {
+			c.Delete()
+		}
+		return true
+	}, nil)
+}
@@ -0,0 +1,142 @@
+// Package cfg builds control-flow graphs of functions
+// New splits the body of a function, or the top level of a program, into basic blocks:
+// runs of statements always executed together, linked by edges for the ways control
+// passes from one to the next
+package cfg
+
+import (
+	"goast/ast"
+)
+
+// Kind is the kind of an edge, what makes control take it
+type Kind string
+
+// Edge kinds
+// Loops, break and continue, try/finally and throw would need edges of their own (back
+// edges, jumps out of a loop, exceptional edges); none is supported until the parser has
+// those statements, which it does not: if and return are the only control flow there is
+const (
+	Normal Kind = "normal" // Control falls through to the next statement
+	True   Kind = "true"   // The test of an if statement is truthy
+	False  Kind = "false"  // The test of an if statement is falsy
+	Return Kind = "return" // A return statement leaves the function
+)
+
+// Graph is the control-flow graph of a function or of the top level of a program
+type Graph struct {
+	Node   ast.Node // FunctionDeclaration, or the Program for its top level
+	Name   string   // Function name, empty for the program
+	Blocks []*Block // Every block, the entry first and the exit last
+	Entry  *Block   // Block where execution starts
+	Exit   *Block   // Empty block every way out of the function leads to
+}
+
+// Block is a basic block: statements that run one after the other, entered at the first one
+// A block ending in a branch ends with its IfStatement, which stands for the evaluation of
+// the test only; its True and False edges lead to the consequent and to the code after it
+type Block struct {
+	Index int        // Position in Graph.Blocks
+	Nodes []ast.Node // Statements, the last one possibly an IfStatement
+	Succs []*Edge    // Edges to the blocks control may go to next
+	Preds []*Edge    // Edges from the blocks control may come from
+}
+
+// Edge is a possible transfer of control from the end of a block to the start of another
+type Edge struct {
+	From, To *Block
+	Kind     Kind
+}
+
+// Build returns the graphs of a program: its top level first, then every function declared
+// in it in source order, nested functions included
+func Build(program *ast.Program) []*Graph {
+	graphs := []*Graph{New(program)}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if f, ok := node.(*ast.FunctionDeclaration); ok {
+			graphs = append(graphs, New(f))
+		}
+		return true
+	})
+	return graphs
+}
+
+// New builds the graph of a FunctionDeclaration or of the top level of a Program
+// The bodies of nested functions are not part of it: a declaration is a single statement,
+// which does nothing when it runs since functions are hoisted
+// Statements following a return in the same list start a block without predecessors,
+// which is therefore unreachable
+func New(node ast.Node) *Graph {
+	g := &Graph{Node: node, Exit: &Block{}}
+	b := &builder{g: g}
+	b.current = b.newBlock()
+	g.Entry = b.current
+	switch n := node.(type) {
+	case *ast.Program:
+		b.statements(n.Body)
+	case *ast.FunctionDeclaration:
+		g.Name = n.Name
+		b.statements(n.Body)
+	}
+	if b.current != nil {
+		link(b.current, g.Exit, Normal) // Falling off the end returns undefined
+	}
+	g.Exit.Index = len(g.Blocks)
+	g.Blocks = append(g.Blocks, g.Exit)
+	return g
+}
+
+// builder holds the state of New
+type builder struct {
+	g       *Graph
+	current *Block // Block the next statement goes to, nil after a return
+}
+
+// newBlock adds an empty block to the graph
+func (b *builder) newBlock() *Block {
+	block := &Block{Index: len(b.g.Blocks)}
+	b.g.Blocks = append(b.g.Blocks, block)
+	return block
+}
+
+// add appends a node to the current block, starting an unreachable one after a return
+func (b *builder) add(node ast.Node) {
+	if b.current == nil {
+		b.current = b.newBlock()
+	}
+	b.current.Nodes = append(b.current.Nodes, node)
+}
+
+// statements adds a list of statements to the graph, from the current block on
+// Comments and the ErrorNodes of syntax errors take no part in control flow
+func (b *builder) statements(list []ast.Node) {
+	for _, node := range list {
+		switch n := node.(type) {
+		case *ast.Comment, *ast.ErrorNode:
+		case *ast.IfStatement:
+			b.add(n)
+			test := b.current
+			b.current = b.newBlock()
+			link(test, b.current, True)
+			b.statements(n.Consequent)
+			end := b.current
+			b.current = b.newBlock()
+			link(test, b.current, False)
+			if end != nil {
+				link(end, b.current, Normal)
+			}
+		case *ast.ReturnStatement:
+			b.add(n)
+			link(b.current, b.g.Exit, Return)
+			b.current = nil
+		default:
+			b.add(n)
+		}
+	}
+}
+
+// link adds an edge between two blocks
+func link(from, to *Block, kind Kind) {
+	e := &Edge{From: from, To: to, Kind: kind}
+	from.Succs = append(from.Succs, e)
+	to.Preds = append(to.Preds, e)
+}
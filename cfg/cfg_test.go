@@ -0,0 +1,207 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+
+	"goast/parser"
+)
+
+// parse parses a program, failing the test on syntax errors
+func parse(t *testing.T, src string) []*Graph {
+	t.Helper()
+	program, err := parser.ParseFile("test.js", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Build(program)
+}
+
+// TestShapes checks the blocks and edges built for each kind of statement
+func TestShapes(t *testing.T) {
+	tests := []struct {
+		name, src string
+		want      []string // String of each graph Build returns
+	}{
+		{
+			"straight line",
+			"let a = 1;\nlog(a);\n",
+			[]string{`program
+  B0 (entry)
+    let a = 1;
+    log(a);
+    -> B1
+  B1 (exit)
+`},
+		},
+		{
+			"if",
+			"if (a) {\n  log(1);\n}\nlog(2);\n",
+			[]string{`program
+  B0 (entry)
+    if (a)
+    -> B1 (true), B2 (false)
+  B1
+    log(1);
+    -> B2
+  B2
+    log(2);
+    -> B3
+  B3 (exit)
+`},
+		},
+		{
+			"nested if",
+			"if (a) {\n  if (b) {\n    log(1);\n  }\n}\n",
+			[]string{`program
+  B0 (entry)
+    if (a)
+    -> B1 (true), B4 (false)
+  B1
+    if (b)
+    -> B2 (true), B3 (false)
+  B2
+    log(1);
+    -> B3
+  B3
+    -> B4
+  B4
+    -> B5
+  B5 (exit)
+`},
+		},
+		{
+			"return in if",
+			"function f(x) {\n  if (x) {\n    return 1;\n  }\n  return 2;\n}\n",
+			[]string{`program
+  B0 (entry)
+    function f(x)
+    -> B1
+  B1 (exit)
+`, `function f
+  B0 (entry)
+    if (x)
+    -> B1 (true), B2 (false)
+  B1
+    return 1;
+    -> B3 (return)
+  B2
+    return 2;
+    -> B3 (return)
+  B3 (exit)
+`},
+		},
+		{
+			"dead code after return",
+			"function f() {\n  return;\n  log(1);\n}\n",
+			[]string{`program
+  B0 (entry)
+    function f()
+    -> B1
+  B1 (exit)
+`, `function f
+  B0 (entry)
+    return;
+    -> B2 (return)
+  B1
+    log(1);
+    -> B2
+  B2 (exit)
+`},
+		},
+		{
+			"nested functions",
+			"function outer() {\n  function inner() {\n    return 1;\n  }\n  return inner();\n}\nfunction last() {}\n",
+			[]string{`program
+  B0 (entry)
+    function outer()
+    function last()
+    -> B1
+  B1 (exit)
+`, `function outer
+  B0 (entry)
+    function inner()
+    return inner();
+    -> B1 (return)
+  B1 (exit)
+`, `function inner
+  B0 (entry)
+    return 1;
+    -> B1 (return)
+  B1 (exit)
+`, `function last
+  B0 (entry)
+    -> B1
+  B1 (exit)
+`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			graphs := parse(t, test.src)
+			if len(graphs) != len(test.want) {
+				t.Fatalf("got %d graphs, want %d", len(graphs), len(test.want))
+			}
+			for i, g := range graphs {
+				if got := g.String(); got != test.want[i] {
+					t.Errorf("graph %d:\n%s\nwant:\n%s", i, got, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestEdges checks that every edge is listed in the successors of its origin and in the
+// predecessors of its target, and that the entry comes first and the exit last
+func TestEdges(t *testing.T) {
+	src := "function f(x) {\n  if (x) {\n    if (x > 1) {\n      return 1;\n    }\n    log(x);\n  }\n  return;\n  log(0);\n}\n"
+	for _, g := range parse(t, src) {
+		if g.Blocks[0] != g.Entry || g.Blocks[len(g.Blocks)-1] != g.Exit {
+			t.Errorf("%s: entry or exit out of place", g.title())
+		}
+		if len(g.Exit.Succs) != 0 {
+			t.Errorf("%s: exit has successors", g.title())
+		}
+		for i, block := range g.Blocks {
+			if block.Index != i {
+				t.Errorf("%s: block %d has Index %d", g.title(), i, block.Index)
+			}
+			for _, e := range block.Succs {
+				if e.From != block || !hasEdge(e.To.Preds, e) {
+					t.Errorf("%s: edge B%d -> B%d missing from its target", g.title(), e.From.Index, e.To.Index)
+				}
+			}
+			for _, e := range block.Preds {
+				if e.To != block || !hasEdge(e.From.Succs, e) {
+					t.Errorf("%s: edge B%d -> B%d missing from its origin", g.title(), e.From.Index, e.To.Index)
+				}
+			}
+		}
+	}
+}
+
+// hasEdge reports whether edges holds e
+func hasEdge(edges []*Edge, e *Edge) bool {
+	for _, x := range edges {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
+
+// TestDot checks that branch edges are labeled and return edges dashed
+func TestDot(t *testing.T) {
+	dot := Dot(parse(t, "function f(x) {\n  if (x) {\n    return 1;\n  }\n}\n")...)
+	for _, want := range []string{
+		`label="function f";`,
+		`g1_b0 -> g1_b1 [label="true"];`,
+		`g1_b0 -> g1_b2 [label="false"];`,
+		`g1_b1 -> g1_b3 [style=dashed];`,
+		`g1_b2 -> g1_b3;`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("missing %s in:\n%s", want, dot)
+		}
+	}
+}
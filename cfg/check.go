@@ -0,0 +1,102 @@
+package cfg
+
+import (
+	"goast/ast"
+	"goast/constant"
+)
+
+// Reachable returns, by block Index, whether control can reach each block from the entry
+// Every edge counts, even the branch of an if statement whose test is constant
+func (g *Graph) Reachable() []bool {
+	reachable := make([]bool, len(g.Blocks))
+	work := []*Block{g.Entry}
+	reachable[g.Entry.Index] = true
+	for len(work) > 0 {
+		block := work[len(work)-1]
+		work = work[:len(work)-1]
+		for _, e := range block.Succs {
+			if !reachable[e.To.Index] {
+				reachable[e.To.Index] = true
+				work = append(work, e.To)
+			}
+		}
+	}
+	return reachable
+}
+
+// Unreachable returns the first statement of each run of statements control never reaches,
+// those following a return in the same list
+// The blocks nested in such a run are part of it and not reported again, and function
+// declarations are skipped since they are hoisted
+func (g *Graph) Unreachable() []ast.Node {
+	reachable := g.Reachable()
+	var nodes []ast.Node
+	for _, block := range g.Blocks {
+		if reachable[block.Index] || len(block.Preds) > 0 {
+			continue
+		}
+		for _, node := range block.Nodes {
+			if _, ok := node.(*ast.FunctionDeclaration); !ok {
+				nodes = append(nodes, node)
+				break
+			}
+		}
+	}
+	return nodes
+}
+
+// ConstantConditions returns the if statements of a graph whose test is always truthy or
+// always falsy, in source order
+func (g *Graph) ConstantConditions() []*ast.IfStatement {
+	var ifs []*ast.IfStatement
+	for _, block := range g.Blocks {
+		if len(block.Nodes) == 0 {
+			continue
+		}
+		if n, ok := block.Nodes[len(block.Nodes)-1].(*ast.IfStatement); ok {
+			if _, constant := ConstantTest(n.Test); constant {
+				ifs = append(ifs, n)
+			}
+		}
+	}
+	return ifs
+}
+
+// ConstantTest reports whether a test is constant and, if so, whether it is truthy
+// Expressions of literals are evaluated; array, object and new expressions create objects,
+// which are always truthy
+func ConstantTest(test ast.Node) (truthy, isConstant bool) {
+	switch test.(type) {
+	case *ast.ArrayExpression, *ast.ObjectExpression, *ast.NewExpression:
+		return true, true
+	}
+	v, ok := constant.Evaluate(test)
+	if !ok {
+		return false, false
+	}
+	return constant.Truthy(v), true
+}
+
+// MissingReturn reports whether a function returns a value on some paths and reaches the
+// end of its body on others, where it returns undefined without saying so
+// It is always false for the top level of a program
+func (g *Graph) MissingReturn() bool {
+	if _, ok := g.Node.(*ast.FunctionDeclaration); !ok {
+		return false
+	}
+	reachable := g.Reachable()
+	value, end := false, false
+	for _, e := range g.Exit.Preds {
+		if !reachable[e.From.Index] {
+			continue
+		}
+		switch e.Kind {
+		case Return:
+			r := e.From.Nodes[len(e.From.Nodes)-1].(*ast.ReturnStatement)
+			value = value || r.Argument != nil
+		case Normal:
+			end = true
+		}
+	}
+	return value && end
+}
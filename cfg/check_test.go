@@ -0,0 +1,115 @@
+package cfg
+
+import (
+	"testing"
+
+	"goast/codegen"
+	"goast/parser"
+)
+
+// TestUnreachable checks the first statement reported for each run of dead code
+func TestUnreachable(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string // Unreachable statements of f, as code
+	}{
+		{"function f() {\n  log(1);\n  return;\n}\n", nil},
+		{"function f() {\n  return;\n  log(1);\n  log(2);\n}\n", []string{"log(1);"}},
+		{"function f() {\n  return;\n  function g() {}\n  log(2);\n}\n", []string{"log(2);"}},
+		{"function f() {\n  return;\n  if (a) {\n    log(1);\n  }\n}\n", []string{"if (a) {\n  log(1);\n}"}},
+		{"function f() {\n  if (a) {\n    return;\n    log(1);\n  }\n  return;\n  log(2);\n}\n", []string{"log(1);", "log(2);"}},
+		{"function f() {\n  if (false) {\n    log(1);\n  }\n}\n", nil}, // Constant tests are not followed
+	}
+	for _, test := range tests {
+		graphs := parse(t, test.src)
+		nodes := graphs[1].Unreachable()
+		var got []string
+		for _, node := range nodes {
+			got = append(got, codegen.Generate(node, nil))
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("%q: got %q, want %q", test.src, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%q: got %q, want %q", test.src, got[i], test.want[i])
+			}
+		}
+	}
+}
+
+// TestConstantTest checks which tests are found constant and their truthiness
+func TestConstantTest(t *testing.T) {
+	tests := []struct {
+		src              string
+		truthy, constant bool
+	}{
+		{"true", true, true},
+		{"false", false, true},
+		{"0", false, true},
+		{"1", true, true},
+		{`""`, false, true},
+		{`"0"`, true, true},
+		{"null", false, true},
+		{"0 / 0", false, true}, // NaN
+		{"1 + 1 === 2", true, true},
+		{`"1" == 1`, true, true},
+		{`"a" < "b"`, true, true},
+		{"[]", true, true},
+		{"{}", true, true},
+		{"new Foo()", true, true},
+		{"x", false, false},
+		{"x === 1", false, false},
+		{"f()", false, false},
+		{"x = 1", false, false},
+	}
+	for _, test := range tests {
+		node, err := parser.ParseExpression(test.src, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", test.src, err)
+		}
+		truthy, constant := ConstantTest(node)
+		if truthy != test.truthy || constant != test.constant {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", test.src, truthy, constant, test.truthy, test.constant)
+		}
+	}
+}
+
+// TestConstantConditions checks that only if statements with a constant test are reported,
+// nested ones included
+func TestConstantConditions(t *testing.T) {
+	src := "if (x) {\n  if (1 < 2) {\n    log(1);\n  }\n}\nif (\"\") {\n  log(2);\n}\nif (x === 1) {\n  log(3);\n}\n"
+	ifs := parse(t, src)[0].ConstantConditions()
+	var got []string
+	for _, n := range ifs {
+		got = append(got, source(n))
+	}
+	want := []string{"if (1 < 2)", `if ("")`}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// TestMissingReturn checks functions returning a value on some paths only
+func TestMissingReturn(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"function f() {\n  log(1);\n}\n", false},
+		{"function f() {\n  return 1;\n}\n", false},
+		{"function f(x) {\n  if (x) {\n    return 1;\n  }\n  return 2;\n}\n", false},
+		{"function f(x) {\n  if (x) {\n    return 1;\n  }\n}\n", true},
+		{"function f(x) {\n  if (x) {\n    return 1;\n  }\n  return;\n}\n", false}, // A bare return is not falling off the end
+		{"function f(x) {\n  if (x) {\n    return;\n  }\n}\n", false},              // No path returns a value
+		{"function f() {\n  return 1;\n  if (x) {}\n}\n", false},                   // The end is unreachable
+		{"if (x) {\n  log(1);\n}\n", false},                                        // Top level
+	}
+	for _, test := range tests {
+		graphs := parse(t, test.src)
+		if got := graphs[len(graphs)-1].MissingReturn(); got != test.want {
+			t.Errorf("%q: got %v, want %v", test.src, got, test.want)
+		}
+	}
+}
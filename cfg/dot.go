@@ -0,0 +1,126 @@
+package cfg
+
+import (
+	"fmt"
+	"strings"
+
+	"goast/ast"
+	"goast/codegen"
+)
+
+// Dot returns a Graphviz DOT digraph drawing graphs side by side, one cluster each
+// Blocks list their statements as source code; branch edges are labeled true and false,
+// and return edges are dashed. Render it with: dot -Tsvg -o cfg.svg
+func Dot(graphs ...*Graph) string {
+	var sb strings.Builder
+	sb.WriteString("digraph cfg {\n")
+	sb.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	for i, g := range graphs {
+		fmt.Fprintf(&sb, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "    label=%s;\n", quoteDot(g.title()))
+		for _, block := range g.Blocks {
+			id := fmt.Sprintf("g%d_b%d", i, block.Index)
+			switch block {
+			case g.Entry:
+				fmt.Fprintf(&sb, "    %s [label=%s];\n", id, quoteDot(block.label("entry")))
+			case g.Exit:
+				fmt.Fprintf(&sb, "    %s [label=\"exit\", shape=oval];\n", id)
+			default:
+				fmt.Fprintf(&sb, "    %s [label=%s];\n", id, quoteDot(block.label("")))
+			}
+		}
+		for _, block := range g.Blocks {
+			for _, e := range block.Succs {
+				fmt.Fprintf(&sb, "    g%d_b%d -> g%d_b%d", i, e.From.Index, i, e.To.Index)
+				switch e.Kind {
+				case Normal:
+				case Return:
+					sb.WriteString(" [style=dashed]")
+				default:
+					fmt.Fprintf(&sb, " [label=%q]", e.Kind)
+				}
+				sb.WriteString(";\n")
+			}
+		}
+		sb.WriteString("  }\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// String lists the blocks of a graph with their statements and successors, for reading in
+// a terminal
+func (g *Graph) String() string {
+	var sb strings.Builder
+	sb.WriteString(g.title() + "\n")
+	for _, block := range g.Blocks {
+		fmt.Fprintf(&sb, "  B%d", block.Index)
+		switch block {
+		case g.Entry:
+			sb.WriteString(" (entry)")
+		case g.Exit:
+			sb.WriteString(" (exit)")
+		}
+		sb.WriteString("\n")
+		for _, node := range block.Nodes {
+			sb.WriteString("    " + source(node) + "\n")
+		}
+		if len(block.Succs) > 0 {
+			targets := make([]string, len(block.Succs))
+			for i, e := range block.Succs {
+				targets[i] = fmt.Sprintf("B%d", e.To.Index)
+				if e.Kind != Normal {
+					targets[i] += " (" + string(e.Kind) + ")"
+				}
+			}
+			sb.WriteString("    -> " + strings.Join(targets, ", ") + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// title names a graph after its function, or "program" for the top level
+func (g *Graph) title() string {
+	if g.Name == "" {
+		return "program"
+	}
+	return "function " + g.Name
+}
+
+// label is the text of a block in DOT: its name, then one line per statement
+func (b *Block) label(name string) string {
+	if name == "" {
+		name = fmt.Sprintf("B%d", b.Index)
+	}
+	lines := []string{name}
+	for _, node := range b.Nodes {
+		lines = append(lines, source(node))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// source prints a node of a block on one line: a function declaration by its signature,
+// since its body has a graph of its own, and an if statement by its test
+func source(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.FunctionDeclaration:
+		signature := *n
+		signature.Body = nil
+		node = &signature
+	case *ast.IfStatement:
+		test := *n
+		test.Consequent = nil
+		node = &test
+	}
+	lines := strings.Split(strings.TrimSpace(codegen.Generate(node, nil)), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSuffix(strings.Join(lines, " "), " {}")
+}
+
+// quoteDot quotes a label for DOT, with every line left-justified
+func quoteDot(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\l`).Replace(s)
+	return `"` + s + `"`
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"goast/cfg"
+	"goast/parser"
+)
+
+// runCfg implements the cfg subcommand
+// It prints the control-flow graphs of a file (standard input without arguments) as a
+// Graphviz DOT digraph, or as a list of blocks with -format text
+func runCfg(args []string) int {
+	flags := flag.NewFlagSet("cfg", flag.ExitOnError)
+	format := flags.String("format", "dot", "Output format: dot (Graphviz) or text")
+	name := flags.String("func", "", "Only print the graph of the function with this name, or program for the top level")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s cfg [flags] [file.js]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() > 1 || *format != "dot" && *format != "text" {
+		flags.Usage()
+		return 2
+	}
+
+	path := "<stdin>"
+	var src []byte
+	var err error
+	if flags.NArg() == 0 {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		path = flags.Arg(0)
+		src, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	program, err := parser.ParseFile(path, string(src), nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	graphs := cfg.Build(program)
+	if *name != "" {
+		var selected []*cfg.Graph
+		for _, g := range graphs {
+			if g.Name == *name || *name == "program" && g.Name == "" {
+				selected = append(selected, g)
+			}
+		}
+		if len(selected) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: no function named %s in %s\n", *name, path)
+			return 1
+		}
+		graphs = selected
+	}
+
+	if *format == "dot" {
+		fmt.Print(cfg.Dot(graphs...))
+		return 0
+	}
+	for i, g := range graphs {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(g)
+	}
+	return 0
+}
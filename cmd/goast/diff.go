@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffLine is one line of an edit script
+type diffLine struct {
+	kind byte // ' ' for unchanged, '-' for removed, '+' for added
+	text string
+}
+
+// unifiedDiff returns the changes from a to b in unified diff format
+// It uses a longest common subsequence table, which is plenty for source files
+func unifiedDiff(path, a, b string) string {
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s (formatted)\n", path, path)
+
+	// Group changes that are close to each other into hunks
+	for i := 0; i < len(lines); {
+		if lines[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].kind != ' ' {
+				end++
+				continue
+			}
+			// Stop once the unchanged run is long enough to separate two hunks
+			run := end
+			for run < len(lines) && lines[run].kind == ' ' {
+				run++
+			}
+			if run == len(lines) || run-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = run
+		}
+		writeHunk(&out, lines, start, end)
+		i = end
+	}
+	return out.String()
+}
+
+// writeHunk writes lines[start:end] with its @@ header
+func writeHunk(out *strings.Builder, lines []diffLine, start, end int) {
+	oldStart, newStart := 1, 1
+	for _, line := range lines[:start] {
+		if line.kind != '+' {
+			oldStart++
+		}
+		if line.kind != '-' {
+			newStart++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, line := range lines[start:end] {
+		if line.kind != '+' {
+			oldCount++
+		}
+		if line.kind != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, line := range lines[start:end] {
+		fmt.Fprintf(out, "%c%s\n", line.kind, line.text)
+	}
+}
+
+// diffLines computes an edit script turning a into b
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+// splitLines splits text into lines without their newline characters
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"goast/format"
+)
+
+// runFmt implements the fmt subcommand
+// Without files it formats standard input; with -w files are rewritten in place,
+// with -d a diff is printed instead of the formatted code
+func runFmt(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "Write the result back to the source files instead of standard output")
+	diff := flags.Bool("d", false, "Print a diff of the changes instead of the formatted code")
+	width := flags.Int("width", format.DefaultPrintWidth, "Print width the formatter aims for")
+	indent := flags.Int("indent", 2, "Number of spaces per indentation level")
+	quote := flags.String("quote", "double", "Preferred string quote: double or single")
+	semicolons := flags.Bool("semi", true, "Print semicolons at the end of statements")
+	trailingCommas := flags.Bool("trailing-comma", false, "Add trailing commas to parameter and argument lists broken across lines")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s fmt [flags] [file.js ...]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *quote != "double" && *quote != "single" {
+		fmt.Fprintf(os.Stderr, "Error: Unknown quote style %q, expected double or single\n", *quote)
+		return 2
+	}
+	opts := &format.Options{
+		PrintWidth:     *width,
+		Indent:         fmt.Sprintf("%*s", *indent, ""),
+		Quote:          '"',
+		OmitSemicolons: !*semicolons,
+		TrailingCommas: *trailingCommas,
+	}
+	if *quote == "single" {
+		opts.Quote = '\''
+	}
+
+	// Standard input is formatted to standard output
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		return formatFile("<stdin>", string(src), opts, false, *diff)
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			status = 1
+			continue
+		}
+		if code := formatFile(path, string(src), opts, *write, *diff); code != 0 {
+			status = code
+		}
+	}
+	return status
+}
+
+// formatFile formats one file and reports the result as requested by the flags
+func formatFile(path, src string, opts *format.Options, write, diff bool) int {
+	formatted, err := format.Source(path, src, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	switch {
+	case diff:
+		if formatted != src {
+			fmt.Print(unifiedDiff(path, src, formatted))
+		}
+	case write:
+		if formatted != src {
+			if err := os.WriteFile(path, []byte(formatted), 0o644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				return 1
+			}
+		}
+	default:
+		fmt.Print(formatted)
+	}
+	return 0
+}
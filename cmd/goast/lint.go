@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"goast/lint"
+)
+
+// defaultLintConfig is the configuration file used when -config is not given, if it exists
+const defaultLintConfig = ".goastlint.json"
+
+// runLint implements the lint subcommand
+// Problems are printed one per line; the exit status is 1 if any has error severity
+// With -fix the fixable problems are corrected first: files are rewritten in place, standard
+// input is printed fixed on standard output with the remaining problems on stderr
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	configPath := flags.String("config", "", "Configuration file (default "+defaultLintConfig+" if present)")
+	listRules := flags.Bool("rules", false, "List the available rules and exit")
+	fix := flags.Bool("fix", false, "Fix the problems that can be fixed automatically")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s lint [flags] [file.js ...]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *listRules {
+		for _, rule := range lint.Rules() {
+			fmt.Printf("%-16s %-6s %s\n", rule.Name(), lint.DefaultSeverity(rule.Name()), rule.Description())
+		}
+		return 0
+	}
+
+	cfg, err := loadLintConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 2
+	}
+
+	// Standard input is linted when no files are given
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		return lintFile("<stdin>", string(src), cfg, *fix)
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			status = 1
+			continue
+		}
+		if code := lintFile(path, string(src), cfg, *fix); code != 0 {
+			status = code
+		}
+	}
+	return status
+}
+
+// loadLintConfig reads the configuration at path, or the default file if path is empty
+func loadLintConfig(path string) (*lint.Config, error) {
+	if path == "" {
+		if _, err := os.Stat(defaultLintConfig); err != nil {
+			return nil, nil
+		}
+		path = defaultLintConfig
+	}
+	return lint.LoadConfig(path)
+}
+
+// lintFile lints one file, fixing it first if requested, and prints its problems
+func lintFile(path, src string, cfg *lint.Config, fix bool) int {
+	var diagnostics []lint.Diagnostic
+	var err error
+	report := os.Stdout
+	if fix {
+		var fixed string
+		fixed, diagnostics, err = lint.FixSource(path, src, cfg)
+		if err == nil {
+			if path == "<stdin>" {
+				fmt.Print(fixed)
+				report = os.Stderr
+			} else if fixed != src {
+				err = os.WriteFile(path, []byte(fixed), 0o644)
+			}
+		}
+	} else {
+		diagnostics, err = lint.Source(path, src, cfg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	status := 0
+	for _, d := range diagnostics {
+		fmt.Fprintln(report, d.Error())
+		if d.Severity == lint.Error {
+			status = 1
+		}
+	}
+	return status
+}
@@ -0,0 +1,197 @@
+// Command goast parses a JavaScript file and prints its tokens and AST
+//
+// Subcommands give access to the other tools built on the parser:
+//
+//	goast fmt [flags] [files]      format JavaScript source
+//	goast minify [flags] [file]    minify JavaScript source
+//	goast lint [flags] [files]     report likely mistakes
+//	goast run [flags] [file]       run a program and print results
+//	goast repl [flags]             evaluate JavaScript interactively
+//	goast cfg [flags] [file]       print control-flow graphs
+//	goast types [flags] [file]     infer types and report suspicious operations
+//	goast metrics [flags] [files]  measure complexity and size
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"goast/codegen"
+	"goast/estree"
+	"goast/lexer"
+	"goast/parser"
+	"goast/printer"
+	"goast/sourcemap"
+)
+
+// commands maps subcommand names to their entry points
+// Each one receives the arguments after its name and returns the exit status
+var commands = map[string]func(args []string) int{
+	"cfg":     runCfg,
+	"fmt":     runFmt,
+	"lint":    runLint,
+	"metrics": runMetrics,
+	"minify":  runMinify,
+	"repl":    runRepl,
+	"run":     runRun,
+	"types":   runTypes,
+}
+
+// main is the entry point of our program
+// It reads the JS file, tokenizes it, builds the AST, and prints the result
+func main() {
+	// A subcommand name as first argument hands over to that tool
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+
+	// Define command-line flags
+	filePath := flag.String("f", "./script.js", "Path to JavaScript file to parse")
+	maxDepth := flag.Int("max-depth", parser.DefaultMaxDepth, "Maximum nesting depth accepted by the parser")
+	format := flag.String("format", "text", "Output format: text (tokens and AST dump), json (ESTree) or js (generated source)")
+	sourceMap := flag.String("source-map", "", "With -format js, write a source map for the output to this file")
+	inputSourceMap := flag.String("input-source-map", "", "Source map of the input file, composed into the -source-map output")
+
+	// Parse the command-line flags
+	flag.Parse()
+
+	// Validate the output format
+	if *format != "text" && *format != "json" && *format != "js" {
+		fmt.Fprintf(os.Stderr, "Error: Unknown format %q, expected text, json or js\n", *format)
+		os.Exit(1)
+	}
+	if (*sourceMap != "" || *inputSourceMap != "") && *format != "js" {
+		fmt.Fprintf(os.Stderr, "Error: -source-map and -input-source-map require -format js\n")
+		os.Exit(1)
+	}
+	if *inputSourceMap != "" && *sourceMap == "" {
+		fmt.Fprintf(os.Stderr, "Error: -input-source-map requires -source-map\n")
+		os.Exit(1)
+	}
+
+	// Validate the file extension
+	if !strings.HasSuffix(*filePath, ".js") {
+		fmt.Fprintf(os.Stderr, "Error: File must be a JavaScript file with .js extension\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s -f <file.js>\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	// Check if the file exists
+	if _, err := os.Stat(*filePath); os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Error: File %s does not exist\n", *filePath)
+		os.Exit(1)
+	}
+
+	// Read the JavaScript file
+	content := readFile(*filePath)
+
+	// JSON and JS output replace the whole debugging dump so they can be piped to other tools
+	if *format == "json" || *format == "js" {
+		printOutput(*format, *filePath, content, *maxDepth, *sourceMap, *inputSourceMap)
+		return
+	}
+
+	// Print file information
+	fmt.Printf("Parsing file: %s\n", *filePath)
+	fmt.Println("File content:")
+	fmt.Println(content)
+	fmt.Println("\nTokenizing...")
+
+	// Tokenize the source code
+	lx := lexer.NewLexer(content)
+	tokens := lx.Tokenize()
+
+	// Print all identified tokens for debugging
+	fmt.Println("\nTokens:")
+	for _, token := range tokens {
+		if token.Type != "EOF" {
+			fmt.Printf("  %s: %s\n", token.Type, token.Value)
+		}
+	}
+
+	// Parse the tokens into an AST
+	fmt.Println("\nParsing...")
+	p := parser.NewParser(tokens)
+	p.MaxDepth = *maxDepth
+	program := p.Parse()
+
+	// Print the structure of the AST
+	fmt.Println("\nAST:")
+	printer.PrintAST(program, "")
+
+	// Report lexical and syntax errors after the (possibly partial) AST
+	if lexErrors, errors := lx.Errors(), p.Errors(); len(lexErrors)+len(errors) > 0 {
+		fmt.Fprintln(os.Stderr)
+		for _, err := range lexErrors {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		}
+		for _, err := range errors {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// printOutput parses content and prints it as ESTree JSON or generated JavaScript on standard output
+// Syntax errors are listed on stderr after the output for the partial AST
+// For JavaScript output a source map is written to mapPath when it is set, composed with the
+// map read from inputMapPath so that it points at the sources the input was generated from
+func printOutput(format, filePath, content string, maxDepth int, mapPath, inputMapPath string) {
+	program, parseErr := parser.ParseFile(filePath, content, &parser.Options{MaxDepth: maxDepth})
+
+	if format == "js" {
+		opts := &codegen.Options{}
+		if mapPath != "" {
+			opts.SourceMap = sourcemap.NewGenerator("")
+			opts.SourceFile = filePath
+			opts.Source = content
+		}
+		fmt.Print(codegen.Generate(program, opts))
+		if mapPath != "" {
+			if err := writeSourceMap(opts.SourceMap.Map(), mapPath, inputMapPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("//# sourceMappingURL=%s\n", filepath.Base(mapPath))
+		}
+	} else {
+		data, err := estree.Marshal(program, &estree.Options{Source: content, Indent: "  "})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	}
+
+	if parseErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", parseErr)
+		os.Exit(1)
+	}
+}
+
+// writeSourceMap writes m to path, composed with the source map at inputPath if there is one
+func writeSourceMap(m *sourcemap.Map, path, inputPath string) error {
+	if inputPath != "" {
+		data, err := os.ReadFile(inputPath)
+		if err != nil {
+			return err
+		}
+		input, err := sourcemap.Parse(data)
+		if err != nil {
+			return err
+		}
+		if m, err = sourcemap.Compose(m, input); err != nil {
+			return err
+		}
+	}
+	data, err := m.JSON()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
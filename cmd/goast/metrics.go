@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"goast/metrics"
+)
+
+// runMetrics implements the metrics subcommand
+// It prints the measures of every function and of each file (standard input without
+// arguments) as a table, or as a JSON array of files with -format json. Functions over one of
+// the -max limits are reported on stderr and make the exit status 1, so the command can
+// fail a build
+func runMetrics(args []string) int {
+	flags := flag.NewFlagSet("metrics", flag.ExitOnError)
+	format := flags.String("format", "table", "Output format: table or json")
+	var limits metrics.Limits
+	flags.IntVar(&limits.Cyclomatic, "max-cyclomatic", 0, "Largest cyclomatic complexity allowed for a function, 0 for no limit")
+	flags.IntVar(&limits.Cognitive, "max-cognitive", 0, "Largest cognitive complexity allowed for a function, 0 for no limit")
+	flags.IntVar(&limits.Nesting, "max-nesting", 0, "Deepest nesting allowed in a function, 0 for no limit")
+	flags.IntVar(&limits.Params, "max-params", 0, "Most parameters allowed for a function, 0 for no limit")
+	flags.IntVar(&limits.Lines, "max-lines", 0, "Most lines of code allowed for a function, 0 for no limit")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s metrics [flags] [file.js ...]\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Complexity counts if statements only: the parser has no loops, switch, ?: or &&, || and ??\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *format != "table" && *format != "json" {
+		flags.Usage()
+		return 2
+	}
+
+	// Standard input is measured when no files are given
+	type input struct{ path, src string }
+	var inputs []input
+	status := 0
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		inputs = append(inputs, input{"<stdin>", string(src)})
+	}
+	for _, path := range flags.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			status = 1
+			continue
+		}
+		inputs = append(inputs, input{path, string(src)})
+	}
+
+	files := []*metrics.File{}
+	for _, in := range inputs {
+		file, err := metrics.Source(in.path, in.src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			status = 1
+			continue
+		}
+		files = append(files, file)
+	}
+
+	if *format == "json" {
+		out, err := json.MarshalIndent(files, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+	} else {
+		for i, file := range files {
+			if i > 0 {
+				fmt.Println()
+			}
+			printMetrics(file)
+		}
+	}
+
+	for _, file := range files {
+		for _, v := range file.Check(limits) {
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: %s\n", file.Filename, v.Function.Line, v.Function.Column, v)
+			status = 1
+		}
+	}
+	return status
+}
+
+// printMetrics prints the measures of a file as a table, one row per function and a last
+// one for the whole file
+func printMetrics(file *metrics.File) {
+	fmt.Println(file.Filename)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	// Numbers are aligned right, names left by padding them to the same width
+	width := len("function")
+	for _, fn := range file.Functions {
+		width = max(width, len(fn.Name))
+	}
+	fmt.Fprintf(w, "%-*s\tline\tparams\tcyclomatic\tcognitive\tnesting\tloc\tvolume\tdifficulty\teffort\t\n", width, "function")
+	row := func(name, line, params string, m metrics.Measures) {
+		h := m.Halstead
+		fmt.Fprintf(w, "%-*s\t%s\t%s\t%d\t%d\t%d\t%d\t%.1f\t%.1f\t%.1f\t\n", width,
+			name, line, params, m.Cyclomatic, m.Cognitive, m.Nesting, m.Lines, h.Volume, h.Difficulty, h.Effort)
+	}
+	for _, fn := range file.Functions {
+		row(fn.Name, fmt.Sprint(fn.Line), fmt.Sprint(fn.Params), fn.Measures)
+	}
+	row("(file)", "", "", file.Measures)
+	w.Flush()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"goast/minify"
+	"goast/sourcemap"
+)
+
+// runMinify implements the minify subcommand
+// It minifies one file (standard input without arguments), prints the result or writes it to -o,
+// and reports the size before and after on stderr
+func runMinify(args []string) int {
+	flags := flag.NewFlagSet("minify", flag.ExitOnError)
+	output := flags.String("o", "", "Write the minified code to this file instead of standard output")
+	mapPath := flags.String("source-map", "", "Write a source map for the minified code to this file")
+	keepNames := flags.Bool("keep-names", false, "Do not shorten local variable, parameter and function names")
+	keepConstants := flags.Bool("keep-constants", false, "Do not fold constant expressions or remove constant if statements")
+	quiet := flags.Bool("q", false, "Do not report the sizes")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s minify [flags] [file.js]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := "<stdin>"
+	var src []byte
+	var err error
+	if flags.NArg() == 0 {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		path = flags.Arg(0)
+		src, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	opts := &minify.Options{KeepNames: *keepNames, KeepConstants: *keepConstants}
+	if *mapPath != "" {
+		file := ""
+		if *output != "" {
+			file = filepath.Base(*output)
+		}
+		opts.SourceMap = sourcemap.NewGenerator(file)
+	}
+	minified, err := minify.Source(path, string(src), opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	size := len(minified)
+
+	if *mapPath != "" {
+		data, err := opts.SourceMap.Map().JSON()
+		if err == nil {
+			err = os.WriteFile(*mapPath, data, 0o644)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		minified += "\n//# sourceMappingURL=" + filepath.Base(*mapPath)
+	}
+	minified += "\n"
+
+	if *output != "" {
+		err = os.WriteFile(*output, []byte(minified), 0o644)
+	} else {
+		_, err = io.WriteString(os.Stdout, minified)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	if !*quiet {
+		saved := 0.0
+		if len(src) > 0 {
+			saved = 100 * float64(len(src)-size) / float64(len(src))
+		}
+		fmt.Fprintf(os.Stderr, "%s: %d -> %d bytes (%.1f%% smaller)\n", path, len(src), size, saved)
+	}
+	return 0
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"goast/interp"
+	"goast/lexer"
+	"goast/parser"
+	"goast/printer"
+)
+
+// replModes are the ways the REPL shows an input
+var replModes = []string{"eval", "ast", "tokens"}
+
+// replHelp lists the REPL commands
+const replHelp = `Commands:
+  :mode eval|ast|tokens  show the value of each input, its AST or its tokens
+  :ast                   toggle printing the AST before the value in eval mode
+  :load file.js          run a file in the current scope
+  :history               list the inputs entered so far
+  :break                 discard the unfinished input
+  :reset                 start over with an empty global scope
+  :help                  show this help
+  :quit                  leave (end of input works too)
+Input left unfinished, with a brace or parenthesis open, continues on the next line.
+`
+
+// repl is the state of an interactive session
+type repl struct {
+	out     io.Writer
+	in      *interp.Interpreter
+	mode    string
+	showAST bool
+	history []string
+}
+
+// newRepl creates a session printing to out, console.log included
+func newRepl(out io.Writer) *repl {
+	r := &repl{out: out, mode: "eval"}
+	r.reset()
+	return r
+}
+
+// reset gives the session an interpreter with an empty global scope
+func (r *repl) reset() {
+	r.in = interp.New(&interp.Options{Stdout: r.out})
+}
+
+// runRepl implements the repl subcommand
+// It reads JavaScript from standard input line by line and shows each complete input
+func runRepl(args []string) int {
+	flags := flag.NewFlagSet("repl", flag.ExitOnError)
+	mode := flags.String("mode", "eval", "Initial mode: eval, ast or tokens")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s repl [flags]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	r := newRepl(os.Stdout)
+	if err := r.setMode(*mode); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 2
+	}
+	fmt.Fprintln(r.out, "goast repl, :help lists the commands")
+	r.loop(os.Stdin)
+	return 0
+}
+
+// loop reads inputs until end of input or :quit
+// Lines are accumulated while the parser reports the input as incomplete
+func (r *repl) loop(input io.Reader) {
+	scanner := bufio.NewScanner(input)
+	var pending strings.Builder
+	for {
+		if pending.Len() == 0 {
+			fmt.Fprint(r.out, "> ")
+		} else {
+			fmt.Fprint(r.out, "... ")
+		}
+		if !scanner.Scan() {
+			fmt.Fprintln(r.out)
+			return
+		}
+		line := scanner.Text()
+
+		// Commands start with a colon, which no statement can
+		if command := strings.TrimSpace(line); strings.HasPrefix(command, ":") {
+			if command == ":break" {
+				pending.Reset()
+				continue
+			}
+			if !r.command(command) {
+				return
+			}
+			continue
+		}
+
+		pending.WriteString(line + "\n")
+		src := pending.String()
+		if strings.TrimSpace(src) == "" {
+			pending.Reset()
+			continue
+		}
+		if parser.Incomplete(src) {
+			continue
+		}
+		pending.Reset()
+		r.history = append(r.history, strings.TrimSuffix(src, "\n"))
+		r.show("<repl>", src)
+	}
+}
+
+// command runs a REPL command and reports whether the session goes on
+func (r *repl) command(command string) bool {
+	name, arg, _ := strings.Cut(command, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case ":quit", ":exit":
+		return false
+	case ":help":
+		fmt.Fprint(r.out, replHelp)
+	case ":mode":
+		if arg == "" {
+			fmt.Fprintf(r.out, "mode %s\n", r.mode)
+		} else if err := r.setMode(arg); err != nil {
+			fmt.Fprintln(r.out, err)
+		}
+	case ":ast":
+		r.showAST = !r.showAST
+		fmt.Fprintf(r.out, "AST %s\n", map[bool]string{true: "on", false: "off"}[r.showAST])
+	case ":load":
+		src, err := os.ReadFile(arg)
+		if err != nil {
+			fmt.Fprintln(r.out, err)
+			break
+		}
+		r.show(arg, string(src))
+	case ":history":
+		width := len(strconv.Itoa(len(r.history)))
+		for i, entry := range r.history {
+			fmt.Fprintf(r.out, "%*d  %s\n", width, i+1, strings.ReplaceAll(entry, "\n", "\n"+strings.Repeat(" ", width+2)))
+		}
+	case ":reset":
+		r.reset()
+		fmt.Fprintln(r.out, "scope cleared")
+	default:
+		fmt.Fprintf(r.out, "unknown command %s, :help lists the commands\n", name)
+	}
+	return true
+}
+
+// setMode selects what show prints
+func (r *repl) setMode(mode string) error {
+	for _, m := range replModes {
+		if m == mode {
+			r.mode = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown mode %q, expected %s", mode, strings.Join(replModes, ", "))
+}
+
+// show prints an input according to the mode: its tokens, its AST, or the value it evaluates to
+func (r *repl) show(filename, src string) {
+	switch r.mode {
+	case "tokens":
+		lx := lexer.NewLexer(src)
+		for _, token := range lx.Tokenize() {
+			if token.Type != "EOF" {
+				fmt.Fprintf(r.out, "  %s: %s\n", token.Type, token.Value)
+			}
+		}
+		for _, err := range lx.Errors() {
+			fmt.Fprintln(r.out, err)
+		}
+		return
+	case "ast":
+		r.printAST(filename, src)
+		return
+	}
+
+	if r.showAST {
+		r.printAST(filename, src)
+	}
+	v, err := r.in.RunSource(filename, src)
+	if err != nil {
+		fmt.Fprintf(r.out, "Uncaught %s\n", err)
+		return
+	}
+	fmt.Fprintln(r.out, interp.Inspect(v))
+}
+
+// printAST prints the tree of an input followed by its syntax errors
+func (r *repl) printAST(filename, src string) {
+	program, err := parser.ParseFile(filename, src, nil)
+	printer.Fprint(r.out, program, "")
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// session feeds input to a new REPL and returns what it printed
+func session(input string) string {
+	var out strings.Builder
+	newRepl(&out).loop(strings.NewReader(input))
+	return out.String()
+}
+
+func TestReplState(t *testing.T) {
+	got := session("let x = 1;\nfunction f(a) {\n  return a + x;\n}\nf(2)\nx = x + 1;\nf(2)\nconsole.log(\"x is\", x)\n")
+	want := "> undefined\n" +
+		"> ... ... undefined\n" + // The declaration of f continues over three lines
+		"> 3\n" +
+		"> 2\n" +
+		"> 4\n" + // f sees the new value of x
+		"> x is 2\nundefined\n" +
+		"> \n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReplContinuation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"open parenthesis", "(1 +\n2)\n", "> ... 3\n> \n"},
+		{"open brace", "if (true) {\n\n  1;\n}\n", "> ... ... ... undefined\n> \n"},
+		{"open bracket", "[1,\n2].length\n", "> ... 2\n> \n"},
+		{"blank lines", "\n\n1\n", "> > > 1\n> \n"},
+		{"break", "(1 +\n:break\n2\n", "> ... > 2\n> \n"},
+		{"end of input inside", "(1 +\n", "> ... \n"},
+	}
+	for _, tt := range tests {
+		if got := session(tt.input); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestReplErrors checks that the session goes on after syntax and runtime errors, keeping
+// what the inputs before them defined
+func TestReplErrors(t *testing.T) {
+	got := session("let a = 1;\nlet b = ;\ny\na = a + 1;\nnull.p\na\n")
+	want := "> undefined\n" +
+		"> Uncaught <repl>:1:9: syntax error: unexpected \";\" in expression\n" +
+		"> Uncaught <repl>:1:1: ReferenceError: y is not defined\n" +
+		"> 2\n" +
+		"> Uncaught <repl>:1:1: TypeError: Cannot read properties of null (reading 'p')\n" +
+		"> 2\n" +
+		"> \n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReplCommands(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lib.js")
+	if err := os.WriteFile(path, []byte("function double(n) {\n  return n * 2;\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"reset", "let x = 1;\n:reset\nx\nlet x = 2;\n", "> undefined\n> scope cleared\n> Uncaught <repl>:1:1: ReferenceError: x is not defined\n> undefined\n> \n"},
+		{"load", ":load " + path + "\ndouble(4)\n", "> undefined\n> 8\n> \n"},
+		{"ast mode", ":mode ast\n1\n", "> > Program:\n  ExpressionStatement:\n    NumericLiteral: 1\n> \n"},
+		{"tokens mode", ":mode tokens\na+1\n", "> >   IDENTIFIER: a\n  PLUS: +\n  NUMBER: 1\n> \n"},
+		{"show mode", ":mode\n", "> mode eval\n> \n"},
+		{"unknown mode", ":mode foo\n", "> unknown mode \"foo\", expected eval, ast, tokens\n> \n"},
+		{"history", "1\nf(\n2)\n:history\n", "> 1\n> ... Uncaught <repl>:1:1: ReferenceError: f is not defined\n> 1  1\n2  f(\n   2)\n> \n"},
+		{"unknown command", ":nope\n", "> unknown command :nope, :help lists the commands\n> \n"},
+		{"quit", ":quit\n1\n", "> "},
+	}
+	for _, tt := range tests {
+		if got := session(tt.input); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"goast/ast"
+	"goast/codegen"
+	"goast/interp"
+	"goast/parser"
+	"goast/vm"
+)
+
+// callList collects the repeatable -call flag
+type callList []string
+
+func (c *callList) String() string     { return strings.Join(*c, ", ") }
+func (c *callList) Set(s string) error { *c = append(*c, s); return nil }
+
+// runRun implements the run subcommand
+// It runs a program, printing the value of each top-level expression statement, then
+// evaluates the -call expressions against what the program declared
+func runRun(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	var calls callList
+	flags.Var(&calls, "call", "Expression to evaluate after the program, such as 'checkAge(20)' (repeatable)")
+	quiet := flags.Bool("q", false, "Do not print the values of the program's own expression statements")
+	useVM := flags.Bool("vm", false, "Run on the bytecode VM instead of the tree-walking interpreter")
+	disasm := flags.Bool("disasm", false, "Print the bytecode of the program and exit")
+	bench := flags.Int("bench", 0, "Run the program and the calls this many times on both engines and compare their speed")
+	var limits interp.Limits
+	flags.Int64Var(&limits.MaxSteps, "max-steps", 0, "Stop the program after this many evaluation steps or VM instructions (0 for no limit)")
+	flags.IntVar(&limits.MaxCallDepth, "max-depth", 10000, "Maximum nesting of function calls, at most 100000")
+	flags.Int64Var(&limits.MaxAllocations, "max-alloc", 0, "Maximum number of functions, strings and array elements created (0 for no limit)")
+	flags.IntVar(&limits.MaxStringLength, "max-string", 0, "Maximum length in bytes of a string built by the program (0 for no limit)")
+	timeout := flags.Duration("timeout", 0, "Interrupt the program after this long, such as 2s (0 for no limit)")
+	now := flags.String("now", "", "Fixed time Date reads as the current time, in RFC 3339 format such as 2024-01-02T15:04:05Z")
+	tz := flags.String("tz", "UTC", "Time zone of Date's local methods, such as Europe/Paris or Local")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s run [flags] [file.js]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+	path := "./script.js"
+	if flags.NArg() == 1 {
+		path = flags.Arg(0)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	var clock func() time.Time
+	if *now != "" {
+		t, err := time.Parse(time.RFC3339Nano, *now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -now: %s\n", err)
+			return 2
+		}
+		clock = func() time.Time { return t }
+	}
+	location, err := time.LoadLocation(*tz)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -tz: %s\n", err)
+		return 2
+	}
+
+	if *timeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		limits.Context = ctx
+	}
+
+	switch {
+	case *disasm:
+		code, err := vm.CompileSource(path, string(src))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return 1
+		}
+		fmt.Print(vm.Disassemble(code))
+		return 0
+	case *bench > 0:
+		return benchmark(path, string(src), calls, *bench)
+	case *useVM:
+		return runVM(path, string(src), calls, &vm.Options{Limits: limits, Now: clock, Location: location})
+	}
+
+	opts := &interp.Options{Limits: limits, Now: clock, Location: location}
+	if !*quiet {
+		opts.Echo = printResult
+	}
+	in := interp.New(opts)
+	if _, err := in.RunSource(path, string(src)); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	for _, call := range calls {
+		v, err := in.EvalSource(call)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", call, err)
+			return 1
+		}
+		fmt.Printf("%s => %s\n", call, interp.Inspect(v))
+	}
+	return 0
+}
+
+// runVM runs a program and the calls on the bytecode VM
+// The VM does not report the values of the program's expression statements, only those of the calls
+func runVM(path, src string, calls []string, opts *vm.Options) int {
+	code, err := vm.CompileSource(path, src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	machine := vm.New(opts)
+	if _, err := machine.Run(code); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+
+	for _, call := range calls {
+		code, err := vm.CompileSource("", call)
+		if err == nil {
+			var v interp.Value
+			if v, err = machine.Run(code); err == nil {
+				fmt.Printf("%s => %s\n", call, interp.Inspect(v))
+				continue
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", call, err)
+		return 1
+	}
+	return 0
+}
+
+// benchmark times n runs of the program followed by the calls on each engine, each run in a
+// fresh global scope
+// Parsing and compiling happen once beforehand and are not timed
+func benchmark(path, src string, calls []string, n int) int {
+	program, err := parser.ParseFile(path, src, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	code, err := vm.Compile(program)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	var exprs []ast.Node
+	var codes []*vm.Code
+	for _, call := range calls {
+		expr, err := parser.ParseExpression(call, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", call, err)
+			return 1
+		}
+		exprs = append(exprs, expr)
+		c, err := vm.Compile(&ast.Program{Body: []ast.Node{&ast.ExpressionStatement{Expression: expr}}})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", call, err)
+			return 1
+		}
+		codes = append(codes, c)
+	}
+
+	start := time.Now()
+	for range n {
+		in := interp.New(nil)
+		if _, err := in.Run(program); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return 1
+		}
+		for _, expr := range exprs {
+			if _, err := in.Eval(expr); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+				return 1
+			}
+		}
+	}
+	treeWalk := time.Since(start)
+
+	start = time.Now()
+	for range n {
+		machine := vm.New(nil)
+		if _, err := machine.Run(code); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return 1
+		}
+		for _, c := range codes {
+			if _, err := machine.Run(c); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", err)
+				return 1
+			}
+		}
+	}
+	bytecode := time.Since(start)
+
+	fmt.Printf("interp %8d runs %12s/run\n", n, treeWalk/time.Duration(n))
+	fmt.Printf("vm     %8d runs %12s/run  %.2fx\n", n, bytecode/time.Duration(n), float64(treeWalk)/float64(bytecode))
+	return 0
+}
+
+// printResult prints an expression with its value
+// Assignments are statements run for their effect and are not shown
+func printResult(expr ast.Node, v interp.Value) {
+	if b, ok := expr.(*ast.BinaryExpression); ok && b.Operator == "=" {
+		return
+	}
+	fmt.Printf("%s => %s\n", codegen.Generate(expr, nil), interp.Inspect(v))
+}
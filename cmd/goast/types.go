@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"goast/ast"
+	"goast/parser"
+	"goast/scope"
+	"goast/types"
+)
+
+// runTypes implements the types subcommand
+// It prints the inferred types of the variables and functions of a file (standard input
+// without arguments) in source order, then the suspicious operations found, and exits with
+// status 1 if there are any
+func runTypes(args []string) int {
+	flags := flag.NewFlagSet("types", flag.ExitOnError)
+	quiet := flags.Bool("quiet", false, "Only print the suspicious operations")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s types [flags] [file.js]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	path := "<stdin>"
+	var src []byte
+	var err error
+	if flags.NArg() == 0 {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		path = flags.Arg(0)
+		src, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	return printTypes(os.Stdout, os.Stderr, path, string(src), *quiet)
+}
+
+// printTypes parses and infers src and prints the declarations (unless quiet) and the
+// suspicious operations to stdout, each prefixed with path:line:column; syntax errors go to
+// stderr. It returns the exit status of the types subcommand
+func printTypes(stdout, stderr io.Writer, path, src string, quiet bool) int {
+	program, err := parser.ParseFile(path, src, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s\n", err)
+		return 1
+	}
+	info := types.Infer(program, scope.Analyze(program, nil))
+	lines := ast.NewLines(src)
+
+	if !quiet {
+		// Declarations are indented by how deep in functions they are
+		depth := 0
+		ast.Traverse(program, func(node ast.Node) bool {
+			indent := strings.Repeat("  ", depth)
+			switch n := node.(type) {
+			case *ast.FunctionDeclaration:
+				line, column := lines.Position(n.NameSpan.Start)
+				fmt.Fprintf(stdout, "%s:%d:%d: %sfunction %s\n", path, line, column, indent, info.Signature(n))
+				depth++
+			case *ast.VariableDeclaration:
+				line, column := lines.Position(n.NameSpan.Start)
+				fmt.Fprintf(stdout, "%s:%d:%d: %s%s %s: %s\n", path, line, column, indent, n.Kind, n.Name, info.Types[n])
+			}
+			return true
+		}, func(node ast.Node) {
+			if _, ok := node.(*ast.FunctionDeclaration); ok {
+				depth--
+			}
+		})
+	}
+
+	for _, p := range info.Problems {
+		line, column := lines.Position(p.Span.Start)
+		fmt.Fprintf(stdout, "%s:%d:%d: %s\n", path, line, column, p.Message)
+	}
+	if len(info.Problems) > 0 {
+		return 1
+	}
+	return 0
+}
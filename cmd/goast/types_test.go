@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintTypes(t *testing.T) {
+	src := "function f(n) {\n  let half = n / 2;\n  return half;\n}\n\nconst s = \"é\";\nconst x = s - 1;\n"
+	var stdout, stderr strings.Builder
+	status := printTypes(&stdout, &stderr, "app.js", src, false)
+	want := `app.js:1:10: function f(n: unknown): number
+app.js:2:7:   let half: number
+app.js:6:7: const s: string
+app.js:7:7: const x: number
+app.js:7:11: Arithmetic with '-' converts a string to a number
+`
+	if stdout.String() != want || stderr.Len() != 0 || status != 1 {
+		t.Errorf("status %d, printed\n%s\nwant\n%s\nerrors: %s", status, stdout.String(), want, stderr.String())
+	}
+
+	stdout.Reset()
+	if status := printTypes(&stdout, &stderr, "app.js", "const a = 1;\n", true); status != 0 || stdout.Len() != 0 {
+		t.Errorf("quiet run without problems: status %d, printed %q", status, stdout.String())
+	}
+
+	if status := printTypes(&stdout, &stderr, "bad.js", "const = 1;\n", false); status != 1 || !strings.HasPrefix(stderr.String(), "bad.js:1:7: ") {
+		t.Errorf("syntax error: status %d, errors %q", status, stderr.String())
+	}
+}
@@ -0,0 +1,494 @@
+// Package codegen prints an AST back to JavaScript source code
+// Together with the parser and astutil it makes parse, transform, print round trips possible
+package codegen
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"goast/ast"
+	"goast/sourcemap"
+)
+
+// Options configures Generate
+// A nil *Options is valid and selects the defaults
+type Options struct {
+	Indent string // Indentation for each nesting level, two spaces when empty
+	Quote  byte   // Preferred string quote, '"' (the default) or '\''
+
+	// Compact prints the smallest output: no whitespace beyond what separates tokens and
+	// no comments
+	// Runs of declarations of the same kind are not merged into one statement, since the
+	// AST and the parser hold a single declarator per declaration
+	Compact bool
+
+	// SourceMap, when set, receives a mapping for every statement, identifier and literal
+	// that has a position; Source must then hold the code the AST was parsed from
+	SourceMap  *sourcemap.Generator
+	SourceFile string // Name of the original file recorded in the mappings
+	Source     string // Original source code, used to turn node offsets into lines and columns
+}
+
+// Generate returns the JavaScript source code for node
+// Statements are printed one per line with a blank line around top-level functions,
+// and parentheses are added only where operator precedence requires them
+// ErrorNode and InvalidExpression have no JavaScript equivalent and are printed as comments
+func Generate(node ast.Node, opts *Options) string {
+	g := newGenerator(opts)
+	g.node(node)
+	return g.out.String()
+}
+
+// generator accumulates the output of Generate
+type generator struct {
+	indent string          // Indentation for one level
+	quote  byte            // Preferred string quote
+	level  int             // Current nesting level
+	out    strings.Builder // Generated source
+	spaces bool            // Whether optional spaces are printed, false in compact mode
+
+	// Source map state, sourceMap is nil when no map is generated
+	sourceMap  *sourcemap.Generator
+	sourceFile string
+	source     string
+	lines      ast.Lines // Line table of source
+	line       int       // Current line of the output, 0-based
+	column     int       // Current column of the output in UTF-16 code units
+}
+
+// newGenerator applies the defaults to opts
+func newGenerator(opts *Options) *generator {
+	g := &generator{indent: "  ", quote: '"', spaces: true}
+	if opts != nil {
+		if opts.Indent != "" {
+			g.indent = opts.Indent
+		}
+		if opts.Quote == '\'' {
+			g.quote = '\''
+		}
+		if opts.Compact {
+			g.indent, g.spaces = "", false
+		}
+		if opts.SourceMap != nil {
+			g.sourceMap = opts.SourceMap
+			g.sourceFile = opts.SourceFile
+			g.source = opts.Source
+			g.lines = ast.NewLines(g.source)
+			g.sourceMap.AddSource(g.sourceFile, g.source)
+		}
+	}
+	return g
+}
+
+// write appends s to the output, keeping track of the output position for the source map
+func (g *generator) write(s string) {
+	g.out.WriteString(s)
+	if g.sourceMap == nil {
+		return
+	}
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		g.line += strings.Count(s, "\n")
+		g.column = 0
+		s = s[i+1:]
+	}
+	g.column += utf16Len(s)
+}
+
+// space writes s, or nothing in compact mode where it is optional whitespace
+func (g *generator) space(s string) {
+	if g.spaces {
+		g.write(s)
+	}
+}
+
+// mark maps the current output position to the start of span in the original source
+// named marks identifiers, whose original spelling is recorded as the mapping's name
+// so that renamed (for instance minified) identifiers can be traced back
+func (g *generator) mark(span ast.Span, named bool) {
+	if g.sourceMap == nil || !span.IsValid() || span.End > len(g.source) {
+		return
+	}
+	name := ""
+	if named {
+		name = g.source[span.Start:span.End]
+	}
+	// Source maps count lines from 0 and columns in UTF-16 code units
+	line, _ := g.lines.Position(span.Start)
+	column := utf16Len(g.source[g.lines.Start(line):span.Start])
+	g.sourceMap.AddMapping(sourcemap.Mapping{
+		GeneratedLine:   g.line,
+		GeneratedColumn: g.column,
+		Source:          g.sourceFile,
+		OriginalLine:    line - 1,
+		OriginalColumn:  column,
+		Name:            name,
+	})
+}
+
+// utf16Len returns the length of s in UTF-16 code units, the unit of source map columns
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r == utf8.RuneError {
+			n++
+			continue
+		}
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// node prints a program, a statement or an expression
+func (g *generator) node(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.Program:
+		g.program(n)
+	case *ast.FunctionDeclaration, *ast.IfStatement, *ast.ReturnStatement,
+		*ast.VariableDeclaration, *ast.ExpressionStatement, *ast.Comment, *ast.ErrorNode:
+		g.statement(n)
+	default:
+		g.expression(n, 0)
+	}
+}
+
+// program prints the top-level statements
+// A blank line separates function declarations from their neighbours
+func (g *generator) program(program *ast.Program) {
+	if !g.spaces {
+		g.compactList(program.Body)
+		return
+	}
+	for i, stmt := range program.Body {
+		if i > 0 && (isFunction(stmt) || isFunction(program.Body[i-1])) {
+			g.write("\n")
+		}
+		g.statement(stmt)
+		g.write("\n")
+	}
+}
+
+// isFunction reports whether a statement is a function declaration
+func isFunction(node ast.Node) bool {
+	_, ok := node.(*ast.FunctionDeclaration)
+	return ok
+}
+
+// compactList prints a statement list in compact mode, dropping comments
+// Each declaration keeps its own keyword: the parser reads a single declarator per
+// declaration, and compact output must parse back
+func (g *generator) compactList(list []ast.Node) {
+	for _, stmt := range list {
+		if _, ok := stmt.(*ast.Comment); ok {
+			continue
+		}
+		g.statement(stmt)
+	}
+}
+
+// statement prints one statement at the current indentation, without the final newline
+func (g *generator) statement(node ast.Node) {
+	g.writeIndent()
+	g.mark(node.Range(), false)
+
+	switch n := node.(type) {
+	case *ast.FunctionDeclaration:
+		g.write("function ")
+		g.mark(n.NameSpan, true)
+		g.write(n.Name + "(")
+		for i := range n.Params {
+			if i > 0 {
+				g.write(",")
+				g.space(" ")
+			}
+			g.parameter(&n.Params[i])
+		}
+		g.write(")")
+		g.space(" ")
+		g.block(n.Body)
+	case *ast.IfStatement:
+		g.write("if")
+		g.space(" ")
+		g.write("(")
+		g.expression(n.Test, 0)
+		g.write(")")
+		g.space(" ")
+		g.block(n.Consequent)
+	case *ast.ReturnStatement:
+		g.write("return")
+		if n.Argument != nil {
+			// A quote already separates the keyword from a string
+			if _, ok := n.Argument.(*ast.StringLiteral); g.spaces || !ok {
+				g.write(" ")
+			}
+			g.expression(n.Argument, 0)
+		}
+		g.write(";")
+	case *ast.VariableDeclaration:
+		g.write(n.Kind + " ")
+		g.declarator(n)
+		g.write(";")
+	case *ast.Comment:
+		if g.spaces {
+			g.write(n.Text)
+		}
+	case *ast.ExpressionStatement:
+		// A statement starting with a brace would be read as a block
+		if startsWithBrace(n.Expression) {
+			g.write("(")
+			g.expression(n.Expression, 0)
+			g.write(")")
+		} else {
+			g.expression(n.Expression, 0)
+		}
+		g.write(";")
+	case *ast.ErrorNode:
+		g.write("/* syntax error: " + commentSafe(n.Message) + " */")
+	default:
+		// An expression in statement position
+		g.expression(node, 0)
+		g.write(";")
+	}
+}
+
+// declarator prints the name and initializer of a variable declaration
+func (g *generator) declarator(decl *ast.VariableDeclaration) {
+	g.mark(decl.NameSpan, true)
+	g.write(decl.Name)
+	if decl.Value != nil {
+		g.assign()
+		// The initializer is an assignment target already, so "=" binds as usual
+		g.expression(decl.Value, ast.Precedence("="))
+	}
+}
+
+// assign writes the "=" between a binding and its value
+func (g *generator) assign() {
+	g.space(" ")
+	g.write("=")
+	g.space(" ")
+}
+
+// parameter prints a parameter with its default value
+func (g *generator) parameter(param *ast.Parameter) {
+	g.mark(param.NameSpan, true)
+	g.write(param.Name)
+	if param.DefaultValue != nil {
+		g.assign()
+		g.expression(param.DefaultValue, ast.Precedence("="))
+	}
+}
+
+// block prints a braced statement list, each statement on its own line
+func (g *generator) block(body []ast.Node) {
+	if len(body) == 0 {
+		g.write("{}")
+		return
+	}
+	if !g.spaces {
+		g.write("{")
+		g.compactList(body)
+		g.write("}")
+		return
+	}
+	g.write("{\n")
+	g.level++
+	for _, stmt := range body {
+		g.statement(stmt)
+		g.write("\n")
+	}
+	g.level--
+	g.writeIndent()
+	g.write("}")
+}
+
+// expression prints an expression, parenthesized if its operator binds looser than minPrecedence
+func (g *generator) expression(node ast.Node, minPrecedence int) {
+	switch n := node.(type) {
+	case nil:
+		g.write("/* missing expression */")
+	case *ast.BinaryExpression:
+		precedence := ast.Precedence(n.Operator)
+		parenthesize := precedence < minPrecedence
+		if parenthesize {
+			g.write("(")
+		}
+		// The operand on the grouping side may share the precedence, the other must bind tighter
+		leftMin, rightMin := precedence, precedence+1
+		if ast.IsRightAssociative(n.Operator) {
+			leftMin, rightMin = precedence+1, precedence
+		}
+		g.expression(n.Left, leftMin)
+		g.space(" ")
+		g.write(n.Operator)
+		g.space(" ")
+		g.expression(n.Right, rightMin)
+		if parenthesize {
+			g.write(")")
+		}
+	case *ast.Identifier:
+		g.mark(n.Span, true)
+		g.write(n.Name)
+	case *ast.NumericLiteral:
+		g.mark(n.Span, false)
+		g.write(n.Value)
+	case *ast.CallExpression:
+		g.expression(n.Callee, ast.CallPrecedence)
+		g.write("(")
+		for i, arg := range n.Arguments {
+			if i > 0 {
+				g.write(",")
+				g.space(" ")
+			}
+			g.expression(arg, ast.Precedence("="))
+		}
+		g.write(")")
+	case *ast.MemberExpression:
+		if n.Index != nil {
+			g.expression(n.Object, ast.CallPrecedence)
+			g.write("[")
+			g.expression(n.Index, 0)
+			g.write("]")
+			break
+		}
+		// The dot after an integer would be read as its decimal point
+		if number, ok := n.Object.(*ast.NumericLiteral); ok && !strings.ContainsAny(number.Value, ".xXoObB") {
+			g.write("(")
+			g.expression(number, 0)
+			g.write(")")
+		} else {
+			g.expression(n.Object, ast.CallPrecedence)
+		}
+		g.write(".")
+		g.mark(n.PropertySpan, false)
+		g.write(n.Property)
+	case *ast.NewExpression:
+		g.mark(n.Span, false)
+		g.write("new ")
+		// A call in the callee would take the arguments meant for new
+		if hasCall(n.Callee) {
+			g.write("(")
+			g.expression(n.Callee, 0)
+			g.write(")")
+		} else {
+			g.expression(n.Callee, ast.CallPrecedence)
+		}
+		g.write("(")
+		for i, arg := range n.Arguments {
+			if i > 0 {
+				g.write(",")
+				g.space(" ")
+			}
+			g.expression(arg, ast.Precedence("="))
+		}
+		g.write(")")
+	case *ast.ArrayExpression:
+		g.mark(n.Span, false)
+		g.write("[")
+		for i, element := range n.Elements {
+			if i > 0 {
+				g.write(",")
+				g.space(" ")
+			}
+			g.expression(element, ast.Precedence("="))
+		}
+		g.write("]")
+	case *ast.ObjectExpression:
+		g.mark(n.Span, false)
+		if len(n.Properties) == 0 {
+			g.write("{}")
+			break
+		}
+		g.write("{")
+		g.space(" ")
+		for i := range n.Properties {
+			if i > 0 {
+				g.write(",")
+				g.space(" ")
+			}
+			g.property(&n.Properties[i])
+		}
+		g.space(" ")
+		g.write("}")
+	case *ast.BooleanLiteral:
+		g.mark(n.Span, false)
+		g.write(strconv.FormatBool(n.Value))
+	case *ast.NullLiteral:
+		g.mark(n.Span, false)
+		g.write("null")
+	case *ast.StringLiteral:
+		g.mark(n.Span, false)
+		g.write(Quote(n.Value, g.quote))
+	case *ast.InvalidExpression:
+		g.write("/* invalid expression */")
+	default:
+		g.write("/* unsupported " + node.Type() + " */")
+	}
+}
+
+// property prints a property of an object literal, in shorthand when it was written so
+func (g *generator) property(prop *ast.Property) {
+	g.mark(prop.KeySpan, false)
+	if value, ok := prop.Value.(*ast.Identifier); ok && value.Name == prop.Key && value.Span == prop.KeySpan {
+		g.write(prop.Key)
+		return
+	}
+	g.write(PropertyKey(prop.Key, g.quote) + ":")
+	g.space(" ")
+	g.expression(prop.Value, ast.Precedence("="))
+}
+
+// PropertyKey returns a key of an object literal as written in the source: bare when it is
+// an identifier or a keyword, quoted with the preferred quote otherwise
+func PropertyKey(key string, preferred byte) string {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		letter := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return Quote(key, preferred)
+		}
+	}
+	if key == "" {
+		return Quote(key, preferred)
+	}
+	return key
+}
+
+// startsWithBrace reports whether the code printed for an expression starts with an object literal
+func startsWithBrace(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.ObjectExpression:
+		return true
+	case *ast.BinaryExpression:
+		return startsWithBrace(n.Left)
+	case *ast.CallExpression:
+		return startsWithBrace(n.Callee)
+	case *ast.MemberExpression:
+		return startsWithBrace(n.Object)
+	}
+	return false
+}
+
+// hasCall reports whether the callee of a new expression calls a function outside parentheses
+func hasCall(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.CallExpression:
+		return true
+	case *ast.MemberExpression:
+		return hasCall(n.Object)
+	}
+	return false
+}
+
+// writeIndent writes the indentation of the current nesting level
+func (g *generator) writeIndent() {
+	for i := 0; i < g.level; i++ {
+		g.write(g.indent)
+	}
+}
+
+// commentSafe keeps text from closing the block comment it is printed in
+func commentSafe(text string) string {
+	return strings.ReplaceAll(text, "*/", "* /")
+}
@@ -0,0 +1,149 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"goast/ast"
+	"goast/parser"
+)
+
+// TestRoundTrip prints programs in each style, parses the output back and checks that it
+// prints the same again, so printing loses nothing the parser reads
+func TestRoundTrip(t *testing.T) {
+	sources := map[string]string{
+		"precedence":  "x = (a + b) * c - d / (e % f);\ny = a - (b - c);\nz = a < b === c > d;\nw = a = b = c;\n",
+		"members":     "a.b[c + 1].d(e)[0];\n(1).toString();\n(1.5).toFixed(1);\nnew (f())();\nnew (a.b().c)(1);\nnew a.b.C(1).d;\n",
+		"objects":     "({ a: 1 }).a;\n({}).x = 1;\nlet o = { a, \"b c\": 2, d: { e: [1, { f }] } };\nlet key = { if: 1, \"\": 2, 3: 4 };\n",
+		"strings":     "let s = \"it's\";\nlet t = 'say \"hi\"';\nlet u = \"tab\\there\\nline\\\\\";\nlet v = \"\\u2028\";\n",
+		"statements":  "// leading\nfunction f(a, b = [1, 2], c = { d: 1 }) {\n  if (a) {\n    // inside\n    return;\n  }\n  var x;\n  let y;\n  const z = a;\n  return a + b;\n}\nif (true) {\n}\n",
+		"expressions": "f();\ng(1, \"a\", null, true, false, [], {});\n",
+	}
+	files, err := filepath.Glob("../examples/stdlib/*.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range append(files, "../script.js") {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[filepath.Base(file)] = string(src)
+	}
+
+	styles := map[string]*Options{
+		"default": nil,
+		"compact": {Compact: true},
+		"single":  {Quote: '\'', Indent: "\t"},
+	}
+	for name, src := range sources {
+		program, err := parser.ParseFile(name, src, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for style, opts := range styles {
+			first := Generate(program, opts)
+			reparsed, err := parser.ParseFile(name, first, nil)
+			if err != nil {
+				t.Errorf("%s, %s: output does not parse: %v\n%s", name, style, err, first)
+				continue
+			}
+			if second := Generate(reparsed, opts); second != first {
+				t.Errorf("%s, %s: printed\n%s\nthen\n%s", name, style, first, second)
+			}
+			// Whatever the style, the program is the same
+			if got, want := Generate(reparsed, nil), Generate(program, nil); style != "default" && !sameExceptComments(got, want, opts) {
+				t.Errorf("%s, %s: reads back as\n%s\nwant\n%s", name, style, got, want)
+			}
+		}
+	}
+}
+
+// sameExceptComments compares two default printings, ignoring that compact output drops comments
+func sameExceptComments(got, want string, opts *Options) bool {
+	if opts == nil || !opts.Compact {
+		return got == want
+	}
+	strip := func(s string) string {
+		program, err := parser.ParseFile("strip.js", s, nil)
+		if err != nil {
+			return s
+		}
+		var body []ast.Node
+		for _, node := range program.Body {
+			if _, ok := node.(*ast.Comment); !ok {
+				body = append(body, node)
+			}
+		}
+		program.Body = body
+		return Generate(program, &Options{Compact: true})
+	}
+	return strip(got) == strip(want)
+}
+
+// TestParentheses prints trees the parser cannot produce from unparenthesized source and
+// checks that the parentheses keep their structure
+func TestParentheses(t *testing.T) {
+	id := func(name string) ast.Node { return &ast.Identifier{Name: name} }
+	binary := func(left ast.Node, operator string, right ast.Node) ast.Node {
+		return &ast.BinaryExpression{Left: left, Operator: operator, Right: right}
+	}
+	tests := []struct {
+		node ast.Node
+		want string
+	}{
+		{binary(binary(id("a"), "+", id("b")), "*", id("c")), "(a + b) * c"},
+		{binary(id("a"), "*", binary(id("b"), "+", id("c"))), "a * (b + c)"},
+		{binary(id("a"), "-", binary(id("b"), "-", id("c"))), "a - (b - c)"},
+		{binary(binary(id("a"), "-", id("b")), "-", id("c")), "a - b - c"},
+		{binary(id("a"), "=", binary(id("b"), "=", id("c"))), "a = b = c"},
+		{binary(binary(id("a"), "=", id("b")), "+", id("c")), "(a = b) + c"},
+		{&ast.CallExpression{Callee: binary(id("a"), "+", id("b"))}, "(a + b)()"},
+		{&ast.MemberExpression{Object: binary(id("a"), "+", id("b")), Property: "c"}, "(a + b).c"},
+		{&ast.MemberExpression{Object: &ast.NumericLiteral{Value: "1"}, Property: "c"}, "(1).c"},
+		{&ast.MemberExpression{Object: &ast.NumericLiteral{Value: "1.5"}, Property: "c"}, "1.5.c"},
+		{&ast.NewExpression{Callee: &ast.CallExpression{Callee: id("f")}}, "new (f())()"},
+		{&ast.CallExpression{Callee: id("f"), Arguments: []ast.Node{binary(id("a"), "=", id("b"))}}, "f(a = b)"},
+	}
+	for _, test := range tests {
+		got := Generate(test.node, nil)
+		if got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+			continue
+		}
+		expr, err := parser.ParseExpression(got, nil)
+		if err != nil {
+			t.Errorf("%s: %v", got, err)
+			continue
+		}
+		if again := Generate(expr, nil); again != got {
+			t.Errorf("%s reads back as %s", got, again)
+		}
+	}
+}
+
+// TestQuote checks the choice of quote and the escapes
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		value     string
+		preferred byte
+		want      string
+	}{
+		{"abc", '"', `"abc"`},
+		{"abc", '\'', `'abc'`},
+		{`it's`, '\'', `"it's"`},
+		{`say "hi"`, '"', `'say "hi"'`},
+		{`'"'`, '"', `"'\"'"`},
+		{"a\\b\n\r\t\b\f\v", '"', `"a\\b\n\r\t\b\f\v"`},
+		{"\x00\x1f\x7f", '"', `"\x00\x1f\x7f"`},
+		{"\u2028\u2029", '"', `"\u2028\u2029"`},
+		{"é€😀", '"', `"é€😀"`},
+		{"\xff", '"', `"\ufffd"`},
+	}
+	for _, test := range tests {
+		if got := Quote(test.value, test.preferred); got != test.want {
+			t.Errorf("Quote(%q, %c) = %s, want %s", test.value, test.preferred, got, test.want)
+		}
+	}
+}
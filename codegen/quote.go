@@ -0,0 +1,61 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Quote returns value as a JavaScript string literal
+// The preferred quote is used unless the other one needs fewer escapes, like Prettier does
+// Backslashes, the chosen quote, line terminators and other control characters are escaped
+func Quote(value string, preferred byte) string {
+	quote := preferred
+	if quote != '\'' {
+		quote = '"'
+	}
+	other := byte('\'')
+	if quote == '\'' {
+		other = '"'
+	}
+	if strings.Count(value, string(quote)) > strings.Count(value, string(other)) {
+		quote = other
+	}
+
+	var sb strings.Builder
+	sb.WriteByte(quote)
+	for _, r := range value {
+		switch r {
+		case rune(quote):
+			sb.WriteByte('\\')
+			sb.WriteByte(quote)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\v':
+			sb.WriteString(`\v`)
+		case '\u2028', '\u2029':
+			// Line terminators in JavaScript, even though they are not ASCII
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		case utf8.RuneError:
+			sb.WriteString(`\ufffd`) // Invalid UTF-8 is replaced, as a JavaScript string cannot hold it
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\x%02x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte(quote)
+	return sb.String()
+}
@@ -0,0 +1,241 @@
+// Package constant implements JavaScript's primitive values and the operators on them
+// It evaluates expressions made only of literals without running a program, for the
+// analyses and the minifier; the interpreter uses the same values and operators, so a
+// folded constant is exactly what the program would compute
+package constant
+
+import (
+	"math"
+	"strconv"
+
+	"goast/ast"
+)
+
+// Value is a JavaScript value
+// The primitives are Undefined, Null, Boolean, Number and String; the interpreter adds
+// objects and functions, which this package treats as opaque
+type Value interface {
+	TypeOf() string // Result of the typeof operator
+}
+
+// Undefined is the value of missing variables, arguments and return values
+type Undefined struct{}
+
+// Null is the intentional absence of an object
+type Null struct{}
+
+// Boolean is true or false
+type Boolean bool
+
+// Number is a double-precision floating point number, JavaScript's only numeric type
+type Number float64
+
+// String is a JavaScript string
+// It holds UTF-8 text; lengths and comparisons follow JavaScript's UTF-16 code units
+type String string
+
+func (Undefined) TypeOf() string { return "undefined" }
+func (Null) TypeOf() string      { return "object" } // A historical accident every engine keeps
+func (Boolean) TypeOf() string   { return "boolean" }
+func (Number) TypeOf() string    { return "number" }
+func (String) TypeOf() string    { return "string" }
+
+// IsPrimitive reports whether v is one of the primitive values of this package
+func IsPrimitive(v Value) bool {
+	switch v.(type) {
+	case Undefined, Null, Boolean, Number, String:
+		return true
+	}
+	return false
+}
+
+// Evaluate returns the value of an expression made only of literals and binary operators
+// It reports false for anything else, including assignments
+func Evaluate(node ast.Node) (Value, bool) {
+	switch n := node.(type) {
+	case *ast.NumericLiteral:
+		return Number(StringToNumber(n.Value)), true
+	case *ast.StringLiteral:
+		return String(n.Value), true
+	case *ast.BooleanLiteral:
+		return Boolean(n.Value), true
+	case *ast.NullLiteral:
+		return Null{}, true
+	case *ast.BinaryExpression:
+		left, ok := Evaluate(n.Left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := Evaluate(n.Right)
+		if !ok {
+			return nil, false
+		}
+		return BinaryOperation(n.Operator, left, right)
+	}
+	return nil, false
+}
+
+// Truthy converts a value to a boolean, as an if test does
+// The falsy values are undefined, null, false, 0, -0, NaN and the empty string; a nil
+// Value counts as undefined and anything that is not a primitive is truthy
+func Truthy(v Value) bool {
+	switch v := v.(type) {
+	case nil, Undefined, Null:
+		return false
+	case Boolean:
+		return bool(v)
+	case Number:
+		return v != 0 && !math.IsNaN(float64(v))
+	case String:
+		return v != ""
+	}
+	return true
+}
+
+// ToNumber converts a primitive value to a number, NaN for anything else
+func ToNumber(v Value) float64 {
+	switch v := v.(type) {
+	case Null:
+		return 0
+	case Boolean:
+		if v {
+			return 1
+		}
+		return 0
+	case Number:
+		return float64(v)
+	case String:
+		return StringToNumber(string(v))
+	}
+	return math.NaN()
+}
+
+// ToString converts a primitive value to a string, "undefined" for a nil Value
+func ToString(v Value) string {
+	switch v := v.(type) {
+	case Null:
+		return "null"
+	case Boolean:
+		return strconv.FormatBool(bool(v))
+	case Number:
+		return NumberToString(float64(v))
+	case String:
+		return string(v)
+	}
+	return "undefined"
+}
+
+// BinaryOperation applies a binary operator to two primitive values with JavaScript's
+// coercion rules
+// It reports false for assignment, unknown operators and operands that are not primitives
+func BinaryOperation(operator string, left, right Value) (Value, bool) {
+	if !IsPrimitive(left) || !IsPrimitive(right) {
+		return nil, false
+	}
+	switch operator {
+	case "+":
+		_, ls := left.(String)
+		_, rs := right.(String)
+		if ls || rs {
+			return String(ToString(left) + ToString(right)), true
+		}
+		return Number(ToNumber(left) + ToNumber(right)), true
+	case "-", "*", "/", "%":
+		return Number(Arithmetic(operator, ToNumber(left), ToNumber(right))), true
+	case "<", ">", "<=", ">=":
+		return Boolean(compare(operator, left, right)), true
+	case "==":
+		return Boolean(LooseEquals(left, right)), true
+	case "!=":
+		return Boolean(!LooseEquals(left, right)), true
+	case "===":
+		return Boolean(StrictEquals(left, right)), true
+	case "!==":
+		return Boolean(!StrictEquals(left, right)), true
+	}
+	return nil, false
+}
+
+// Arithmetic applies +, -, *, / or % to two numbers with IEEE 754 semantics
+// % keeps the sign of the dividend, as math.Mod does
+func Arithmetic(operator string, a, b float64) float64 {
+	switch operator {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		return a / b
+	}
+	return math.Mod(a, b)
+}
+
+// compare applies a relational operator to two primitives
+// Two strings compare by UTF-16 code units, anything else numerically; NaN compares false
+func compare(operator string, left, right Value) bool {
+	if a, ok := left.(String); ok {
+		if b, ok := right.(String); ok {
+			c := CompareStrings(string(a), string(b))
+			switch operator {
+			case "<":
+				return c < 0
+			case ">":
+				return c > 0
+			case "<=":
+				return c <= 0
+			}
+			return c >= 0
+		}
+	}
+	a, b := ToNumber(left), ToNumber(right)
+	switch operator {
+	case "<":
+		return a < b
+	case ">":
+		return a > b
+	case "<=":
+		return a <= b
+	}
+	return a >= b
+}
+
+// StrictEquals implements === on primitives: same type and same value, NaN is not equal to itself
+// Other values are equal only to themselves
+func StrictEquals(left, right Value) bool {
+	if l, ok := left.(Number); ok {
+		r, ok := right.(Number)
+		return ok && l == r // Go's float comparison already has NaN != NaN and 0 == -0
+	}
+	return left == right // Primitives compare by value through the interface
+}
+
+// LooseEquals implements == on primitives
+// null and undefined equal each other and nothing else; otherwise booleans become numbers
+// and a string compared with a number is converted to a number
+func LooseEquals(left, right Value) bool {
+	switch {
+	case isNullish(left) || isNullish(right):
+		return isNullish(left) && isNullish(right)
+	case left.TypeOf() == right.TypeOf():
+		return StrictEquals(left, right)
+	}
+	if l, ok := left.(Boolean); ok {
+		return LooseEquals(Number(ToNumber(l)), right)
+	}
+	if r, ok := right.(Boolean); ok {
+		return LooseEquals(left, Number(ToNumber(r)))
+	}
+	// One number and one string
+	return ToNumber(left) == ToNumber(right)
+}
+
+// isNullish reports whether v is null or undefined
+func isNullish(v Value) bool {
+	switch v.(type) {
+	case Null, Undefined:
+		return true
+	}
+	return false
+}
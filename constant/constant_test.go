@@ -0,0 +1,51 @@
+package constant
+
+import (
+	"testing"
+
+	"goast/parser"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		src    string
+		want   string // ToString of the value, "" if the expression is not constant
+		truthy bool
+	}{
+		{"1 + 2 * 3", "7", true},
+		{"'a' + 1 + 2", "a12", true},
+		{"1 + 2 + 'a'", "3a", true},
+		{"0.1 + 0.2", "0.30000000000000004", true},
+		{"1 / 0", "Infinity", true},
+		{"0 / 0", "NaN", false},
+		{"7 % 3 - 1", "0", false},
+		{"'' + 0", "0", true}, // A non-empty string
+		{"null == 0", "false", false},
+		{"'1' == 1", "true", true},
+		{"true == 1", "true", true},
+		{"'\U0001F600' < '￿'", "true", true}, // UTF-16 order, not UTF-8
+		{"'10' < '9'", "true", true},
+		{"'10' < 9", "false", false},
+		{"x + 1", "", false},
+		{"f()", "", false},
+	}
+	for _, tt := range tests {
+		expr, err := parser.ParseExpression(tt.src, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.src, err)
+		}
+		v, ok := Evaluate(expr)
+		if !ok {
+			if tt.want != "" {
+				t.Errorf("%s: not constant, want %s", tt.src, tt.want)
+			}
+			continue
+		}
+		if got := ToString(v); got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.src, got, tt.want)
+		}
+		if Truthy(v) != tt.truthy {
+			t.Errorf("%s: truthy = %v, want %v", tt.src, Truthy(v), tt.truthy)
+		}
+	}
+}
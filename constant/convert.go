@@ -0,0 +1,131 @@
+package constant
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// decimalLiteral matches the decimal numbers JavaScript accepts in strings
+var decimalLiteral = regexp.MustCompile(`^[+-]?(Infinity|([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?)$`)
+
+// IsDecimal reports whether s is a decimal number as JavaScript accepts it in strings,
+// with an optional sign and exponent, or Infinity
+func IsDecimal(s string) bool {
+	return decimalLiteral.MatchString(s)
+}
+
+// StringToNumber implements JavaScript's string to number conversion
+// Surrounding whitespace is ignored, the empty string is 0 and anything else that is not
+// a decimal, hexadecimal (0x), octal (0o) or binary (0b) number is NaN
+func StringToNumber(s string) float64 {
+	s = strings.TrimFunc(s, IsSpace)
+	if s == "" {
+		return 0
+	}
+	if len(s) > 2 && s[0] == '0' {
+		base := 0
+		switch s[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 0 {
+			n, err := strconv.ParseUint(s[2:], base, 64)
+			if err != nil || strings.Contains(s, "_") {
+				return math.NaN()
+			}
+			return float64(n)
+		}
+	}
+	if !IsDecimal(s) {
+		return math.NaN()
+	}
+	switch strings.TrimLeft(s, "+") {
+	case "Infinity":
+		return math.Inf(1)
+	case "-Infinity":
+		return math.Inf(-1)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && !math.IsInf(f, 0) { // Out of range values round to ±Infinity, as in JavaScript
+		return math.NaN()
+	}
+	return f
+}
+
+// IsSpace reports whether r is white space or a line terminator in JavaScript
+func IsSpace(r rune) bool {
+	switch r {
+	case '\t', '\n', '\v', '\f', '\r', ' ', 0xA0, 0x1680, 0x2028, 0x2029, 0x202F, 0x205F, 0x3000, 0xFEFF:
+		return true
+	}
+	return r >= 0x2000 && r <= 0x200A
+}
+
+// NumberToString formats a number the way JavaScript's Number.prototype.toString does:
+// the shortest digits that read back as the same number, in exponent notation below 1e-6
+// and from 1e21 on
+func NumberToString(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		return "0" // Also for -0
+	case f < 0:
+		return "-" + NumberToString(-f)
+	}
+
+	// Shortest digits and decimal exponent: f = 0.digits × 10^n
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exponent)
+	k, n := len(digits), e+1
+
+	switch {
+	case k <= n && n <= 21:
+		return digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return "0." + strings.Repeat("0", -n) + digits
+	}
+	sign := "+"
+	if n-1 < 0 {
+		sign = "-"
+	}
+	exp := strconv.Itoa(abs(n - 1))
+	if k == 1 {
+		return digits + "e" + sign + exp
+	}
+	return digits[:1] + "." + digits[1:] + "e" + sign + exp
+}
+
+// abs returns the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// CompareStrings orders two strings by their UTF-16 code units, the way JavaScript does
+// It differs from Go's byte order for characters outside the Basic Multilingual Plane
+func CompareStrings(a, b string) int {
+	x, y := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			return int(x[i]) - int(y[i])
+		}
+	}
+	return len(x) - len(y)
+}
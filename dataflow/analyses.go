@@ -0,0 +1,176 @@
+package dataflow
+
+import (
+	"slices"
+
+	"goast/ast"
+	"goast/cfg"
+)
+
+// setLattice is the lattice of sets ordered by inclusion, joined by union
+type setLattice struct{}
+
+func (setLattice) Bottom() Set         { return Set{} }
+func (setLattice) Join(a, b Set) Set   { return a.Union(b) }
+func (setLattice) Equal(a, b Set) bool { return a.Equal(b) }
+
+// reachingDefinitions is the analysis behind ReachingDefinitions
+type reachingDefinitions struct {
+	setLattice
+	f *Function
+}
+
+func (reachingDefinitions) Direction() Direction { return Forward }
+func (reachingDefinitions) Boundary() Set        { return Set{} }
+
+func (r reachingDefinitions) Transfer(block *cfg.Block, defs Set) Set {
+	for _, a := range r.f.Accesses[block.Index] {
+		if a.Write {
+			defs = defs.Difference(r.f.defs[a.Variable]).Add(a.Definition)
+		}
+	}
+	return defs
+}
+
+// ReachingDefinitions computes, for the start and end of each block, the set of the
+// definitions, by index in Function.Definitions, that some path reaches the point from
+// without the variable being written again
+func ReachingDefinitions(f *Function) *Result[Set] {
+	return Solve(f.Graph, reachingDefinitions{f: f})
+}
+
+// liveVariables is the analysis behind LiveVariables
+type liveVariables struct {
+	setLattice
+	f *Function
+}
+
+func (liveVariables) Direction() Direction { return Backward }
+func (liveVariables) Boundary() Set        { return Set{} }
+
+func (l liveVariables) Transfer(block *cfg.Block, live Set) Set {
+	accesses := l.f.Accesses[block.Index]
+	for _, a := range slices.Backward(accesses) {
+		live = liveBefore(live, a)
+	}
+	return live
+}
+
+// liveBefore returns the variables live before an access from those live after it
+func liveBefore(live Set, a *Access) Set {
+	if a.Write {
+		return live.Remove(a.Variable)
+	}
+	return live.Add(a.Variable)
+}
+
+// LiveVariables computes, for the start and end of each block, the set of the variables, by
+// index in Function.Variables, that some path from the point reads before writing them
+func LiveVariables(f *Function) *Result[Set] {
+	return Solve(f.Graph, liveVariables{f: f})
+}
+
+// definiteAssignment is the analysis behind DefiniteAssignment
+// It asks whether something holds on all paths, so its sets shrink where paths meet and
+// start full
+type definiteAssignment struct {
+	f *Function
+}
+
+func (d definiteAssignment) Bottom() Set        { return Full(len(d.f.Variables)) }
+func (definiteAssignment) Join(a, b Set) Set    { return a.Intersect(b) }
+func (definiteAssignment) Equal(a, b Set) bool  { return a.Equal(b) }
+func (definiteAssignment) Direction() Direction { return Forward }
+func (definiteAssignment) Boundary() Set        { return Set{} }
+
+func (d definiteAssignment) Transfer(block *cfg.Block, assigned Set) Set {
+	for _, a := range d.f.Accesses[block.Index] {
+		if a.Write {
+			assigned = assigned.Add(a.Variable)
+		}
+	}
+	return assigned
+}
+
+// DefiniteAssignment computes, for the start and end of each block, the set of the variables,
+// by index in Function.Variables, that every path to the point writes
+// Blocks no path reaches have every variable assigned
+func DefiniteAssignment(f *Function) *Result[Set] {
+	return Solve(f.Graph, definiteAssignment{f: f})
+}
+
+// UselessAssignments returns the writes whose value is never read: no path from them reads
+// the variable before writing it again
+// Only initializers and assignments control reaches are considered, and only for variables
+// read somewhere: a variable never read at all is a problem of its own
+func (f *Function) UselessAssignments() []*Access {
+	read := Set{}
+	for _, accesses := range f.Accesses {
+		for _, a := range accesses {
+			if !a.Write {
+				read = read.Add(a.Variable)
+			}
+		}
+	}
+
+	result := LiveVariables(f)
+	reachable := f.Graph.Reachable()
+	var useless []*Access
+	for _, block := range f.Graph.Blocks {
+		if !reachable[block.Index] {
+			continue // Dead code is reported as such
+		}
+		live := result.Out[block.Index]
+		accesses := f.Accesses[block.Index]
+		for _, a := range slices.Backward(accesses) {
+			if a.Write && read.Has(a.Variable) && !live.Has(a.Variable) && assigns(a.Node) {
+				useless = append(useless, a)
+			}
+			live = liveBefore(live, a)
+		}
+	}
+	slices.SortFunc(useless, func(a, b *Access) int { return a.Span.Start - b.Span.Start })
+	return useless
+}
+
+// assigns reports whether the node writing a variable gives it a value of its own:
+// an assignment or a declaration with an initializer
+func assigns(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.VariableDeclaration:
+		return n.Value != nil
+	}
+	return false
+}
+
+// EarlyUse is a read of a variable some path reaches before the variable is written, where
+// it is undefined, or in the temporal dead zone of a let or a const
+type EarlyUse struct {
+	*Access
+	Always bool // No write of the variable reaches the read at all
+}
+
+// EarlyUses returns the reads of variables that are not definitely assigned, in source order
+func (f *Function) EarlyUses() []EarlyUse {
+	assigned := DefiniteAssignment(f)
+	reaching := ReachingDefinitions(f)
+	var uses []EarlyUse
+	for _, block := range f.Graph.Blocks {
+		in, defs := assigned.In[block.Index], reaching.In[block.Index]
+		for _, a := range f.Accesses[block.Index] {
+			if a.Write {
+				in = in.Add(a.Variable)
+				defs = defs.Difference(f.defs[a.Variable]).Add(a.Definition)
+				continue
+			}
+			if !in.Has(a.Variable) {
+				always := defs.Intersect(f.defs[a.Variable]).Equal(Set{})
+				uses = append(uses, EarlyUse{Access: a, Always: always})
+			}
+		}
+	}
+	slices.SortFunc(uses, func(a, b EarlyUse) int { return a.Span.Start - b.Span.Start })
+	return uses
+}
@@ -0,0 +1,218 @@
+// Package dataflow solves monotone data-flow problems over control-flow graphs
+// An Analysis describes the facts it computes as a Lattice, the direction they flow in and
+// how each block transforms them; Solve iterates from the bottom of the lattice with a
+// worklist until nothing changes
+// Reaching definitions, live variables and definite assignment are built in, over the local
+// variables of a function listed by New
+package dataflow
+
+import (
+	"math/bits"
+	"slices"
+
+	"goast/cfg"
+)
+
+// Direction is the direction facts flow in
+type Direction int
+
+const (
+	Forward  Direction = iota // From the entry along the edges, like reaching definitions
+	Backward                  // From the exit against the edges, like liveness
+)
+
+// Lattice is the set of facts of an analysis, ordered so that Join only moves up
+// Bottom is where every block starts: the empty set for analyses asking whether something
+// may happen on some path, the full set for those asking whether it must on all of them
+type Lattice[F any] interface {
+	Bottom() F
+	Join(a, b F) F // Least upper bound, where paths meet
+	Equal(a, b F) bool
+}
+
+// Analysis is a monotone data-flow problem: Transfer must never move a fact down when its
+// input moves up, which guarantees that Solve terminates
+type Analysis[F any] interface {
+	Lattice[F]
+	Direction() Direction
+
+	// Boundary is the fact at the start of the entry of a forward analysis, or at the end
+	// of the exit of a backward one
+	Boundary() F
+
+	// Transfer computes the fact on the far side of a block from the fact on the near one:
+	// at its end from its start going forward, at its start from its end going backward
+	Transfer(block *cfg.Block, fact F) F
+}
+
+// Result holds the facts at the start and at the end of each block, by block Index
+type Result[F any] struct {
+	In  []F
+	Out []F
+}
+
+// Solve computes the fixed point of an analysis over a graph
+// Blocks are visited first in the direction of the analysis, then again whenever the fact
+// flowing into them changes
+func Solve[F any](g *cfg.Graph, a Analysis[F]) *Result[F] {
+	n := len(g.Blocks)
+	r := &Result[F]{In: make([]F, n), Out: make([]F, n)}
+	for i := range n {
+		r.In[i], r.Out[i] = a.Bottom(), a.Bottom()
+	}
+
+	forward := a.Direction() == Forward
+	// near is the side of the blocks facts flow into, far the side they leave from
+	near, far, boundary := r.In, r.Out, g.Entry
+	work := slices.Clone(g.Blocks)
+	if !forward {
+		near, far, boundary = r.Out, r.In, g.Exit
+		slices.Reverse(work)
+	}
+	queued := make([]bool, n)
+	for i := range queued {
+		queued[i] = true
+	}
+
+	for len(work) > 0 {
+		block := work[0]
+		work = work[1:]
+		queued[block.Index] = false
+
+		fact := a.Bottom()
+		if block == boundary {
+			fact = a.Boundary()
+		}
+		from, to := block.Preds, block.Succs
+		if !forward {
+			from, to = block.Succs, block.Preds
+		}
+		for _, e := range from {
+			other := e.From
+			if !forward {
+				other = e.To
+			}
+			fact = a.Join(fact, far[other.Index])
+		}
+		near[block.Index] = fact
+
+		out := a.Transfer(block, fact)
+		if a.Equal(out, far[block.Index]) {
+			continue
+		}
+		far[block.Index] = out
+		for _, e := range to {
+			next := e.To
+			if !forward {
+				next = e.From
+			}
+			if !queued[next.Index] {
+				queued[next.Index] = true
+				work = append(work, next)
+			}
+		}
+	}
+	return r
+}
+
+// Set is a set of small non-negative integers, such as the indexes of variables or of
+// definitions, and the fact of the built-in analyses
+// Sets are values: the operations return new sets and never modify their operands
+type Set struct {
+	words []uint64
+}
+
+// Full returns the set of the integers from 0 to n-1
+func Full(n int) Set {
+	s := Set{words: make([]uint64, (n+63)/64)}
+	for i := range s.words {
+		s.words[i] = ^uint64(0)
+	}
+	if r := n % 64; r != 0 {
+		s.words[len(s.words)-1] = 1<<r - 1
+	}
+	return s
+}
+
+// Has reports whether i is in the set
+func (s Set) Has(i int) bool {
+	return i/64 < len(s.words) && s.words[i/64]&(1<<(i%64)) != 0
+}
+
+// Add returns the set with i added
+func (s Set) Add(i int) Set {
+	words := make([]uint64, max(len(s.words), i/64+1))
+	copy(words, s.words)
+	words[i/64] |= 1 << (i % 64)
+	return Set{words}
+}
+
+// Remove returns the set without i
+func (s Set) Remove(i int) Set {
+	if !s.Has(i) {
+		return s
+	}
+	words := slices.Clone(s.words)
+	words[i/64] &^= 1 << (i % 64)
+	return Set{words}
+}
+
+// Union returns the integers in either set
+func (s Set) Union(t Set) Set {
+	if len(s.words) < len(t.words) {
+		s, t = t, s
+	}
+	words := slices.Clone(s.words)
+	for i, w := range t.words {
+		words[i] |= w
+	}
+	return Set{words}
+}
+
+// Intersect returns the integers in both sets
+func (s Set) Intersect(t Set) Set {
+	words := make([]uint64, min(len(s.words), len(t.words)))
+	for i := range words {
+		words[i] = s.words[i] & t.words[i]
+	}
+	return Set{words}
+}
+
+// Difference returns the integers in s but not in t
+func (s Set) Difference(t Set) Set {
+	words := slices.Clone(s.words)
+	for i := range min(len(s.words), len(t.words)) {
+		words[i] &^= t.words[i]
+	}
+	return Set{words}
+}
+
+// Equal reports whether two sets hold the same integers
+func (s Set) Equal(t Set) bool {
+	for i := range max(len(s.words), len(t.words)) {
+		if s.word(i) != t.word(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Elements returns the integers of the set in increasing order
+func (s Set) Elements() []int {
+	var elements []int
+	for i, w := range s.words {
+		for w != 0 {
+			elements = append(elements, i*64+bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+	return elements
+}
+
+// word returns the i-th word of the set, 0 past its end
+func (s Set) word(i int) uint64 {
+	if i < len(s.words) {
+		return s.words[i]
+	}
+	return 0
+}
@@ -0,0 +1,229 @@
+package dataflow
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"goast/ast"
+	"goast/cfg"
+	"goast/parser"
+	"goast/scope"
+)
+
+// analyze builds the Function of the first function declared in src
+func analyze(t *testing.T, src string) *Function {
+	t.Helper()
+	program, err := parser.ParseFile("flow.js", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(cfg.New(program.Body[0]), scope.Analyze(program, nil))
+}
+
+// names lists the variables of a set, by name
+func names(f *Function, s Set) string {
+	var list []string
+	for _, i := range s.Elements() {
+		list = append(list, f.Variables[i].Name)
+	}
+	return strings.Join(list, " ")
+}
+
+// definitions lists the definitions of a set as name:line
+func definitions(f *Function, src string, s Set) string {
+	lines := ast.NewLines(src)
+	var list []string
+	for _, i := range s.Elements() {
+		d := f.Definitions[i]
+		line, _ := lines.Position(d.Span.Start)
+		list = append(list, fmt.Sprintf("%s:%d", f.Variables[d.Variable].Name, line))
+	}
+	return strings.Join(list, " ")
+}
+
+// branch is a function whose graph has the blocks
+// B0 (params, lines 2-3), B1 (line 4), B2 (lines 6-7), B3 (exit)
+const branch = `function f(p) {
+  let x = 1;
+  if (p) {
+    x = 2;
+  }
+  let y = x;
+  return y;
+}
+`
+
+func TestReachingDefinitions(t *testing.T) {
+	f := analyze(t, branch)
+	r := ReachingDefinitions(f)
+	tests := []struct {
+		block   int
+		in, out string
+	}{
+		{0, "", "p:1 x:2"},
+		{1, "p:1 x:2", "p:1 x:4"},
+		{2, "p:1 x:2 x:4", "p:1 x:2 x:4 y:6"},
+		{3, "p:1 x:2 x:4 y:6", "p:1 x:2 x:4 y:6"},
+	}
+	for _, test := range tests {
+		if got := definitions(f, branch, r.In[test.block]); got != test.in {
+			t.Errorf("B%d in: %s, want %s", test.block, got, test.in)
+		}
+		if got := definitions(f, branch, r.Out[test.block]); got != test.out {
+			t.Errorf("B%d out: %s, want %s", test.block, got, test.out)
+		}
+	}
+}
+
+func TestLiveVariables(t *testing.T) {
+	f := analyze(t, branch)
+	r := LiveVariables(f)
+	tests := []struct {
+		block   int
+		in, out string
+	}{
+		{0, "", "x"}, // p is written by the call before it is read
+		{1, "", "x"},
+		{2, "x", ""},
+		{3, "", ""},
+	}
+	for _, test := range tests {
+		if got := names(f, r.In[test.block]); got != test.in {
+			t.Errorf("B%d in: %s, want %s", test.block, got, test.in)
+		}
+		if got := names(f, r.Out[test.block]); got != test.out {
+			t.Errorf("B%d out: %s, want %s", test.block, got, test.out)
+		}
+	}
+}
+
+func TestDefiniteAssignment(t *testing.T) {
+	src := `function f(p) {
+  var v;
+  let w;
+  if (p) {
+    v = 1;
+  }
+  return v + w;
+}
+`
+	f := analyze(t, src)
+	r := DefiniteAssignment(f)
+	// B0 declares and tests, B1 assigns v, B2 returns
+	tests := []struct {
+		block   int
+		in, out string
+	}{
+		{0, "", "p w"}, // var v; does nothing, let w; makes w undefined
+		{1, "p w", "p v w"},
+		{2, "p w", "p w"},
+	}
+	for _, test := range tests {
+		if got := names(f, r.In[test.block]); got != test.in {
+			t.Errorf("B%d in: %s, want %s", test.block, got, test.in)
+		}
+		if got := names(f, r.Out[test.block]); got != test.out {
+			t.Errorf("B%d out: %s, want %s", test.block, got, test.out)
+		}
+	}
+
+	// Blocks nothing reaches count as assigned, so they do not weaken the blocks after them
+	dead := analyze(t, "function f() {\n  return;\n  let a = 1;\n}\n")
+	if got := names(dead, DefiniteAssignment(dead).In[1]); got != "a" {
+		t.Errorf("dead block in: %s, want a", got)
+	}
+}
+
+func TestEarlyUses(t *testing.T) {
+	src := `function f(p) {
+  var v;
+  var w;
+  if (p) {
+    v = 1;
+  }
+  log(v, w);
+  w = 2;
+  return w;
+}
+`
+	f := analyze(t, src)
+	var got []string
+	for _, use := range f.EarlyUses() {
+		got = append(got, fmt.Sprintf("%s always=%v", f.Variables[use.Variable].Name, use.Always))
+	}
+	want := []string{"v always=false", "w always=true"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUselessAssignments(t *testing.T) {
+	src := `function f(p) {
+  let x = 1;
+  x = 2;
+  if (p) {
+    x = 3;
+  }
+  let unused = 4;
+  return x;
+}
+`
+	f := analyze(t, src)
+	var got []string
+	for _, a := range f.UselessAssignments() {
+		got = append(got, definitions(f, src, Set{}.Add(a.Definition)))
+	}
+	// unused is never read at all, which is reported elsewhere
+	if want := []string{"x:2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCapturedVariables(t *testing.T) {
+	src := `function f() {
+  let shared = 1;
+  let local = 2;
+  function g() {
+    return shared;
+  }
+  return local + g();
+}
+`
+	f := analyze(t, src)
+	var got []string
+	for _, v := range f.Variables {
+		got = append(got, v.Name)
+	}
+	if want := []string{"local", "g"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tracked %q, want %q", got, want)
+	}
+}
+
+func TestSet(t *testing.T) {
+	a := Set{}.Add(1).Add(70)
+	b := Set{}.Add(70).Add(3)
+	tests := []struct {
+		name string
+		got  Set
+		want []int
+	}{
+		{"union", a.Union(b), []int{1, 3, 70}},
+		{"intersect", a.Intersect(b), []int{70}},
+		{"difference", a.Difference(b), []int{1}},
+		{"remove", a.Remove(70), []int{1}},
+		{"full", Full(3), []int{0, 1, 2}},
+	}
+	for _, test := range tests {
+		if got := test.got.Elements(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: %v, want %v", test.name, got, test.want)
+		}
+	}
+	if !a.Equal(Set{}.Add(70).Add(1)) || a.Equal(b) || !(Set{}).Equal(Full(200).Difference(Full(200))) {
+		t.Error("Equal does not compare the elements only")
+	}
+	if a.Has(2) || !a.Has(70) || a.Has(1000) {
+		t.Error("Has is wrong")
+	}
+}
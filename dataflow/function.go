@@ -0,0 +1,154 @@
+package dataflow
+
+import (
+	"goast/ast"
+	"goast/cfg"
+	"goast/scope"
+)
+
+// Function lists what the built-in analyses of a graph work on: the local variables of the
+// function, numbered, and the reads and writes of them in each block
+//
+// The variables are those declared in the function itself, or at the top level for the graph
+// of a program, parameters and functions included. Those a nested function refers to are left
+// out, since calling it may read or write them at any point
+type Function struct {
+	Graph       *cfg.Graph
+	Variables   []*scope.Variable // Tracked variables, by index
+	Definitions []*Access         // Writes, by index
+	Accesses    [][]*Access       // Reads and writes of each block in evaluation order, by block Index
+
+	index map[*scope.Variable]int
+	defs  []Set // Definitions of each variable
+}
+
+// Access is a read or a write of a tracked variable
+// Parameters and function declarations are written at the start of the entry block, in
+// this order, the default value of a parameter being read before it is written
+// Declarations write their variable, even without an initializer, except var declarations
+// without one, which do nothing when they run; assignments write after their value is read
+type Access struct {
+	Variable   int        // Index in Function.Variables
+	Write      bool       // The access writes the variable
+	Definition int        // Index of a write in Function.Definitions, -1 for a read
+	Node       ast.Node   // Identifier, or the VariableDeclaration, Parameter or FunctionDeclaration writing
+	Span       ast.Span   // Location of the variable name
+	Block      *cfg.Block // Block the access happens in
+}
+
+// New lists the variables and accesses of a graph, using the scope analysis of its program
+func New(g *cfg.Graph, scopes *scope.Analysis) *Function {
+	f := &Function{Graph: g, Accesses: make([][]*Access, len(g.Blocks)), index: map[*scope.Variable]int{}}
+	top := scopes.Scope(g.Node)
+	if top == nil {
+		return f
+	}
+	top = top.FunctionScope()
+	for _, s := range scopes.Scopes {
+		if s.FunctionScope() != top {
+			continue
+		}
+		for _, v := range s.Variables {
+			if !captured(v, top) {
+				f.index[v] = len(f.Variables)
+				f.Variables = append(f.Variables, v)
+			}
+		}
+	}
+	f.defs = make([]Set, len(f.Variables))
+	declared := map[ast.Node]*scope.Variable{}
+	for _, v := range f.Variables {
+		for _, decl := range v.Declarations {
+			declared[decl] = v
+		}
+	}
+
+	// Parameters and hoisted functions come first
+	if fn, ok := g.Node.(*ast.FunctionDeclaration); ok {
+		for i := range fn.Params {
+			param := &fn.Params[i]
+			f.expression(g.Entry, param.DefaultValue, scopes)
+			f.write(g.Entry, top.Variable(param.Name), param, param.NameSpan)
+		}
+	}
+	for _, v := range f.Variables {
+		if v.Kind == "function" {
+			decl := v.Declarations[len(v.Declarations)-1].(*ast.FunctionDeclaration)
+			f.write(g.Entry, v, decl, decl.NameSpan)
+		}
+	}
+
+	for _, block := range g.Blocks {
+		for _, node := range block.Nodes {
+			switch n := node.(type) {
+			case *ast.VariableDeclaration:
+				f.expression(block, n.Value, scopes)
+				if n.Value != nil || n.Kind != "var" {
+					f.write(block, declared[n], n, n.NameSpan)
+				}
+			case *ast.IfStatement:
+				f.expression(block, n.Test, scopes)
+			case *ast.ReturnStatement:
+				f.expression(block, n.Argument, scopes)
+			case *ast.FunctionDeclaration:
+			default:
+				f.expression(block, n, scopes)
+			}
+		}
+	}
+	return f
+}
+
+// captured reports whether a variable is referred to from a function nested in top
+func captured(v *scope.Variable, top *scope.Scope) bool {
+	for _, ref := range v.References {
+		if ref.From.FunctionScope() != top {
+			return true
+		}
+	}
+	return false
+}
+
+// expression records the accesses of an expression in evaluation order
+func (f *Function) expression(block *cfg.Block, node ast.Node, scopes *scope.Analysis) {
+	if node == nil {
+		return
+	}
+	ast.Traverse(node, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Identifier); ok {
+			if ref := scopes.Reference(id); ref != nil && !ref.Write {
+				f.read(block, ref.Resolved, id)
+			}
+		}
+		return true
+	}, func(n ast.Node) {
+		// The target of an assignment is written once the value is computed
+		if b, ok := n.(*ast.BinaryExpression); ok && b.Operator == "=" {
+			if id, ok := b.Left.(*ast.Identifier); ok {
+				if ref := scopes.Reference(id); ref != nil {
+					f.write(block, ref.Resolved, id, id.Span)
+				}
+			}
+		}
+	})
+}
+
+// read records a read of a variable, if it is tracked
+func (f *Function) read(block *cfg.Block, v *scope.Variable, id *ast.Identifier) {
+	if i, ok := f.index[v]; ok {
+		a := &Access{Variable: i, Definition: -1, Node: id, Span: id.Span, Block: block}
+		f.Accesses[block.Index] = append(f.Accesses[block.Index], a)
+	}
+}
+
+// write records a write of a variable, if it is tracked, as a new definition
+func (f *Function) write(block *cfg.Block, v *scope.Variable, node ast.Node, span ast.Span) {
+	i, ok := f.index[v]
+	if !ok {
+		return
+	}
+	a := &Access{Variable: i, Write: true, Definition: len(f.Definitions), Node: node, Span: span, Block: block}
+	f.defs[i] = f.defs[i].Add(a.Definition)
+	f.Definitions = append(f.Definitions, a)
+	f.Accesses[block.Index] = append(f.Accesses[block.Index], a)
+}
@@ -0,0 +1,346 @@
+// Package estree converts the AST to and from ESTree-shaped JSON
+// ESTree is the AST format used by Acorn, Babel and ESLint, so the output can be
+// compared against their fixtures
+package estree
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"goast/ast"
+	"goast/codegen"
+)
+
+// Options configures Marshal
+// A nil *Options is valid and produces compact JSON without loc
+type Options struct {
+	Source string // Source text the AST was parsed from, enables "loc" on every node
+	Indent string // Indentation for pretty-printed output, compact when empty
+}
+
+// Marshal serializes node and all of its children to ESTree JSON
+// Nodes carrying a valid Span get a "range" field, plus "loc" when opts.Source is set
+// Comments are moved out of the statement lists into a "comments" array on the Program,
+// the same way Acorn and Babel report them
+func Marshal(node ast.Node, opts *Options) ([]byte, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	m := &marshaler{source: opts.Source, lines: ast.NewLines(opts.Source), hasSource: opts.Source != ""}
+	value := m.node(node)
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil || opts.Indent == "" {
+		return data, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", opts.Indent); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// object is a JSON object that keeps its keys in insertion order
+// ESTree output is much easier to read with "type" first
+type object []member
+
+// member is a single key/value pair of an object
+type member struct {
+	Key   string
+	Value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.Key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshaler holds the state shared while converting one tree
+type marshaler struct {
+	source    string    // Source text, used for the raw spelling of literals
+	lines     ast.Lines // Line table of the source
+	hasSource bool      // Whether loc can be computed
+	comments  []object  // Comments collected from every statement list
+	err       error     // First node that cannot be represented, returned by Marshal
+}
+
+// node converts any AST node to its ESTree representation
+// A nil node becomes JSON null, as ESTree does for missing children
+func (m *marshaler) node(node ast.Node) any {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *ast.Program:
+		body := m.statements(n.Body)
+		obj := m.withSpan(object{{"type", "Program"}, {"sourceType", "script"}, {"body", body}}, n.Span)
+		comments := m.comments
+		if comments == nil {
+			comments = []object{}
+		}
+		return append(obj, member{"comments", comments})
+	case *ast.FunctionDeclaration:
+		params := make([]any, len(n.Params))
+		for i, param := range n.Params {
+			params[i] = m.parameter(param)
+		}
+		return m.withSpan(object{
+			{"type", "FunctionDeclaration"},
+			{"id", m.identifier(n.Name, n.NameSpan)},
+			{"expression", false},
+			{"generator", false},
+			{"async", false},
+			{"params", params},
+			{"body", m.block(n.Body, n.BodySpan)},
+		}, n.Span)
+	case *ast.IfStatement:
+		return m.withSpan(object{
+			{"type", "IfStatement"},
+			{"test", m.node(n.Test)},
+			{"consequent", m.block(n.Consequent, n.ConsequentSpan)},
+			{"alternate", nil},
+		}, n.Span)
+	case *ast.ReturnStatement:
+		return m.withSpan(object{{"type", "ReturnStatement"}, {"argument", m.node(n.Argument)}}, n.Span)
+	case *ast.VariableDeclaration:
+		declarator := object{
+			{"type", "VariableDeclarator"},
+			{"id", m.identifier(n.Name, n.NameSpan)},
+			{"init", m.node(n.Value)},
+		}
+		declaratorSpan := n.NameSpan
+		if n.Value != nil && n.Value.Range().IsValid() {
+			declaratorSpan.End = n.Value.Range().End
+		}
+		return m.withSpan(object{
+			{"type", "VariableDeclaration"},
+			{"kind", n.Kind},
+			{"declarations", []any{m.withSpan(declarator, declaratorSpan)}},
+		}, n.Span)
+	case *ast.BinaryExpression:
+		// Our parser accepts "=" as an operator, ESTree models that as an assignment
+		typ := "BinaryExpression"
+		if n.Operator == "=" {
+			typ = "AssignmentExpression"
+		}
+		return m.withSpan(object{
+			{"type", typ},
+			{"operator", n.Operator},
+			{"left", m.node(n.Left)},
+			{"right", m.node(n.Right)},
+		}, n.Span)
+	case *ast.CallExpression:
+		return m.withSpan(object{
+			{"type", "CallExpression"},
+			{"callee", m.node(n.Callee)},
+			{"arguments", m.nodes(n.Arguments)},
+			{"optional", false},
+		}, n.Span)
+	case *ast.MemberExpression:
+		var property any = m.identifier(n.Property, n.PropertySpan)
+		if n.Index != nil {
+			property = m.node(n.Index)
+		}
+		return m.withSpan(object{
+			{"type", "MemberExpression"},
+			{"object", m.node(n.Object)},
+			{"property", property},
+			{"computed", n.Index != nil},
+			{"optional", false},
+		}, n.Span)
+	case *ast.NewExpression:
+		return m.withSpan(object{
+			{"type", "NewExpression"},
+			{"callee", m.node(n.Callee)},
+			{"arguments", m.nodes(n.Arguments)},
+		}, n.Span)
+	case *ast.ArrayExpression:
+		return m.withSpan(object{{"type", "ArrayExpression"}, {"elements", m.nodes(n.Elements)}}, n.Span)
+	case *ast.ObjectExpression:
+		properties := make([]any, len(n.Properties))
+		for i := range n.Properties {
+			properties[i] = m.property(&n.Properties[i])
+		}
+		return m.withSpan(object{{"type", "ObjectExpression"}, {"properties", properties}}, n.Span)
+	case *ast.ExpressionStatement:
+		return m.withSpan(object{{"type", "ExpressionStatement"}, {"expression", m.node(n.Expression)}}, n.Span)
+	case *ast.Identifier:
+		return m.identifier(n.Name, n.Span)
+	case *ast.StringLiteral:
+		return m.withSpan(object{
+			{"type", "Literal"},
+			{"value", n.Value},
+			{"raw", m.raw(n.Span, codegen.Quote(n.Value, '"'))},
+		}, n.Span)
+	case *ast.NumericLiteral:
+		return m.withSpan(object{{"type", "Literal"}, {"value", m.number(n)}, {"raw", m.raw(n.Span, n.Value)}}, n.Span)
+	case *ast.BooleanLiteral:
+		return m.withSpan(object{{"type", "Literal"}, {"value", n.Value}, {"raw", m.raw(n.Span, strconv.FormatBool(n.Value))}}, n.Span)
+	case *ast.NullLiteral:
+		return m.withSpan(object{{"type", "Literal"}, {"value", nil}, {"raw", m.raw(n.Span, "null")}}, n.Span)
+	case *ast.Comment:
+		// Comments only appear in statement lists, which route them to m.comments
+		return m.comment(n)
+	case *ast.ErrorNode:
+		return m.withSpan(object{{"type", "ErrorNode"}, {"message", n.Message}}, n.Span)
+	case *ast.InvalidExpression:
+		return m.withSpan(object{{"type", "InvalidExpression"}}, n.Span)
+	default:
+		if m.err == nil {
+			m.err = fmt.Errorf("estree: cannot marshal %s node", node.Type())
+		}
+		return nil
+	}
+}
+
+// nodes converts a list of expressions
+func (m *marshaler) nodes(nodes []ast.Node) []any {
+	out := make([]any, len(nodes))
+	for i, node := range nodes {
+		out[i] = m.node(node)
+	}
+	return out
+}
+
+// property converts a property of an object literal to an ESTree Property
+// The key is an Identifier, or a Literal when it was written as a string or a number
+func (m *marshaler) property(prop *ast.Property) object {
+	value, _ := prop.Value.(*ast.Identifier)
+	shorthand := value != nil && value.Name == prop.Key && value.Span == prop.KeySpan
+
+	var key object
+	raw := m.raw(prop.KeySpan, codegen.PropertyKey(prop.Key, '"'))
+	switch {
+	case strings.HasPrefix(raw, "\"") || strings.HasPrefix(raw, "'"):
+		key = m.withSpan(object{{"type", "Literal"}, {"value", prop.Key}, {"raw", raw}}, prop.KeySpan)
+	case raw != "" && raw[0] >= '0' && raw[0] <= '9':
+		number, _ := strconv.ParseFloat(raw, 64)
+		key = m.withSpan(object{{"type", "Literal"}, {"value", number}, {"raw", raw}}, prop.KeySpan)
+	default:
+		key = m.identifier(prop.Key, prop.KeySpan)
+	}
+	return m.withSpan(object{
+		{"type", "Property"},
+		{"key", key},
+		{"value", m.node(prop.Value)},
+		{"kind", "init"},
+		{"method", false},
+		{"shorthand", shorthand},
+		{"computed", false},
+	}, prop.Span)
+}
+
+// statements converts a statement list, collecting comments separately
+func (m *marshaler) statements(nodes []ast.Node) []any {
+	out := []any{}
+	for _, node := range nodes {
+		if comment, ok := node.(*ast.Comment); ok {
+			m.comments = append(m.comments, m.comment(comment))
+			continue
+		}
+		out = append(out, m.node(node))
+	}
+	return out
+}
+
+// block wraps a statement list in a BlockStatement spanning the braces
+func (m *marshaler) block(nodes []ast.Node, span ast.Span) object {
+	return m.withSpan(object{{"type", "BlockStatement"}, {"body", m.statements(nodes)}}, span)
+}
+
+// parameter converts a function parameter to an Identifier or, with a default, an AssignmentPattern
+func (m *marshaler) parameter(param ast.Parameter) object {
+	id := m.identifier(param.Name, param.NameSpan)
+	if param.DefaultValue == nil {
+		return id
+	}
+	return m.withSpan(object{
+		{"type", "AssignmentPattern"},
+		{"left", id},
+		{"right", m.node(param.DefaultValue)},
+	}, param.Span)
+}
+
+// identifier builds an ESTree Identifier
+func (m *marshaler) identifier(name string, span ast.Span) object {
+	return m.withSpan(object{{"type", "Identifier"}, {"name", name}}, span)
+}
+
+// comment builds an ESTree line comment, whose value excludes the leading //
+func (m *marshaler) comment(c *ast.Comment) object {
+	return m.withSpan(object{{"type", "Line"}, {"value", strings.TrimPrefix(c.Text, "//")}}, c.Span)
+}
+
+// withSpan appends start, end, range and loc for nodes that have a position
+func (m *marshaler) withSpan(obj object, span ast.Span) object {
+	if !span.IsValid() {
+		return obj
+	}
+	obj = append(obj,
+		member{"start", span.Start},
+		member{"end", span.End},
+	)
+	if m.hasSource {
+		obj = append(obj, member{"loc", object{
+			{"start", m.position(span.Start)},
+			{"end", m.position(span.End)},
+		}})
+	}
+	return append(obj, member{"range", []int{span.Start, span.End}})
+}
+
+// position converts an offset into an ESTree position (1-based line, 0-based column)
+func (m *marshaler) position(offset int) object {
+	line, column := m.lines.Position(offset)
+	return object{{"line", line}, {"column", column - 1}}
+}
+
+// number returns the value of a numeric literal as a JSON number
+// Literals too large for a float64 evaluate to Infinity in JavaScript; JSON has no Infinity,
+// so they are written as 1e999, which JavaScript's JSON.parse reads back as Infinity
+func (m *marshaler) number(n *ast.NumericLiteral) any {
+	number, err := strconv.ParseFloat(n.Value, 64)
+	switch {
+	case err == nil:
+		return number
+	case errors.Is(err, strconv.ErrRange) && math.IsInf(number, 0):
+		if number < 0 {
+			return json.Number("-1e999")
+		}
+		return json.Number("1e999")
+	}
+	if m.err == nil {
+		m.err = fmt.Errorf("estree: invalid numeric literal %q", n.Value)
+	}
+	return nil
+}
+
+// raw returns the source text of a literal, or fallback when the source is not available
+func (m *marshaler) raw(span ast.Span, fallback string) string {
+	if m.hasSource && span.IsValid() && span.End <= len(m.source) {
+		return m.source[span.Start:span.End]
+	}
+	return fallback
+}
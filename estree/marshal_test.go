@@ -0,0 +1,61 @@
+package estree
+
+import (
+	"strings"
+	"testing"
+
+	"goast/ast"
+	"goast/parser"
+)
+
+func TestNumericLiteralValue(t *testing.T) {
+	huge := "1" + strings.Repeat("0", 400)
+	tests := []struct {
+		literal string
+		value   string // JSON of the "value" field
+	}{
+		{"42", "42"},
+		{"1.50", "1.5"},
+		{huge, "1e999"},
+		{"0." + strings.Repeat("0", 400) + "1", "0"},
+	}
+	for _, tt := range tests {
+		program, err := parser.ParseFile("num.js", tt.literal+";", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := Marshal(program, nil)
+		if err != nil {
+			t.Fatalf("%.20s: %v", tt.literal, err)
+		}
+		if want := `"value":` + tt.value + `,`; !strings.Contains(string(data), want) {
+			t.Errorf("%.20s: JSON %.200s does not contain %s", tt.literal, data, want)
+		}
+		// The value reads back as the same literal
+		back, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("%.20s: %v", tt.literal, err)
+		}
+		literal := back.(*ast.Program).Body[0].(*ast.ExpressionStatement).Expression.(*ast.NumericLiteral)
+		if literal.Value != tt.literal {
+			t.Errorf("%.20s: read back as %.20s", tt.literal, literal.Value)
+		}
+	}
+
+	if _, err := Marshal(&ast.NumericLiteral{Value: "abc"}, nil); err == nil {
+		t.Error("invalid numeric literal marshaled without an error")
+	}
+}
+
+// unknownNode is a node type the marshaler does not know
+type unknownNode struct{ ast.Span }
+
+func (unknownNode) Type() string { return "UnknownNode" }
+
+func TestMarshalUnknownNode(t *testing.T) {
+	program := &ast.Program{Body: []ast.Node{&ast.ExpressionStatement{Expression: unknownNode{}}}}
+	_, err := Marshal(program, nil)
+	if want := "estree: cannot marshal UnknownNode node"; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
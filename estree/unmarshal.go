@@ -0,0 +1,660 @@
+package estree
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"goast/ast"
+)
+
+// UnmarshalError reports a JSON value that cannot be turned into an AST node
+// Path locates the value in the document, for example $.body[2].declarations[0].init
+type UnmarshalError struct {
+	Path    string // JSON path of the offending value
+	Message string // What is wrong with it
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Unmarshal decodes ESTree JSON into AST nodes
+// It accepts the output of Marshal as well as Acorn and Babel style trees, as long as they only
+// use constructs our AST can represent; anything else is reported as an *UnmarshalError
+// Comments listed on the Program are put back into the statement list they belong to,
+// using their ranges when present
+func Unmarshal(data []byte) (ast.Node, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Keep numbers exact for literal values and offsets
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return decode(value, "$")
+}
+
+// jsonObject is a decoded ESTree node together with its path
+type jsonObject struct {
+	fields map[string]any
+	path   string
+}
+
+// decode converts one decoded JSON value into an AST node
+// JSON null becomes a nil node
+func decode(value any, path string) (ast.Node, error) {
+	if value == nil {
+		return nil, nil
+	}
+	obj, err := asObject(value, path)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := obj.str("type")
+	if err != nil {
+		return nil, err
+	}
+	span := obj.span()
+
+	switch typ {
+	case "Program":
+		body, err := obj.statements("body")
+		if err != nil {
+			return nil, err
+		}
+		program := &ast.Program{Span: span, Body: body}
+		if err := obj.restoreComments(program); err != nil {
+			return nil, err
+		}
+		return program, nil
+	case "FunctionDeclaration":
+		return obj.functionDeclaration(span)
+	case "IfStatement":
+		if alternate, ok := obj.fields["alternate"]; ok && alternate != nil {
+			return nil, &UnmarshalError{Path: path + ".alternate", Message: "else branches are not supported"}
+		}
+		test, err := obj.child("test")
+		if err != nil {
+			return nil, err
+		}
+		consequent, consequentSpan, err := obj.block("consequent")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.IfStatement{Span: span, Test: test, Consequent: consequent, ConsequentSpan: consequentSpan}, nil
+	case "ReturnStatement":
+		argument, err := obj.child("argument")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ReturnStatement{Span: span, Argument: argument}, nil
+	case "VariableDeclaration":
+		return obj.variableDeclaration(span)
+	case "BinaryExpression", "AssignmentExpression":
+		operator, err := obj.str("operator")
+		if err != nil {
+			return nil, err
+		}
+		if typ == "AssignmentExpression" && operator != "=" {
+			return nil, &UnmarshalError{Path: path + ".operator", Message: fmt.Sprintf("unsupported assignment operator %q", operator)}
+		}
+		// Precedence knows every operator the parser accepts, = only appears in assignments
+		if typ == "BinaryExpression" && (ast.Precedence(operator) == 0 || operator == "=") {
+			return nil, &UnmarshalError{Path: path + ".operator", Message: fmt.Sprintf("unsupported binary operator %q", operator)}
+		}
+		left, err := obj.child("left")
+		if err != nil {
+			return nil, err
+		}
+		if operator == "=" {
+			switch left.(type) {
+			case *ast.Identifier, *ast.MemberExpression:
+			default:
+				return nil, &UnmarshalError{Path: path + ".left", Message: "assignment target must be an identifier or a member expression"}
+			}
+		}
+		right, err := obj.child("right")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BinaryExpression{Span: span, Left: left, Operator: operator, Right: right}, nil
+	case "CallExpression":
+		if optional, _ := obj.fields["optional"].(bool); optional {
+			return nil, &UnmarshalError{Path: path + ".optional", Message: "optional calls are not supported"}
+		}
+		callee, err := obj.child("callee")
+		if err != nil {
+			return nil, err
+		}
+		args, err := obj.nodes("arguments", "argument")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.CallExpression{Span: span, Callee: callee, Arguments: args}, nil
+	case "NewExpression":
+		callee, err := obj.child("callee")
+		if err != nil {
+			return nil, err
+		}
+		args, err := obj.nodes("arguments", "argument")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.NewExpression{Span: span, Callee: callee, Arguments: args}, nil
+	case "ArrayExpression":
+		elements, err := obj.nodes("elements", "element")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ArrayExpression{Span: span, Elements: elements}, nil
+	case "ObjectExpression":
+		return obj.objectExpression(span)
+	case "MemberExpression":
+		if optional, _ := obj.fields["optional"].(bool); optional {
+			return nil, &UnmarshalError{Path: path + ".optional", Message: "optional chaining is not supported"}
+		}
+		object, err := obj.child("object")
+		if err != nil {
+			return nil, err
+		}
+		if computed, _ := obj.fields["computed"].(bool); computed {
+			index, err := obj.child("property")
+			if err != nil {
+				return nil, err
+			}
+			return &ast.MemberExpression{Span: span, Object: object, Index: index}, nil
+		}
+		property, propertySpan, err := obj.identifier("property")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.MemberExpression{Span: span, Object: object, Property: property, PropertySpan: propertySpan}, nil
+	case "ExpressionStatement":
+		expression, err := obj.child("expression")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ExpressionStatement{Span: span, Expression: expression}, nil
+	case "Identifier":
+		name, err := obj.str("name")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Identifier{Span: span, Name: name}, nil
+	case "Literal", "StringLiteral", "NumericLiteral", "BooleanLiteral", "NullLiteral":
+		return obj.literal(span)
+	case "Line", "CommentLine":
+		text, err := obj.str("value")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Comment{Span: span, Text: "//" + text}, nil
+	case "Block", "CommentBlock":
+		text, err := obj.str("value")
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Comment{Span: span, Text: "/*" + text + "*/"}, nil
+	case "ErrorNode":
+		message, _ := obj.fields["message"].(string)
+		return &ast.ErrorNode{Span: span, Message: message}, nil
+	case "InvalidExpression":
+		return &ast.InvalidExpression{Span: span}, nil
+	default:
+		return nil, &UnmarshalError{Path: path, Message: fmt.Sprintf("unknown node type %q", typ)}
+	}
+}
+
+// functionDeclaration decodes a FunctionDeclaration and its parameters
+func (obj jsonObject) functionDeclaration(span ast.Span) (ast.Node, error) {
+	for _, flag := range []string{"generator", "async"} {
+		if set, _ := obj.fields[flag].(bool); set {
+			return nil, &UnmarshalError{Path: obj.path + "." + flag, Message: flag + " functions are not supported"}
+		}
+	}
+	name, nameSpan, err := obj.identifier("id")
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := obj.array("params")
+	if err != nil {
+		return nil, err
+	}
+	params := make([]ast.Parameter, len(values))
+	for i, value := range values {
+		param, err := decodeParameter(value, fmt.Sprintf("%s.params[%d]", obj.path, i))
+		if err != nil {
+			return nil, err
+		}
+		params[i] = param
+	}
+
+	body, bodySpan, err := obj.block("body")
+	if err != nil {
+		return nil, err
+	}
+	return &ast.FunctionDeclaration{
+		Span:     span,
+		NameSpan: nameSpan,
+		Name:     name,
+		Params:   params,
+		Body:     body,
+		BodySpan: bodySpan,
+	}, nil
+}
+
+// decodeParameter decodes an Identifier or an AssignmentPattern into a Parameter
+func decodeParameter(value any, path string) (ast.Parameter, error) {
+	obj, err := asObject(value, path)
+	if err != nil {
+		return ast.Parameter{}, err
+	}
+	typ, err := obj.str("type")
+	if err != nil {
+		return ast.Parameter{}, err
+	}
+
+	switch typ {
+	case "Identifier":
+		name, err := obj.str("name")
+		if err != nil {
+			return ast.Parameter{}, err
+		}
+		return ast.Parameter{Span: obj.span(), NameSpan: obj.span(), Name: name}, nil
+	case "AssignmentPattern":
+		name, nameSpan, err := obj.identifier("left")
+		if err != nil {
+			return ast.Parameter{}, err
+		}
+		defaultValue, err := obj.child("right")
+		if err != nil {
+			return ast.Parameter{}, err
+		}
+		return ast.Parameter{Span: obj.span(), NameSpan: nameSpan, Name: name, DefaultValue: defaultValue}, nil
+	default:
+		return ast.Parameter{}, &UnmarshalError{Path: path, Message: fmt.Sprintf("unsupported parameter type %q", typ)}
+	}
+}
+
+// objectExpression decodes an ObjectExpression whose properties are plain key: value pairs
+// Babel's ObjectProperty is accepted too; methods, accessors, spreads and computed keys are not
+func (obj jsonObject) objectExpression(span ast.Span) (ast.Node, error) {
+	values, err := obj.array("properties")
+	if err != nil {
+		return nil, err
+	}
+	properties := make([]ast.Property, len(values))
+	for i, value := range values {
+		prop, err := asObject(value, fmt.Sprintf("%s.properties[%d]", obj.path, i))
+		if err != nil {
+			return nil, err
+		}
+		if typ, _ := prop.fields["type"].(string); typ != "Property" && typ != "ObjectProperty" {
+			return nil, &UnmarshalError{Path: prop.path, Message: fmt.Sprintf("unsupported property type %q", typ)}
+		}
+		if kind, ok := prop.fields["kind"].(string); ok && kind != "init" {
+			return nil, &UnmarshalError{Path: prop.path + ".kind", Message: "accessor properties are not supported"}
+		}
+		for _, flag := range []string{"method", "computed"} {
+			if set, _ := prop.fields[flag].(bool); set {
+				return nil, &UnmarshalError{Path: prop.path + "." + flag, Message: flag + " properties are not supported"}
+			}
+		}
+		key, keySpan, err := prop.propertyKey()
+		if err != nil {
+			return nil, err
+		}
+		value, err := prop.child("value")
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, &UnmarshalError{Path: prop.path + ".value", Message: "property value is null"}
+		}
+		properties[i] = ast.Property{Span: prop.span(), KeySpan: keySpan, Key: key, Value: value}
+	}
+	return &ast.ObjectExpression{Span: span, Properties: properties}, nil
+}
+
+// propertyKey decodes the key of a property, an Identifier or a string or number Literal,
+// into the property name
+func (obj jsonObject) propertyKey() (string, ast.Span, error) {
+	key, err := asObject(obj.fields["key"], obj.path+".key")
+	if err != nil {
+		return "", ast.Span{}, err
+	}
+	switch value := key.fields["value"].(type) {
+	case string:
+		return value, key.span(), nil
+	case json.Number:
+		number, err := value.Float64()
+		if err != nil {
+			return "", ast.Span{}, &UnmarshalError{Path: key.path + ".value", Message: err.Error()}
+		}
+		if number < 1e21 {
+			return strconv.FormatFloat(number, 'f', -1, 64), key.span(), nil
+		}
+		return strconv.FormatFloat(number, 'g', -1, 64), key.span(), nil
+	}
+	return obj.identifier("key")
+}
+
+// variableDeclaration decodes a VariableDeclaration with exactly one declarator
+func (obj jsonObject) variableDeclaration(span ast.Span) (ast.Node, error) {
+	kind, err := obj.str("kind")
+	if err != nil {
+		return nil, err
+	}
+	if kind != "const" && kind != "let" && kind != "var" {
+		return nil, &UnmarshalError{Path: obj.path + ".kind", Message: fmt.Sprintf("unsupported declaration kind %q", kind)}
+	}
+	declarations, err := obj.array("declarations")
+	if err != nil {
+		return nil, err
+	}
+	if len(declarations) != 1 {
+		return nil, &UnmarshalError{
+			Path:    obj.path + ".declarations",
+			Message: fmt.Sprintf("expected exactly one declarator, found %d", len(declarations)),
+		}
+	}
+
+	declarator, err := asObject(declarations[0], obj.path+".declarations[0]")
+	if err != nil {
+		return nil, err
+	}
+	name, nameSpan, err := declarator.identifier("id")
+	if err != nil {
+		return nil, err
+	}
+	value, err := declarator.child("init")
+	if err != nil {
+		return nil, err
+	}
+	return &ast.VariableDeclaration{Span: span, NameSpan: nameSpan, Kind: kind, Name: name, Value: value}, nil
+}
+
+// literal decodes string, numeric, boolean and null literals
+// Regular expressions and BigInts have no node in our AST
+func (obj jsonObject) literal(span ast.Span) (ast.Node, error) {
+	if typ, _ := obj.fields["type"].(string); typ == "NullLiteral" {
+		return &ast.NullLiteral{Span: span}, nil
+	}
+	_, regex := obj.fields["regex"]
+	_, bigint := obj.fields["bigint"]
+	switch value := obj.fields["value"].(type) {
+	case nil:
+		if regex || bigint {
+			return nil, &UnmarshalError{Path: obj.path, Message: "regular expression and BigInt literals are not supported"}
+		}
+		return &ast.NullLiteral{Span: span}, nil
+	case bool:
+		return &ast.BooleanLiteral{Span: span, Value: value}, nil
+	case string:
+		return &ast.StringLiteral{Span: span, Value: value}, nil
+	case json.Number:
+		// Prefer the source spelling so 3.0 stays 3.0, as long as it is a literal the lexer
+		// reads and spells the same number; hexadecimal and other forms fall back on value
+		raw, ok := obj.fields["raw"].(string)
+		if !ok || !isDecimal(raw) {
+			return &ast.NumericLiteral{Span: span, Value: value.String()}, nil
+		}
+		if parseNumber(raw) != parseNumber(value.String()) {
+			return nil, &UnmarshalError{Path: obj.path + ".raw", Message: fmt.Sprintf("raw %q does not match value %s", raw, value)}
+		}
+		return &ast.NumericLiteral{Span: span, Value: raw}, nil
+	default:
+		return nil, &UnmarshalError{Path: obj.path + ".value", Message: fmt.Sprintf("unsupported literal value %v", value)}
+	}
+}
+
+// isDecimal reports whether s is a number literal as the lexer reads it: digits, optionally
+// followed by a decimal point and more digits
+func isDecimal(s string) bool {
+	digits, fraction, _ := strings.Cut(s, ".")
+	if digits == "" {
+		return false
+	}
+	for _, c := range digits + fraction {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseNumber returns the float64 a number literal evaluates to, ±Inf if it is too large
+func parseNumber(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// block decodes a BlockStatement field into a statement list and the span of its braces
+// A single statement in place of a block is accepted and treated as a one-statement block
+func (obj jsonObject) block(key string) ([]ast.Node, ast.Span, error) {
+	value, ok := obj.fields[key]
+	if !ok || value == nil {
+		return nil, ast.Span{}, &UnmarshalError{Path: obj.path + "." + key, Message: "missing block"}
+	}
+	block, err := asObject(value, obj.path+"."+key)
+	if err != nil {
+		return nil, ast.Span{}, err
+	}
+	if typ, _ := block.fields["type"].(string); typ != "BlockStatement" {
+		statement, err := decode(value, block.path)
+		if err != nil {
+			return nil, ast.Span{}, err
+		}
+		if !isStatement(statement) {
+			return nil, ast.Span{}, &UnmarshalError{Path: block.path, Message: fmt.Sprintf("expected a statement, found %s", typeOf(value))}
+		}
+		return []ast.Node{statement}, block.span(), nil
+	}
+	body, err := block.statements("body")
+	return body, block.span(), err
+}
+
+// statements decodes an array of statements
+// Expressions must be wrapped in an ExpressionStatement to appear there
+func (obj jsonObject) statements(key string) ([]ast.Node, error) {
+	values, err := obj.array(key)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]ast.Node, 0, len(values))
+	for i, value := range values {
+		node, err := decode(value, fmt.Sprintf("%s.%s[%d]", obj.path, key, i))
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			return nil, &UnmarshalError{Path: fmt.Sprintf("%s.%s[%d]", obj.path, key, i), Message: "statement is null"}
+		}
+		if !isStatement(node) {
+			return nil, &UnmarshalError{Path: fmt.Sprintf("%s.%s[%d]", obj.path, key, i), Message: fmt.Sprintf("expected a statement, found %s", typeOf(value))}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// nodes decodes the array of expressions stored under key, none of which may be null
+// what names an element in the error for a null one
+func (obj jsonObject) nodes(key, what string) ([]ast.Node, error) {
+	values, err := obj.array(key)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]ast.Node, len(values))
+	for i, value := range values {
+		path := fmt.Sprintf("%s.%s[%d]", obj.path, key, i)
+		if nodes[i], err = decode(value, path); err != nil {
+			return nil, err
+		}
+		if nodes[i] == nil {
+			return nil, &UnmarshalError{Path: path, Message: what + " is null"}
+		}
+		if !isExpression(nodes[i]) {
+			return nil, &UnmarshalError{Path: path, Message: fmt.Sprintf("expected an expression, found %s", typeOf(value))}
+		}
+	}
+	return nodes, nil
+}
+
+// child decodes the expression stored under key, which may be null or absent
+func (obj jsonObject) child(key string) (ast.Node, error) {
+	node, err := decode(obj.fields[key], obj.path+"."+key)
+	if err != nil || node == nil || isExpression(node) {
+		return node, err
+	}
+	return nil, &UnmarshalError{Path: obj.path + "." + key, Message: fmt.Sprintf("expected an expression, found %s", typeOf(obj.fields[key]))}
+}
+
+// isStatement reports whether a node may appear in a statement list
+func isStatement(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.FunctionDeclaration, *ast.IfStatement, *ast.ReturnStatement, *ast.VariableDeclaration,
+		*ast.ExpressionStatement, *ast.Comment, *ast.ErrorNode:
+		return true
+	}
+	return false
+}
+
+// isExpression reports whether a node may appear where an expression is expected
+func isExpression(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Identifier, *ast.StringLiteral, *ast.NumericLiteral, *ast.BooleanLiteral, *ast.NullLiteral,
+		*ast.BinaryExpression, *ast.CallExpression, *ast.NewExpression, *ast.ArrayExpression,
+		*ast.ObjectExpression, *ast.MemberExpression, *ast.InvalidExpression:
+		return true
+	}
+	return false
+}
+
+// typeOf returns the type field of a decoded JSON node, for error messages
+func typeOf(value any) string {
+	typ, _ := value.(map[string]any)["type"].(string)
+	return typ
+}
+
+// identifier decodes the Identifier stored under key and returns its name and span
+func (obj jsonObject) identifier(key string) (string, ast.Span, error) {
+	id, err := asObject(obj.fields[key], obj.path+"."+key)
+	if err != nil {
+		return "", ast.Span{}, err
+	}
+	if typ, _ := id.fields["type"].(string); typ != "Identifier" {
+		return "", ast.Span{}, &UnmarshalError{Path: id.path, Message: fmt.Sprintf("expected Identifier, found %q", typ)}
+	}
+	name, err := id.str("name")
+	return name, id.span(), err
+}
+
+// str returns the string field key
+func (obj jsonObject) str(key string) (string, error) {
+	value, ok := obj.fields[key].(string)
+	if !ok {
+		return "", &UnmarshalError{Path: obj.path + "." + key, Message: "expected a string"}
+	}
+	return value, nil
+}
+
+// array returns the array field key, treating a missing field as an empty array
+func (obj jsonObject) array(key string) ([]any, error) {
+	value, ok := obj.fields[key]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	values, ok := value.([]any)
+	if !ok {
+		return nil, &UnmarshalError{Path: obj.path + "." + key, Message: "expected an array"}
+	}
+	return values, nil
+}
+
+// span reads the node position from "range", falling back to Acorn's "start" and "end"
+// Nodes without position information get an empty span
+func (obj jsonObject) span() ast.Span {
+	if values, ok := obj.fields["range"].([]any); ok && len(values) == 2 {
+		return ast.Span{Start: toInt(values[0]), End: toInt(values[1])}
+	}
+	return ast.Span{Start: toInt(obj.fields["start"]), End: toInt(obj.fields["end"])}
+}
+
+// restoreComments puts the comments listed on the Program back into the tree
+// Each comment goes into the innermost block containing it, before the first statement after it;
+// comments without a range are appended to the Program body in order
+func (obj jsonObject) restoreComments(program *ast.Program) error {
+	values, err := obj.array("comments")
+	if err != nil {
+		return err
+	}
+	for i, value := range values {
+		node, err := decode(value, fmt.Sprintf("%s.comments[%d]", obj.path, i))
+		if err != nil {
+			return err
+		}
+		comment, ok := node.(*ast.Comment)
+		if !ok {
+			return &UnmarshalError{Path: fmt.Sprintf("%s.comments[%d]", obj.path, i), Message: "expected a comment"}
+		}
+		if !comment.Span.IsValid() {
+			program.Body = append(program.Body, comment)
+			continue
+		}
+		program.Body = insertComment(program.Body, comment)
+	}
+	return nil
+}
+
+// insertComment inserts comment into list or into the block of a statement containing it
+func insertComment(list []ast.Node, comment *ast.Comment) []ast.Node {
+	for i, node := range list {
+		switch n := node.(type) {
+		case *ast.FunctionDeclaration:
+			if contains(n.BodySpan, comment.Span) {
+				n.Body = insertComment(n.Body, comment)
+				return list
+			}
+		case *ast.IfStatement:
+			if contains(n.ConsequentSpan, comment.Span) {
+				n.Consequent = insertComment(n.Consequent, comment)
+				return list
+			}
+		}
+		if node.Range().Start >= comment.End {
+			return append(list[:i], append([]ast.Node{comment}, list[i:]...)...)
+		}
+	}
+	return append(list, comment)
+}
+
+// contains reports whether inner lies within outer
+func contains(outer, inner ast.Span) bool {
+	return outer.IsValid() && outer.Start <= inner.Start && inner.End <= outer.End
+}
+
+// asObject checks that value is a JSON object
+func asObject(value any, path string) (jsonObject, error) {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return jsonObject{}, &UnmarshalError{Path: path, Message: "expected a node object"}
+	}
+	return jsonObject{fields: fields, path: path}, nil
+}
+
+// toInt converts a decoded JSON number to an int, returning 0 for anything else
+func toInt(value any) int {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0
+	}
+	n, err := number.Int64()
+	if err != nil {
+		return 0
+	}
+	return int(n)
+}
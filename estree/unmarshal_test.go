@@ -0,0 +1,118 @@
+package estree
+
+import (
+	"testing"
+
+	"goast/ast"
+)
+
+// statement wraps the JSON of one expression in a Program
+func statement(expression string) string {
+	return `{"type":"Program","body":[{"type":"ExpressionStatement","expression":` + expression + `}]}`
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	id := func(name string) string { return `{"type":"Identifier","name":"` + name + `"}` }
+	number := func(value, raw string) string {
+		return `{"type":"Literal","value":` + value + `,"raw":"` + raw + `"}`
+	}
+	tests := []struct {
+		name string
+		json string
+		err  string
+	}{
+		{
+			"unknown binary operator",
+			statement(`{"type":"BinaryExpression","operator":"**","left":` + id("a") + `,"right":` + id("b") + `}`),
+			`$.body[0].expression.operator: unsupported binary operator "**"`,
+		},
+		{
+			"assignment as a binary operator",
+			statement(`{"type":"BinaryExpression","operator":"=","left":` + id("a") + `,"right":` + id("b") + `}`),
+			`$.body[0].expression.operator: unsupported binary operator "="`,
+		},
+		{
+			"compound assignment",
+			statement(`{"type":"AssignmentExpression","operator":"+=","left":` + id("a") + `,"right":` + id("b") + `}`),
+			`$.body[0].expression.operator: unsupported assignment operator "+="`,
+		},
+		{
+			"literal assignment target",
+			statement(`{"type":"AssignmentExpression","operator":"=","left":` + number("0", "0") + `,"right":` + id("b") + `}`),
+			`$.body[0].expression.left: assignment target must be an identifier or a member expression`,
+		},
+		{
+			"call assignment target",
+			statement(`{"type":"AssignmentExpression","operator":"=","left":{"type":"CallExpression","callee":` + id("f") + `,"arguments":[]},"right":` + id("b") + `}`),
+			`$.body[0].expression.left: assignment target must be an identifier or a member expression`,
+		},
+		{
+			"raw disagrees with value",
+			statement(number("1", "2")),
+			`$.body[0].expression.raw: raw "2" does not match value 1`,
+		},
+		{
+			"expression in a statement list",
+			`{"type":"Program","body":[` + id("a") + `]}`,
+			`$.body[0]: expected a statement, found Identifier`,
+		},
+		{
+			"expression as a block",
+			`{"type":"Program","body":[{"type":"IfStatement","test":` + id("a") + `,"consequent":` + id("b") + `}]}`,
+			`$.body[0].consequent: expected a statement, found Identifier`,
+		},
+		{
+			"program in a function body",
+			`{"type":"Program","body":[{"type":"FunctionDeclaration","id":` + id("f") + `,"params":[],"body":{"type":"BlockStatement","body":[{"type":"Program","body":[]}]}}]}`,
+			`$.body[0].body.body[0]: expected a statement, found Program`,
+		},
+		{
+			"statement as an expression",
+			statement(`{"type":"ReturnStatement","argument":null}`),
+			`$.body[0].expression: expected an expression, found ReturnStatement`,
+		},
+		{
+			"statement as an operand",
+			statement(`{"type":"BinaryExpression","operator":"+","left":` + id("a") + `,"right":{"type":"ExpressionStatement","expression":` + id("b") + `}}`),
+			`$.body[0].expression.right: expected an expression, found ExpressionStatement`,
+		},
+		{
+			"declaration as an argument",
+			statement(`{"type":"CallExpression","callee":` + id("f") + `,"arguments":[{"type":"VariableDeclaration","kind":"let","declarations":[{"type":"VariableDeclarator","id":` + id("x") + `,"init":null}]}]}`),
+			`$.body[0].expression.arguments[0]: expected an expression, found VariableDeclaration`,
+		},
+		{
+			"comment as an initializer",
+			`{"type":"Program","body":[{"type":"VariableDeclaration","kind":"let","declarations":[{"type":"VariableDeclarator","id":` + id("x") + `,"init":{"type":"Line","value":" c"}}]}]}`,
+			`$.body[0].declarations[0].init: expected an expression, found Line`,
+		},
+	}
+	for _, tt := range tests {
+		_, err := Unmarshal([]byte(tt.json))
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s: error = %v, want %s", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestUnmarshalNumericLiteral(t *testing.T) {
+	tests := []struct {
+		json  string
+		value string
+	}{
+		{`{"type":"Literal","value":3,"raw":"3.0"}`, "3.0"},  // The source spelling is kept
+		{`{"type":"Literal","value":16,"raw":"0x10"}`, "16"}, // The lexer cannot read hexadecimal
+		{`{"type":"Literal","value":2.5}`, "2.5"},
+	}
+	for _, tt := range tests {
+		node, err := Unmarshal([]byte(statement(tt.json)))
+		if err != nil {
+			t.Errorf("%s: %v", tt.json, err)
+			continue
+		}
+		literal := node.(*ast.Program).Body[0].(*ast.ExpressionStatement).Expression.(*ast.NumericLiteral)
+		if literal.Value != tt.value {
+			t.Errorf("%s: value %q, want %q", tt.json, literal.Value, tt.value)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Array: construction, mutation, searching, iteration and reduction
+function isEven(n) { return n % 2 === 0; }
+function square(n) { return n * n; }
+function add(total, n) { return total + n; }
+function byLength(a, b) { return a.length - b.length; }
+function pair(n) { return [n, n * 10]; }
+const numbers = [5, 3, 8, 1];
+numbers.push(9, 2);
+// Arguments are evaluated before anything is printed, so the array shows without 2 and 5
+console.log(numbers, numbers.length, numbers.pop(), numbers.shift());
+console.log(numbers.slice(1, 3), numbers.indexOf(8), numbers.includes(4), numbers.at(0));
+console.log(numbers.filter(isEven), numbers.map(square), numbers.reduce(add, 0));
+console.log(numbers.find(isEven), numbers.findIndex(isEven), numbers.some(isEven), numbers.every(isEven));
+const removed = numbers.splice(1, 2, "a", "b", "c");
+console.log(removed, numbers);
+console.log([10, 9, 1, 2].sort(), ["ccc", "a", "bb"].sort(byLength), [1, 2, 3].reverse());
+console.log([1, [2, [3, [4]]]].flat(2), [1, 2].flatMap(pair), [1, 2].concat([3], 4));
+console.log(Array.isArray([]), Array.of(7, 8), Array.from("hi"), new Array(3).fill(0));
+console.log([1, null, "x"].join("-"), String([1, [2, 3]]), [3, 4].entries());
+
+// Expected output:
+// [ 3, 8, 1, 9 ] 6 2 5
+// [ 8, 1 ] 1 false 3
+// [ 8 ] [ 9, 64, 1, 81 ] 21
+// 8 1 true false
+// [ 8, 1 ] [ 3, 'a', 'b', 'c', 9 ]
+// [ 1, 10, 2, 9 ] [ 'a', 'bb', 'ccc' ] [ 3, 2, 1 ]
+// [ 1, 2, 3, [ 4 ] ] [ 1, 10, 2, 20 ] [ 1, 2, 3, 4 ]
+// true [ 7, 8 ] [ 'h', 'i' ] [ 0, 0, 0 ]
+// 1--x 1,2,3 [ [ 0, 3 ], [ 1, 4 ] ]
@@ -0,0 +1,27 @@
+// Map and Set: insertion order, SameValueZero keys, iteration
+function show(value, key) { console.log(key, "=>", value); }
+const ages = new Map([["ada", 36]]);
+ages.set("bob", 25).set("ada", 37);
+console.log(ages, ages.size, ages.get("ada"), ages.has("eve"), ages.get("eve"));
+ages.forEach(show);
+console.log(ages.keys(), ages.values(), ages.entries());
+const key = {};
+const byObject = new Map();
+byObject.set(key, "object key").set(NaN, "nan key");
+console.log(byObject.get(key), byObject.get({}), byObject.get(NaN));
+const seen = new Set([1, 2, 2, 3, 1]);
+seen.add(4).add(0);
+console.log(seen, seen.size, seen.has(2), seen.delete(2), seen.delete(2), seen.has(0 - 0));
+console.log(seen.values(), new Set("hello").size, Array.from(seen));
+seen.clear();
+console.log(seen);
+
+// Expected output:
+// Map(2) { 'ada' => 37, 'bob' => 25 } 2 37 false undefined
+// ada => 37
+// bob => 25
+// [ 'ada', 'bob' ] [ 37, 25 ] [ [ 'ada', 37 ], [ 'bob', 25 ] ]
+// object key undefined nan key
+// Set(4) { 1, 3, 4, 0 } 5 true true false true
+// [ 1, 3, 4, 0 ] 4 [ 1, 3, 4, 0 ]
+// Set(0) {}
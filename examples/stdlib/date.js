@@ -0,0 +1,22 @@
+// Date: run with -now 2024-05-01T12:00:00Z (and -tz Europe/Paris for local times)
+const now = new Date();
+console.log(now.toISOString(), Date.now(), Date());
+const d = new Date(2024, 0, 31, 9, 30);
+console.log(d.getFullYear(), d.getMonth(), d.getDate(), d.getDay(), d.getHours(), d.getMinutes());
+d.setMonth(1);
+console.log(d.toDateString(), d.getDate());
+d.setDate(d.getDate() + 30);
+console.log(d.toISOString());
+console.log(new Date("2024-03-10T08:15:00Z").getUTCHours(), new Date("2024-03-10").getTime(), Date.UTC(2000, 0, 1));
+console.log(new Date(0).toUTCString(), new Date(0).toJSON(), new Date("not a date").getTime());
+const later = new Date(now.getTime() + 86400000);
+console.log(later - now, later > now, String(new Date(86400000)));
+
+// Expected output with -now 2024-05-01T12:00:00Z:
+// 2024-05-01T12:00:00.000Z 1714564800000 Wed May 01 2024 12:00:00 GMT+0000 (UTC)
+// 2024 0 31 3 9 30
+// Sat Mar 02 2024 2
+// 2024-04-01T09:30:00.000Z
+// 8 1710028800000 946684800000
+// Thu, 01 Jan 1970 00:00:00 GMT 1970-01-01T00:00:00.000Z NaN
+// 86400000 true Fri Jan 02 1970 00:00:00 GMT+0000 (UTC)
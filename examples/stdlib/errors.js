@@ -0,0 +1,27 @@
+// Errors: the built-in types, their properties and catching them with promises
+function fail(message) {
+  return new RangeError(message);
+}
+function report(reason) {
+  console.log("caught", String(reason));
+}
+const plain = new Error("something failed");
+const typed = TypeError("wrong type");
+const range = fail("too far");
+console.log(plain.name, plain.message, String(plain), plain.toString());
+console.log(typed.name, String(typed), range.name, Object.getPrototypeOf(range) === RangeError.prototype);
+console.log(String(new SyntaxError()), new Error().message === "");
+console.log(plain, [typed]);
+Promise.reject(new ReferenceError("x is not defined")).catch(report);
+Promise.resolve(null).then(readName).catch(report);
+function readName(value) {
+  return value.name;
+}
+
+// Expected output:
+// Error something failed Error: something failed Error: something failed
+// TypeError TypeError: wrong type RangeError true
+// SyntaxError true
+// Error: something failed [ TypeError: wrong type ]
+// caught ReferenceError: x is not defined
+// caught TypeError: Cannot read properties of null (reading 'name')
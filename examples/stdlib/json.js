@@ -0,0 +1,49 @@
+// JSON: parse with a reviver, stringify with a replacer and indentation
+function double(key, value) {
+  if (key === "") {
+    return value;
+  }
+  return value * 2;
+}
+function hideSecret(key, value) {
+  if (key === "secret") {
+    return undefined;
+  }
+  return value;
+}
+const data = JSON.parse("{\"name\":\"goast\",\"tags\":[\"go\",\"js\"],\"stars\":5,\"meta\":null}");
+console.log(data, data.tags[1]);
+console.log(JSON.parse("[1, 2, 3]", double));
+console.log(JSON.stringify({ a: 1, b: "two", c: [true, null], d: undefined, e: NaN }));
+console.log(JSON.stringify({ user: "ada", secret: "x" }, hideSecret), JSON.stringify({ a: 1, b: 2, c: 3 }, ["a", "c"]));
+console.log(JSON.stringify({ list: [1, 2], nested: { ok: true } }, null, 2));
+console.log(JSON.stringify("line\nbreak \"quoted\""), JSON.stringify(new Date(0)), JSON.stringify(greet));
+function greet() {}
+function report(reason) {
+  console.log("caught", String(reason));
+}
+const loop = {};
+loop.self = loop;
+console.log(JSON.stringify([undefined, greet]));
+// Errors are caught through promises, after the synchronous code
+Promise.resolve("{bad json}").then(JSON.parse).catch(report);
+Promise.resolve(loop).then(JSON.stringify).catch(report);
+
+// Expected output:
+// { name: 'goast', tags: [ 'go', 'js' ], stars: 5, meta: null } js
+// [ 2, 4, 6 ]
+// {"a":1,"b":"two","c":[true,null],"e":null}
+// {"user":"ada"} {"a":1,"c":3}
+// {
+//   "list": [
+//     1,
+//     2
+//   ],
+//   "nested": {
+//     "ok": true
+//   }
+// }
+// "line\nbreak \"quoted\"" "1970-01-01T00:00:00.000Z" undefined
+// [null,null]
+// caught SyntaxError: Unexpected token 'b' in JSON at position 1
+// caught TypeError: Converting circular structure to JSON
@@ -0,0 +1,17 @@
+// Number and Math: conversions, predicates, formatting and the global functions
+console.log(Number("3.5"), Number(""), Number("12px"), Number(true), Number(null));
+console.log(Number.isInteger(5), Number.isInteger(5.5), Number.isSafeInteger(Number.MAX_SAFE_INTEGER + 1), Number.isNaN("x"), isNaN("x"));
+console.log((3.14159).toFixed(2), (0.5).toFixed(0), (255).toString(16), (255).toString(2), (1234.5678).toPrecision(6));
+console.log(parseInt("42px"), parseInt("ff", 16), parseFloat("3.14abc"), parseInt("abc"));
+console.log(Number.MAX_SAFE_INTEGER, Number.EPSILON > 0, isFinite("12"), Number.isFinite("12"));
+console.log(Math.max(1, 7, 3), Math.min(), Math.round(2.5), Math.floor(0 - 1.5), Math.hypot(3, 4), Math.PI);
+console.log(Boolean(""), Boolean("0"), (true).toString());
+
+// Expected output:
+// 3.5 0 NaN 1 0
+// true false false false true
+// 3.14 1 ff 11111111 1234.57
+// 42 255 3.14 NaN
+// 9007199254740991 true true false
+// 7 Infinity 3 -2 5 3.141592653589793
+// false true true
@@ -0,0 +1,30 @@
+// Object: keys, entries, assign, freeze, create and the prototype methods
+const point = { x: 1, y: 2 };
+console.log(Object.keys(point), Object.values(point));
+console.log(Object.entries(point));
+console.log(Object.fromEntries([["a", 1], ["b", 2]]));
+const merged = Object.assign({}, point, { z: 3 });
+console.log(merged);
+const frozen = Object.freeze({ answer: 42 });
+frozen.answer = 0;
+console.log(frozen.answer, Object.isFrozen(frozen), Object.isFrozen(point));
+const child = Object.create(point);
+console.log(child.x, child.hasOwnProperty("x"), Object.hasOwn(point, "x"));
+console.log(Object.getPrototypeOf(child) === point, Object.is(NaN, NaN));
+console.log(Object.prototype.toString.call([]), String(point));
+function greet(greeting, name) { return greeting + ", " + name; }
+const hello = greet.bind(null, "Hello");
+console.log(hello("Ada"), greet.call(null, "Hi", "Bob"), greet.apply(null, ["Hey", "Eve"]));
+console.log(hello.name, hello.length, greet.length);
+
+// Expected output:
+// [ 'x', 'y' ] [ 1, 2 ]
+// [ [ 'x', 1 ], [ 'y', 2 ] ]
+// { a: 1, b: 2 }
+// { x: 1, y: 2, z: 3 }
+// 42 true false
+// 1 false true
+// true true
+// [object Array] [object Object]
+// Hello, Ada Hi, Bob Hey, Eve
+// bound greet 1 2
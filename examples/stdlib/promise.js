@@ -0,0 +1,43 @@
+// Promises: handlers run as microtasks once the program has returned, in the order they were queued
+function step(name) {
+  function log(value) {
+    console.log(name, value);
+    return value + 1;
+  }
+  return log;
+}
+function delayed(resolve) {
+  resolve("from the executor");
+}
+function failing(resolve, reject) {
+  reject(new Error("rejected"));
+}
+function settled(results) {
+  console.log(results);
+}
+function rejectedAll(error) {
+  console.log(String(error), error.errors);
+}
+function done() {
+  console.log("finally");
+}
+Promise.resolve(1).then(step("first")).then(step("second"));
+new Promise(delayed).then(step("executor"));
+new Promise(failing).catch(step("catch")).finally(done);
+Promise.all([1, Promise.resolve(2), 3]).then(step("all"));
+Promise.allSettled([Promise.reject(0), 5]).then(settled);
+Promise.race([Promise.resolve("fast"), "value"]).then(step("race"));
+Promise.any([Promise.reject(1), Promise.reject(2)]).catch(rejectedAll);
+console.log("synchronous code runs first", Promise.resolve(7), new Promise(delayed));
+
+// Expected output:
+// synchronous code runs first Promise { 7 } Promise { 'from the executor' }
+// first 1
+// executor from the executor
+// catch Error: rejected
+// second 2
+// finally
+// all [ 1, 2, 3 ]
+// [ { status: 'rejected', reason: 0 }, { status: 'fulfilled', value: 5 } ]
+// race fast
+// AggregateError: All promises were rejected [ 1, 2 ]
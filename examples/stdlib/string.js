@@ -0,0 +1,21 @@
+// String: searching, slicing, case, padding and replacing, in UTF-16 code units
+function shout(match) { return match.toUpperCase() + "!"; }
+const text = "Hello, World";
+console.log(text.length, text.charAt(4), text.charCodeAt(0), text.at(0 - 1));
+console.log(text.indexOf("o"), text.lastIndexOf("o"), text.includes("World"), text.startsWith("Hell"), text.endsWith("d"));
+console.log(text.slice(7), text.slice(0 - 5, 0 - 1), text.substring(5, 0), text.toUpperCase(), text.toLowerCase());
+console.log("  padded  ".trim() + "|", "7".padStart(3, "0"), "ab".padEnd(5, "xy"), "na".repeat(3));
+console.log("a,b,,c".split(","), "abc".split(""), "one two".split(" ", 1));
+console.log("a-b-c".replace("-", "+"), "a-b-c".replaceAll("-", ""), "cat".replace("a", "$&$&"), "dog".replace("o", shout));
+console.log("😀".length, "😀".codePointAt(0), String.fromCharCode(72, 105), String.fromCodePoint(128512));
+console.log("b".localeCompare("a"), String(42), "x".concat(1, true));
+
+// Expected output:
+// 12 o 72 d
+// 4 8 true true true
+// World Worl Hello HELLO, WORLD hello, world
+// padded| 007 abxyx nanana
+// [ 'a', 'b', '', 'c' ] [ 'a', 'b', 'c' ] [ 'one' ]
+// a+b-c abc caat dO!g
+// 2 128512 Hi 😀
+// 1 42 x1true
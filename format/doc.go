@@ -0,0 +1,221 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Doc is a document in the sense of Wadler's "A prettier printer"
+// Documents describe the output together with the places where it may break across lines;
+// the printer then picks, group by group, the flat layout whenever it fits the print width
+type Doc interface {
+	hasHardLine() bool
+}
+
+// text is literal output, it never contains a newline
+type text string
+
+// line is a possible line break
+// In flat mode a line prints as a space (soft lines print nothing), hard lines always break
+type line struct {
+	soft bool
+	hard bool
+}
+
+// concat prints its parts one after the other
+type concat []Doc
+
+// group prints its content flat if it fits on the rest of the line, broken otherwise
+type group struct {
+	doc  Doc
+	hard bool // Content contains a hard line, so the group can never be flat
+}
+
+// indent increases the indentation of the lines inside it by one level
+type indent struct {
+	doc Doc
+}
+
+// ifBreak prints broken or flat depending on the mode of the enclosing group
+type ifBreak struct {
+	broken Doc
+	flat   Doc
+}
+
+// lineSuffix is printed as is but ignored when measuring, used for trailing comments
+// so they never force the code before them to break
+type lineSuffix string
+
+func (text) hasHardLine() bool       { return false }
+func (l line) hasHardLine() bool     { return l.hard }
+func (g group) hasHardLine() bool    { return g.hard }
+func (i indent) hasHardLine() bool   { return i.doc.hasHardLine() }
+func (lineSuffix) hasHardLine() bool { return false }
+func (b ifBreak) hasHardLine() bool {
+	return b.broken.hasHardLine() || b.flat.hasHardLine()
+}
+func (c concat) hasHardLine() bool {
+	for _, doc := range c {
+		if doc.hasHardLine() {
+			return true
+		}
+	}
+	return false
+}
+
+// Document constructors, kept short since the formatter composes many of them
+var (
+	softLine = line{soft: true}
+	hardLine = line{hard: true}
+	anyLine  = line{}
+)
+
+// cat concatenates documents
+func cat(docs ...Doc) Doc {
+	return concat(docs)
+}
+
+// grp groups a document so that it breaks as a whole
+func grp(docs ...Doc) Doc {
+	doc := concat(docs)
+	return group{doc: doc, hard: doc.hasHardLine()}
+}
+
+// ind indents the lines of a document
+func ind(docs ...Doc) Doc {
+	return indent{doc: concat(docs)}
+}
+
+// join puts sep between every pair of documents
+func join(sep Doc, docs []Doc) Doc {
+	out := make(concat, 0, 2*len(docs))
+	for i, doc := range docs {
+		if i > 0 {
+			out = append(out, sep)
+		}
+		out = append(out, doc)
+	}
+	return out
+}
+
+// mode tells whether a group is printed flat or broken
+type mode int
+
+const (
+	modeBreak mode = iota
+	modeFlat
+)
+
+// command is a document waiting to be printed with its indentation and mode
+type command struct {
+	level int
+	mode  mode
+	doc   Doc
+}
+
+// render lays out doc within width columns using indentUnit for each indentation level
+func render(doc Doc, width int, indentUnit string) string {
+	var out []byte
+	column := 0
+	stack := []command{{level: 0, mode: modeBreak, doc: doc}}
+
+	for len(stack) > 0 {
+		cmd := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch d := cmd.doc.(type) {
+		case text:
+			out = append(out, d...)
+			column += utf8.RuneCountInString(string(d))
+		case lineSuffix:
+			out = append(out, d...)
+		case concat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, command{cmd.level, cmd.mode, d[i]})
+			}
+		case indent:
+			stack = append(stack, command{cmd.level + 1, cmd.mode, d.doc})
+		case group:
+			flat := command{cmd.level, modeFlat, d.doc}
+			if !d.hard && (cmd.mode == modeFlat || fits(flat, stack, width-column)) {
+				stack = append(stack, flat)
+			} else {
+				stack = append(stack, command{cmd.level, modeBreak, d.doc})
+			}
+		case ifBreak:
+			if cmd.mode == modeBreak {
+				stack = append(stack, command{cmd.level, cmd.mode, d.broken})
+			} else {
+				stack = append(stack, command{cmd.level, cmd.mode, d.flat})
+			}
+		case line:
+			if cmd.mode == modeFlat && !d.hard {
+				if !d.soft {
+					out = append(out, ' ')
+					column++
+				}
+				continue
+			}
+			// Never leave trailing whitespace before a newline
+			out = bytes.TrimRight(out, " \t")
+			out = append(out, '\n')
+			prefix := strings.Repeat(indentUnit, cmd.level)
+			out = append(out, prefix...)
+			column = utf8.RuneCountInString(prefix)
+		}
+	}
+	return string(out)
+}
+
+// fits reports whether next, printed flat, fits in the remaining width
+// The documents following it on the stack are measured too, up to their first line break,
+// since they end up on the same line
+func fits(next command, rest []command, width int) bool {
+	stack := []command{next}
+	restIndex := len(rest)
+
+	for width >= 0 {
+		if len(stack) == 0 {
+			if restIndex == 0 {
+				return true
+			}
+			restIndex--
+			stack = append(stack, rest[restIndex])
+			continue
+		}
+		cmd := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch d := cmd.doc.(type) {
+		case text:
+			width -= utf8.RuneCountInString(string(d))
+		case concat:
+			for i := len(d) - 1; i >= 0; i-- {
+				stack = append(stack, command{cmd.level, cmd.mode, d[i]})
+			}
+		case indent:
+			stack = append(stack, command{cmd.level, cmd.mode, d.doc})
+		case group:
+			groupMode := cmd.mode
+			if d.hard {
+				groupMode = modeBreak
+			}
+			stack = append(stack, command{cmd.level, groupMode, d.doc})
+		case ifBreak:
+			if cmd.mode == modeBreak {
+				stack = append(stack, command{cmd.level, cmd.mode, d.broken})
+			} else {
+				stack = append(stack, command{cmd.level, cmd.mode, d.flat})
+			}
+		case line:
+			if cmd.mode == modeBreak || d.hard {
+				return true // The rest goes on the next line
+			}
+			if !d.soft {
+				width--
+			}
+		}
+	}
+	return false
+}
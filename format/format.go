@@ -0,0 +1,321 @@
+// Package format implements an opinionated JavaScript formatter in the spirit of Prettier and gofmt
+// Layout decisions are made by a Wadler-style document printer (see doc.go), so long
+// expressions and parameter lists break across lines only when they exceed the print width
+package format
+
+import (
+	"strings"
+
+	"goast/ast"
+	"goast/codegen"
+	"goast/parser"
+)
+
+// DefaultPrintWidth is the line length the formatter aims for when none is configured
+const DefaultPrintWidth = 80
+
+// Options configures the formatter
+// A nil *Options is valid and selects the defaults
+type Options struct {
+	PrintWidth     int    // Maximum line length to aim for, DefaultPrintWidth when zero
+	Indent         string // Indentation for each nesting level, two spaces when empty
+	Quote          byte   // Preferred string quote, '"' (the default) or '\''
+	OmitSemicolons bool   // Rely on automatic semicolon insertion instead of printing semicolons
+	TrailingCommas bool   // Add a trailing comma to parameter and argument lists broken across lines
+}
+
+// Source formats JavaScript source code
+// Comments are kept, and a single blank line is kept wherever the source had one or more
+// between two statements; the result is stable, formatting it again changes nothing
+// Source refuses to format code with syntax errors and returns the parser's ErrorList instead
+func Source(filename, src string, opts *Options) (string, error) {
+	program, err := parser.ParseFile(filename, src, nil)
+	if err != nil {
+		return "", err
+	}
+	return Node(program, src, opts), nil
+}
+
+// Node formats an already parsed program
+// src is the source it was parsed from, used to find blank lines and trailing comments;
+// it may be empty for ASTs built by hand
+func Node(program *ast.Program, src string, opts *Options) string {
+	f := newFormatter(src, opts)
+	doc := f.statements(program.Body)
+	out := render(doc, f.width, f.indent)
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+// formatter turns AST nodes into documents
+type formatter struct {
+	src            string
+	width          int
+	indent         string
+	quote          byte
+	semicolon      string
+	trailingCommas bool
+}
+
+// newFormatter applies the defaults to opts
+func newFormatter(src string, opts *Options) *formatter {
+	f := &formatter{src: src, width: DefaultPrintWidth, indent: "  ", quote: '"', semicolon: ";"}
+	if opts != nil {
+		if opts.PrintWidth > 0 {
+			f.width = opts.PrintWidth
+		}
+		if opts.Indent != "" {
+			f.indent = opts.Indent
+		}
+		if opts.Quote == '\'' {
+			f.quote = '\''
+		}
+		if opts.OmitSemicolons {
+			f.semicolon = ""
+		}
+		f.trailingCommas = opts.TrailingCommas
+	}
+	return f
+}
+
+// statements formats a statement list, one statement per line
+// Comments on the same line as the previous statement stay there as trailing comments
+func (f *formatter) statements(list []ast.Node) Doc {
+	var out concat
+	var previous ast.Node
+	for _, stmt := range list {
+		if comment, ok := stmt.(*ast.Comment); ok && previous != nil && f.sameLine(previous, comment) {
+			out = append(out, lineSuffix(" "+comment.Text))
+			previous = comment
+			continue
+		}
+		if previous != nil {
+			out = append(out, hardLine)
+			if f.blankLineBetween(previous, stmt) {
+				out = append(out, hardLine)
+			}
+		}
+		out = append(out, f.statement(stmt))
+		previous = stmt
+	}
+	return out
+}
+
+// sameLine reports whether b starts on the line on which a ends
+func (f *formatter) sameLine(a, b ast.Node) bool {
+	between, ok := f.between(a, b)
+	return ok && !strings.Contains(between, "\n")
+}
+
+// blankLineBetween reports whether the source has an empty line between a and b
+func (f *formatter) blankLineBetween(a, b ast.Node) bool {
+	between, ok := f.between(a, b)
+	return ok && strings.Count(between, "\n") >= 2
+}
+
+// between returns the source text separating two nodes, if their positions are known
+func (f *formatter) between(a, b ast.Node) (string, bool) {
+	end, start := a.Range().End, b.Range().Start
+	if f.src == "" || !a.Range().IsValid() || !b.Range().IsValid() || end > start || start > len(f.src) {
+		return "", false
+	}
+	return f.src[end:start], true
+}
+
+// statement formats a single statement
+func (f *formatter) statement(node ast.Node) Doc {
+	switch n := node.(type) {
+	case *ast.FunctionDeclaration:
+		return cat(text("function "+n.Name), f.parameters(n.Params), text(" "), f.block(n.Body))
+	case *ast.IfStatement:
+		return cat(
+			grp(text("if ("), ind(softLine, f.expression(n.Test, 0)), softLine, text(") ")),
+			f.block(n.Consequent),
+		)
+	case *ast.ReturnStatement:
+		if n.Argument == nil {
+			return text("return" + f.semicolon)
+		}
+		if hugs(n.Argument) {
+			return cat(text("return "), f.expression(n.Argument, 0), text(f.semicolon))
+		}
+		return cat(text("return "), grp(ind(f.expression(n.Argument, 0))), text(f.semicolon))
+	case *ast.VariableDeclaration:
+		if n.Value == nil {
+			return text(n.Kind + " " + n.Name + f.semicolon)
+		}
+		value := f.expression(n.Value, ast.Precedence("="))
+		if hugs(n.Value) {
+			return cat(text(n.Kind+" "+n.Name+" = "), value, text(f.semicolon))
+		}
+		return cat(text(n.Kind+" "+n.Name+" ="), grp(ind(anyLine, value)), text(f.semicolon))
+	case *ast.ExpressionStatement:
+		expr := f.expression(n.Expression, 0)
+		code := codegen.Generate(n.Expression, nil)
+		// A statement starting with a brace would be read as a block
+		if strings.HasPrefix(code, "{") {
+			expr, code = cat(text("("), expr, text(")")), "("+code
+		}
+		if !hugs(n.Expression) {
+			expr = grp(ind(expr))
+		}
+		doc := cat(expr, text(f.semicolon))
+		// Without semicolons a statement starting with ( or [ would continue the previous line
+		if f.semicolon == "" && (strings.HasPrefix(code, "(") || strings.HasPrefix(code, "[")) {
+			return cat(text(";"), doc)
+		}
+		return doc
+	case *ast.Comment:
+		return text(n.Text)
+	default:
+		// Code with syntax errors is rejected by Source, fall back on the code generator
+		return text(codegen.Generate(node, nil))
+	}
+}
+
+// parameters formats a parameter list, one parameter per line if it does not fit
+func (f *formatter) parameters(params []ast.Parameter) Doc {
+	docs := make([]Doc, len(params))
+	for i, param := range params {
+		if param.DefaultValue == nil {
+			docs[i] = text(param.Name)
+			continue
+		}
+		docs[i] = cat(text(param.Name+" = "), f.expression(param.DefaultValue, ast.Precedence("=")))
+	}
+	return f.list(docs)
+}
+
+// arguments formats the argument list of a call
+// A lone array or object argument hugs the parentheses and breaks inside its own brackets
+func (f *formatter) arguments(nodes []ast.Node) Doc {
+	if len(nodes) == 1 {
+		switch nodes[0].(type) {
+		case *ast.ArrayExpression, *ast.ObjectExpression:
+			return cat(text("("), f.expression(nodes[0], ast.Precedence("=")), text(")"))
+		}
+	}
+	args := make([]Doc, len(nodes))
+	for i, arg := range nodes {
+		args[i] = f.expression(arg, ast.Precedence("="))
+	}
+	return f.list(args)
+}
+
+// list formats a parenthesized, comma-separated list, one element per line if it does not fit
+func (f *formatter) list(docs []Doc) Doc {
+	return f.delimited("(", ")", softLine, docs)
+}
+
+// delimited formats a comma-separated list between open and close, one element per line if
+// it does not fit; inside separates the delimiters from the elements when they fit on a line
+func (f *formatter) delimited(open, close string, inside Doc, docs []Doc) Doc {
+	if len(docs) == 0 {
+		return text(open + close)
+	}
+	var trailing Doc = text("")
+	if f.trailingCommas {
+		trailing = ifBreak{broken: text(","), flat: text("")}
+	}
+	return grp(
+		text(open),
+		ind(inside, join(cat(text(","), anyLine), docs), trailing),
+		inside,
+		text(close),
+	)
+}
+
+// block formats a braced statement list
+func (f *formatter) block(body []ast.Node) Doc {
+	if len(body) == 0 {
+		return text("{}")
+	}
+	return cat(text("{"), ind(hardLine, f.statements(body)), hardLine, text("}"))
+}
+
+// expression formats an expression, parenthesized if it binds looser than minPrecedence
+// Operands of a binary expression may move to the next line, after the operator
+func (f *formatter) expression(node ast.Node, minPrecedence int) Doc {
+	switch n := node.(type) {
+	case *ast.BinaryExpression:
+		precedence := ast.Precedence(n.Operator)
+		leftMin, rightMin := precedence, precedence+1
+		if ast.IsRightAssociative(n.Operator) {
+			leftMin, rightMin = precedence+1, precedence
+		}
+		var separator Doc = anyLine
+		if n.Operator == "=" && hugs(n.Right) {
+			separator = text(" ")
+		}
+		doc := cat(
+			f.operand(n.Left, leftMin, precedence),
+			text(" "+n.Operator),
+			separator,
+			f.operand(n.Right, rightMin, precedence),
+		)
+		if precedence < minPrecedence {
+			return grp(text("("), ind(softLine, doc), softLine, text(")"))
+		}
+		return doc
+	case *ast.CallExpression:
+		return cat(f.expression(n.Callee, ast.CallPrecedence), f.arguments(n.Arguments))
+	case *ast.MemberExpression:
+		if n.Index != nil {
+			return cat(f.expression(n.Object, ast.CallPrecedence), text("["), f.expression(n.Index, 0), text("]"))
+		}
+		if _, ok := n.Object.(*ast.NumericLiteral); ok {
+			return text(codegen.Generate(node, nil))
+		}
+		return cat(f.expression(n.Object, ast.CallPrecedence), text("."+n.Property))
+	case *ast.NewExpression:
+		// The code generator knows when the callee needs parentheses
+		callee := codegen.Generate(&ast.NewExpression{Callee: n.Callee}, nil)
+		return cat(text(strings.TrimSuffix(callee, "()")), f.arguments(n.Arguments))
+	case *ast.ArrayExpression:
+		elements := make([]Doc, len(n.Elements))
+		for i, element := range n.Elements {
+			elements[i] = f.expression(element, ast.Precedence("="))
+		}
+		return f.delimited("[", "]", softLine, elements)
+	case *ast.ObjectExpression:
+		properties := make([]Doc, len(n.Properties))
+		for i, prop := range n.Properties {
+			if value, ok := prop.Value.(*ast.Identifier); ok && value.Name == prop.Key && value.Span == prop.KeySpan {
+				properties[i] = text(prop.Key) // Shorthand
+				continue
+			}
+			properties[i] = cat(text(codegen.PropertyKey(prop.Key, f.quote)+": "), f.expression(prop.Value, ast.Precedence("=")))
+		}
+		return f.delimited("{", "}", anyLine, properties)
+	case *ast.StringLiteral:
+		return text(codegen.Quote(n.Value, f.quote))
+	default:
+		return text(codegen.Generate(node, nil))
+	}
+}
+
+// hugs reports whether an expression is printed right after the = or return before it
+// Calls, arrays and objects break inside their own brackets, so indenting them on a line
+// of their own would only push their elements one level further in, as Prettier avoids
+func hugs(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.CallExpression, *ast.NewExpression, *ast.ArrayExpression, *ast.ObjectExpression:
+		return true
+	case *ast.BinaryExpression:
+		return n.Operator == "=" && hugs(n.Right)
+	}
+	return false
+}
+
+// operand formats an operand of a binary expression with the given precedence
+// Chains of the same precedence break together, while a nested operation of another
+// precedence is grouped so that it stays on one line when possible
+func (f *formatter) operand(node ast.Node, minPrecedence, precedence int) Doc {
+	doc := f.expression(node, minPrecedence)
+	if b, ok := node.(*ast.BinaryExpression); ok && ast.Precedence(b.Operator) != precedence {
+		return grp(doc)
+	}
+	return doc
+}
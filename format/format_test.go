@@ -0,0 +1,48 @@
+package format
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// TestGolden formats each testdata/*.js file and compares the result with its .golden file
+// Run go test ./format -update to rewrite the golden files after an intended change
+func TestGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Source(file, string(src), nil)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		golden := strings.TrimSuffix(file, ".js") + ".golden"
+		if *update {
+			if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != string(want) {
+			t.Errorf("%s: formatted as\n%s\nwant\n%s", file, got, want)
+		}
+		if again, err := Source(file, got, nil); err != nil || again != got {
+			t.Errorf("%s: formatting the output again gives\n%s", file, again)
+		}
+	}
+}
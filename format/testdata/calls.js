@@ -0,0 +1,14 @@
+// Calls that fit stay on one line
+console.log("short", 1, 2);
+console.log("a fairly long string argument", "another fairly long string argument", 42);
+const result = compute("a fairly long string argument", "another long string", 1234);
+let map = new Map([["first key", "first value"], ["second key", "second value"], ["x", 1]]);
+function f() {
+  return compute("a fairly long string argument", "another fairly long string", 12);
+}
+console.log(outer(inner("a fairly long string argument", "another fairly long string argument")));
+total = compute("a fairly long string argument", "another fairly long string argument");
+const numbers = [1000000, 2000000, 3000000, 4000000, 5000000, 6000000, 7000000, 8000000];
+const person = { name: "Alice Wonderland", age: 30, city: "Somewhere over the rainbow" };
+const sum = firstValueWithLongName + secondValueWithLongName + thirdValueWithLongName;
+console.log({ message: "a fairly long message for the object", code: 404, retry: false });
@@ -0,0 +1,509 @@
+package interp
+
+import (
+	"math"
+	"slices"
+
+	"goast/constant"
+)
+
+// defineArray defines Array with its static functions and the methods of Array.prototype
+// Methods that take a callback call it with the element, its index and the array
+func (rt *Runtime) defineArray() {
+	create := func(this Value, args []Value) (Value, error) {
+		if len(args) == 1 {
+			if n, ok := args[0].(Number); ok {
+				if n < 0 || float64(n) != math.Trunc(float64(n)) || n > maxArrayLength {
+					return nil, rangeError("Invalid array length")
+				}
+				elements := make([]Value, int(n))
+				for i := range elements {
+					elements[i] = Undefined{}
+				}
+				return rt.array(elements)
+			}
+		}
+		return rt.array(append([]Value(nil), args...))
+	}
+	ctor := rt.constructor("Array", 1, create, func(args []Value) (Value, error) {
+		return create(Undefined{}, args)
+	})
+	rt.method(&ctor.Object, "isArray", 1, func(this Value, args []Value) (Value, error) {
+		o, ok := arg(args, 0).(*Object)
+		return Boolean(ok && o.Class == "Array"), nil
+	})
+	rt.method(&ctor.Object, "of", 0, func(this Value, args []Value) (Value, error) {
+		return rt.array(append([]Value(nil), args...))
+	})
+	rt.method(&ctor.Object, "from", 1, func(this Value, args []Value) (Value, error) {
+		values, err := rt.arrayLike(arg(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		if mapFn := arg(args, 1); !isNullish(mapFn) {
+			f, err := callable(mapFn)
+			if err != nil {
+				return nil, err
+			}
+			for i, v := range values {
+				if values[i], err = f.Call(Undefined{}, v, Number(i)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return rt.array(values)
+	})
+
+	proto := rt.protos["Array"]
+	method := func(name string, length int, fn func(a *Object, args []Value) (Value, error)) {
+		rt.method(proto, name, length, func(this Value, args []Value) (Value, error) {
+			a, ok := this.(*Object)
+			if !ok || a.Class != "Array" {
+				return nil, typeError("Array.prototype.%s called on %s", name, describeValue(this))
+			}
+			return fn(a, args)
+		})
+	}
+
+	// Mutators
+	method("push", 1, func(a *Object, args []Value) (Value, error) {
+		if err := rt.grow(a, len(args)); err != nil {
+			return nil, err
+		}
+		a.elements = append(a.elements, args...)
+		return Number(len(a.elements)), nil
+	})
+	method("pop", 0, func(a *Object, args []Value) (Value, error) {
+		if len(a.elements) == 0 || a.frozen {
+			return Undefined{}, nil
+		}
+		v := a.elements[len(a.elements)-1]
+		a.elements = a.elements[:len(a.elements)-1]
+		return v, nil
+	})
+	method("shift", 0, func(a *Object, args []Value) (Value, error) {
+		if len(a.elements) == 0 || a.frozen {
+			return Undefined{}, nil
+		}
+		v := a.elements[0]
+		a.elements = slices.Delete(a.elements, 0, 1)
+		return v, nil
+	})
+	method("unshift", 1, func(a *Object, args []Value) (Value, error) {
+		if err := rt.grow(a, len(args)); err != nil {
+			return nil, err
+		}
+		a.elements = slices.Insert(a.elements, 0, args...)
+		return Number(len(a.elements)), nil
+	})
+	method("splice", 2, func(a *Object, args []Value) (Value, error) {
+		start := relativeIndex(arg(args, 0), len(a.elements), 0)
+		count := len(a.elements) - start
+		if len(args) == 0 {
+			count = 0
+		} else if len(args) > 1 {
+			count = int(math.Max(0, math.Min(toInteger(args[1]), float64(count))))
+		}
+		items := args[min(2, len(args)):]
+		if err := rt.grow(a, len(items)-count); err != nil {
+			return nil, err
+		}
+		removed, err := rt.array(slices.Clone(a.elements[start : start+count]))
+		if err != nil {
+			return nil, err
+		}
+		a.elements = slices.Insert(slices.Delete(a.elements, start, start+count), start, items...)
+		return removed, nil
+	})
+	method("reverse", 0, func(a *Object, args []Value) (Value, error) {
+		if !a.frozen {
+			slices.Reverse(a.elements)
+		}
+		return a, nil
+	})
+	method("fill", 1, func(a *Object, args []Value) (Value, error) {
+		start := relativeIndex(arg(args, 1), len(a.elements), 0)
+		end := relativeIndex(arg(args, 2), len(a.elements), len(a.elements))
+		for i := start; i < end && !a.frozen; i++ {
+			a.elements[i] = arg(args, 0)
+		}
+		return a, nil
+	})
+	method("sort", 1, func(a *Object, args []Value) (Value, error) {
+		compare := arg(args, 0)
+		if _, ok := compare.(Undefined); !ok {
+			if _, err := callable(compare); err != nil {
+				return nil, typeError("The comparison function must be either a function or undefined")
+			}
+		}
+		sorted, err := sortValues(slices.Clone(a.elements), compare)
+		if err != nil {
+			return nil, err
+		}
+		if !a.frozen {
+			a.elements = sorted
+		}
+		return a, nil
+	})
+
+	// Accessors
+	method("at", 1, func(a *Object, args []Value) (Value, error) {
+		i := toInteger(arg(args, 0))
+		if i < 0 {
+			i += float64(len(a.elements))
+		}
+		if i < 0 || i >= float64(len(a.elements)) {
+			return Undefined{}, nil
+		}
+		return a.elements[int(i)], nil
+	})
+	method("slice", 2, func(a *Object, args []Value) (Value, error) {
+		start := relativeIndex(arg(args, 0), len(a.elements), 0)
+		end := relativeIndex(arg(args, 1), len(a.elements), len(a.elements))
+		if end < start {
+			end = start
+		}
+		return rt.array(slices.Clone(a.elements[start:end]))
+	})
+	method("concat", 1, func(a *Object, args []Value) (Value, error) {
+		elements := slices.Clone(a.elements)
+		for _, v := range args {
+			if o, ok := v.(*Object); ok && o.Class == "Array" {
+				elements = append(elements, o.elements...)
+			} else {
+				elements = append(elements, v)
+			}
+		}
+		return rt.array(elements)
+	})
+	method("join", 1, func(a *Object, args []Value) (Value, error) {
+		sep := ","
+		if v := arg(args, 0); v != (Undefined{}) {
+			sep = ToString(v)
+		}
+		return rt.string(a.join(sep))
+	})
+	method("toString", 0, func(a *Object, args []Value) (Value, error) {
+		return rt.string(a.join(","))
+	})
+	method("indexOf", 1, func(a *Object, args []Value) (Value, error) {
+		for i := relativeIndex(arg(args, 1), len(a.elements), 0); i < len(a.elements); i++ {
+			if StrictEquals(a.elements[i], arg(args, 0)) {
+				return Number(i), nil
+			}
+		}
+		return Number(-1), nil
+	})
+	method("lastIndexOf", 1, func(a *Object, args []Value) (Value, error) {
+		from := len(a.elements) - 1
+		if len(args) > 1 {
+			from = relativeIndex(args[1], len(a.elements), 0)
+			if toInteger(args[1]) < -float64(len(a.elements)) {
+				from = -1
+			}
+		}
+		for i := min(from, len(a.elements)-1); i >= 0; i-- {
+			if StrictEquals(a.elements[i], arg(args, 0)) {
+				return Number(i), nil
+			}
+		}
+		return Number(-1), nil
+	})
+	method("includes", 1, func(a *Object, args []Value) (Value, error) {
+		for i := relativeIndex(arg(args, 1), len(a.elements), 0); i < len(a.elements); i++ {
+			if sameValueZero(a.elements[i], arg(args, 0)) {
+				return Boolean(true), nil
+			}
+		}
+		return Boolean(false), nil
+	})
+	method("flat", 0, func(a *Object, args []Value) (Value, error) {
+		depth := 1.0
+		if _, ok := arg(args, 0).(Undefined); !ok {
+			depth = toInteger(args[0])
+		}
+		return rt.array(flatten(nil, a.elements, depth))
+	})
+
+	// Iteration visits the elements there were when it started, as they are when the
+	// callback is called
+	iterate := func(a *Object, args []Value, visit func(i int, v, result Value) bool) error {
+		f, err := callable(arg(args, 0))
+		if err != nil {
+			return err
+		}
+		for i, n := 0, len(a.elements); i < n && i < len(a.elements); i++ {
+			v := a.elements[i]
+			result, err := f.Call(arg(args, 1), v, Number(i), a)
+			if err != nil {
+				return err
+			}
+			if !visit(i, v, result) {
+				break
+			}
+		}
+		return nil
+	}
+	method("forEach", 1, func(a *Object, args []Value) (Value, error) {
+		err := iterate(a, args, func(int, Value, Value) bool { return true })
+		return Undefined{}, err
+	})
+	method("map", 1, func(a *Object, args []Value) (Value, error) {
+		results := make([]Value, 0, len(a.elements))
+		err := iterate(a, args, func(_ int, _, result Value) bool {
+			results = append(results, result)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return rt.array(results)
+	})
+	method("filter", 1, func(a *Object, args []Value) (Value, error) {
+		var kept []Value
+		err := iterate(a, args, func(_ int, v, result Value) bool {
+			if ToBoolean(result) {
+				kept = append(kept, v)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return rt.array(kept)
+	})
+	method("flatMap", 1, func(a *Object, args []Value) (Value, error) {
+		var results []Value
+		err := iterate(a, args, func(_ int, _, result Value) bool {
+			results = flatten(results, []Value{result}, 1)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return rt.array(results)
+	})
+	method("some", 1, func(a *Object, args []Value) (Value, error) {
+		found := false
+		err := iterate(a, args, func(_ int, _, result Value) bool {
+			found = ToBoolean(result)
+			return !found
+		})
+		return Boolean(found), err
+	})
+	method("every", 1, func(a *Object, args []Value) (Value, error) {
+		all := true
+		err := iterate(a, args, func(_ int, _, result Value) bool {
+			all = ToBoolean(result)
+			return all
+		})
+		return Boolean(all), err
+	})
+	method("find", 1, func(a *Object, args []Value) (Value, error) {
+		var found Value = Undefined{}
+		err := iterate(a, args, func(_ int, v, result Value) bool {
+			if ToBoolean(result) {
+				found = v
+				return false
+			}
+			return true
+		})
+		return found, err
+	})
+	method("findIndex", 1, func(a *Object, args []Value) (Value, error) {
+		found := -1
+		err := iterate(a, args, func(i int, _, result Value) bool {
+			if ToBoolean(result) {
+				found = i
+				return false
+			}
+			return true
+		})
+		return Number(found), err
+	})
+	for _, name := range []string{"findLast", "findLastIndex"} {
+		method(name, 1, func(a *Object, args []Value) (Value, error) {
+			f, err := callable(arg(args, 0))
+			if err != nil {
+				return nil, err
+			}
+			for i := len(a.elements) - 1; i >= 0; i-- {
+				v := a.elements[i]
+				result, err := f.Call(arg(args, 1), v, Number(i), a)
+				if err != nil {
+					return nil, err
+				}
+				if ToBoolean(result) {
+					if name == "findLast" {
+						return v, nil
+					}
+					return Number(i), nil
+				}
+			}
+			if name == "findLast" {
+				return Undefined{}, nil
+			}
+			return Number(-1), nil
+		})
+	}
+	for _, name := range []string{"reduce", "reduceRight"} {
+		method(name, 1, func(a *Object, args []Value) (Value, error) {
+			f, err := callable(arg(args, 0))
+			if err != nil {
+				return nil, err
+			}
+			indexes := make([]int, len(a.elements))
+			for i := range indexes {
+				indexes[i] = i
+			}
+			if name == "reduceRight" {
+				slices.Reverse(indexes)
+			}
+			var acc Value
+			if len(args) > 1 {
+				acc = args[1]
+			} else if len(indexes) == 0 {
+				return nil, typeError("Reduce of empty array with no initial value")
+			} else {
+				acc, indexes = a.elements[indexes[0]], indexes[1:]
+			}
+			for _, i := range indexes {
+				if i >= len(a.elements) {
+					continue // The callback shortened the array
+				}
+				if acc, err = f.Call(Undefined{}, acc, a.elements[i], Number(i), a); err != nil {
+					return nil, err
+				}
+			}
+			return acc, nil
+		})
+	}
+
+	// Arrays of indexes, values and entries stand in for iterators, which programs could
+	// not step through without loops
+	method("keys", 0, func(a *Object, args []Value) (Value, error) {
+		keys := make([]Value, len(a.elements))
+		for i := range keys {
+			keys[i] = Number(i)
+		}
+		return rt.array(keys)
+	})
+	method("values", 0, func(a *Object, args []Value) (Value, error) {
+		return rt.array(slices.Clone(a.elements))
+	})
+	method("entries", 0, func(a *Object, args []Value) (Value, error) {
+		entries := make([]Value, len(a.elements))
+		for i, v := range a.elements {
+			pair, err := rt.array([]Value{Number(i), v})
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = pair
+		}
+		return rt.array(entries)
+	})
+}
+
+// grow checks that an array may take n more elements
+func (rt *Runtime) grow(a *Object, n int) error {
+	if a.frozen {
+		return typeError("Cannot add property %d, object is not extensible", len(a.elements))
+	}
+	if len(a.elements)+n > maxArrayLength {
+		return rangeError("Invalid array length")
+	}
+	if n > 0 {
+		return rt.meter.Allocate(n)
+	}
+	return nil
+}
+
+// arrayLike returns the values Array.from takes from a value: those it iterates, or the
+// indexed properties of an object with a length
+func (rt *Runtime) arrayLike(v Value) ([]Value, error) {
+	if values, err := rt.iterate(v); err == nil {
+		return values, nil
+	}
+	o := objectOf(v)
+	if o == nil {
+		return nil, nil // Other primitives give an empty array
+	}
+	n := toInteger(o.Get("length"))
+	if n > maxArrayLength {
+		return nil, rangeError("Invalid array length")
+	}
+	values := make([]Value, max(0, int(n)))
+	for i := range values {
+		values[i] = o.Get(constant.NumberToString(float64(i)))
+	}
+	return values, nil
+}
+
+// flatten appends the values to out, spreading nested arrays depth levels deep
+func flatten(out []Value, values []Value, depth float64) []Value {
+	for _, v := range values {
+		if o, ok := v.(*Object); ok && o.Class == "Array" && depth >= 1 && !o.joining {
+			o.joining = true // A cycle is flattened once
+			out = flatten(out, o.elements, depth-1)
+			o.joining = false
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// sortValues sorts values stably with a comparison function, or by their strings if it is
+// undefined; undefined values go last without being compared, as in JavaScript
+func sortValues(values []Value, compare Value) ([]Value, error) {
+	defined := values[:0:0]
+	undefined := 0
+	for _, v := range values {
+		if _, ok := v.(Undefined); ok {
+			undefined++
+		} else {
+			defined = append(defined, v)
+		}
+	}
+	var failed error
+	f, _ := compare.(*Function)
+	slices.SortStableFunc(defined, func(a, b Value) int {
+		if failed != nil {
+			return 0
+		}
+		if f == nil {
+			return constant.CompareStrings(ToString(a), ToString(b))
+		}
+		result, err := f.Call(Undefined{}, a, b)
+		if err != nil {
+			failed = err
+			return 0
+		}
+		n := ToNumber(result)
+		switch {
+		case n < 0:
+			return -1
+		case n > 0:
+			return 1
+		}
+		return 0 // Also NaN
+	})
+	if failed != nil {
+		return nil, failed
+	}
+	for ; undefined > 0; undefined-- {
+		defined = append(defined, Undefined{})
+	}
+	return defined, nil
+}
+
+// sameValueZero is the equality of includes, Map and Set: strict equality where NaN
+// equals itself
+func sameValueZero(a, b Value) bool {
+	x, ok := a.(Number)
+	y, ok2 := b.(Number)
+	if ok && ok2 && x != x && y != y {
+		return true
+	}
+	return StrictEquals(a, b)
+}
@@ -0,0 +1,291 @@
+package interp
+
+import (
+	"io"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"goast/constant"
+)
+
+// console creates the console object
+// log and info write to stdout, warn and error to stderr
+func console(stdout, stderr io.Writer) *Object {
+	c := NewObject()
+	for _, name := range []string{"log", "info", "warn", "error"} {
+		w := stdout
+		if name == "warn" || name == "error" {
+			w = stderr
+		}
+		c.Set(name, NewNativeFunction(name, func(this Value, args []Value) (Value, error) {
+			_, err := io.WriteString(w, formatLog(args)+"\n")
+			return Undefined{}, err
+		}))
+	}
+	return c
+}
+
+// formatLog formats console arguments the way Node.js does: strings as they are, other
+// values inspected, separated by spaces
+func formatLog(args []Value) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if s, ok := arg.(String); ok {
+			parts[i] = string(s)
+		} else {
+			parts[i] = Inspect(arg)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// mathFunctions are the functions of Math taking numbers and returning a number
+var mathFunctions = map[string]func(x []float64) float64{
+	"abs":    unary(math.Abs),
+	"acos":   unary(math.Acos),
+	"acosh":  unary(math.Acosh),
+	"asin":   unary(math.Asin),
+	"asinh":  unary(math.Asinh),
+	"atan":   unary(math.Atan),
+	"atanh":  unary(math.Atanh),
+	"atan2":  func(x []float64) float64 { return math.Atan2(x[0], x[1]) },
+	"cbrt":   unary(math.Cbrt),
+	"ceil":   unary(math.Ceil),
+	"cos":    unary(math.Cos),
+	"cosh":   unary(math.Cosh),
+	"exp":    unary(math.Exp),
+	"expm1":  unary(math.Expm1),
+	"floor":  unary(math.Floor),
+	"fround": unary(func(x float64) float64 { return float64(float32(x)) }),
+	"hypot":  hypot,
+	"log":    unary(math.Log),
+	"log1p":  unary(math.Log1p),
+	"log10":  unary(math.Log10),
+	"log2":   unary(math.Log2),
+	"max":    func(x []float64) float64 { return extremum(x, math.Inf(-1), 1) },
+	"min":    func(x []float64) float64 { return extremum(x, math.Inf(1), -1) },
+	"pow":    func(x []float64) float64 { return pow(x[0], x[1]) },
+	"random": func([]float64) float64 { return rand.Float64() },
+	"round":  unary(round),
+	"sign":   unary(sign),
+	"sin":    unary(math.Sin),
+	"sinh":   unary(math.Sinh),
+	"sqrt":   unary(math.Sqrt),
+	"tan":    unary(math.Tan),
+	"tanh":   unary(math.Tanh),
+	"trunc":  unary(math.Trunc),
+}
+
+// mathArity is the number of arguments the functions of Math read, missing ones are NaN
+var mathArity = map[string]int{"atan2": 2, "pow": 2, "random": 0}
+
+// mathObject creates the Math object
+func mathObject() *Object {
+	m := NewObject()
+	m.Class = "Math"
+	for _, c := range []struct {
+		name  string
+		value float64
+	}{
+		{"E", math.E}, {"LN10", math.Ln10}, {"LN2", math.Ln2}, {"LOG10E", math.Log10E},
+		{"LOG2E", math.Log2E}, {"PI", math.Pi}, {"SQRT1_2", math.Sqrt2 / 2}, {"SQRT2", math.Sqrt2},
+	} {
+		m.Set(c.name, Number(c.value))
+	}
+	for _, name := range slices.Sorted(maps.Keys(mathFunctions)) {
+		f := mathFunctions[name]
+		arity, ok := mathArity[name]
+		if !ok {
+			arity = 1
+		}
+		m.Set(name, NewNativeFunction(name, func(this Value, args []Value) (Value, error) {
+			x := make([]float64, max(arity, len(args)))
+			for i := range x {
+				x[i] = math.NaN()
+				if i < len(args) {
+					x[i] = ToNumber(args[i])
+				}
+			}
+			if name == "hypot" || name == "max" || name == "min" {
+				x = x[:len(args)] // Variadic, no argument is not a NaN argument
+			}
+			return Number(f(x)), nil
+		}))
+	}
+	return m
+}
+
+// unary adapts a function of one number to the argument list of a Math function
+func unary(f func(float64) float64) func(x []float64) float64 {
+	return func(x []float64) float64 { return f(x[0]) }
+}
+
+// extremum implements Math.max and Math.min, dir being 1 for the maximum
+// NaN wins over everything, and +0 is greater than -0
+func extremum(x []float64, result float64, dir float64) float64 {
+	for _, v := range x {
+		switch {
+		case math.IsNaN(v):
+			return math.NaN()
+		case (v-result)*dir > 0:
+			result = v
+		case v == 0 && result == 0 && math.Signbit(result) == (dir > 0):
+			result = v
+		}
+	}
+	return result
+}
+
+// hypot implements Math.hypot: the square root of the sum of the squares of its arguments
+func hypot(x []float64) float64 {
+	sum := 0.0
+	for _, v := range x {
+		if math.IsInf(v, 0) {
+			return math.Inf(1)
+		}
+		sum += v * v
+	}
+	return math.Sqrt(sum)
+}
+
+// round implements Math.round, which rounds halves up, towards +Infinity
+func round(x float64) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) || x == math.Trunc(x) {
+		return x
+	}
+	if x < 0 && x >= -0.5 {
+		return math.Copysign(0, -1)
+	}
+	r := math.Floor(x)
+	if x-r >= 0.5 {
+		r++
+	}
+	return r
+}
+
+// pow implements Math.pow, which unlike math.Pow gives NaN for 1 or -1 to an infinite power
+func pow(x, y float64) float64 {
+	if math.IsInf(y, 0) && math.Abs(x) == 1 {
+		return math.NaN()
+	}
+	return math.Pow(x, y)
+}
+
+// sign implements Math.sign, keeping NaN and the sign of zeros
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return x
+}
+
+// parseInt implements the global parseInt: the integer at the start of a string, in base 10
+// or 16 for 0x, or in the base given by the second argument between 2 and 36
+func parseInt(this Value, args []Value) (Value, error) {
+	s := strings.TrimLeftFunc(ToString(arg(args, 0)), constant.IsSpace)
+	sign := 1.0
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	radix := int(toInt32(ToNumber(arg(args, 1))))
+	switch {
+	case radix == 0 || radix == 16:
+		if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+			s, radix = s[2:], 16
+		} else if radix == 0 {
+			radix = 10
+		}
+	case radix < 2 || radix > 36:
+		return Number(math.NaN()), nil
+	}
+	n, digits := 0.0, 0
+	for ; digits < len(s); digits++ {
+		d := digitValue(s[digits])
+		if d >= radix {
+			break
+		}
+		n = n*float64(radix) + float64(d)
+	}
+	if digits == 0 {
+		return Number(math.NaN()), nil
+	}
+	if radix == 10 {
+		n, _ = strconv.ParseFloat(s[:digits], 64) // Exact rounding for long decimal numbers
+	}
+	return Number(sign * n), nil
+}
+
+// digitValue returns the value of a digit in bases up to 36, 36 for anything else
+func digitValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	}
+	return 36
+}
+
+// parseFloat implements the global parseFloat: the longest decimal number at the start of a string
+func parseFloat(this Value, args []Value) (Value, error) {
+	s := strings.TrimLeftFunc(ToString(arg(args, 0)), constant.IsSpace)
+	for end := len(s); end > 0; end-- {
+		if prefix := s[:end]; constant.IsDecimal(prefix) {
+			return Number(constant.StringToNumber(prefix)), nil
+		}
+	}
+	return Number(math.NaN()), nil
+}
+
+// isNaN implements the global isNaN, which converts its argument to a number first
+func isNaN(this Value, args []Value) (Value, error) {
+	return Boolean(math.IsNaN(ToNumber(arg(args, 0)))), nil
+}
+
+// isFinite implements the global isFinite, which converts its argument to a number first
+func isFinite(this Value, args []Value) (Value, error) {
+	n := ToNumber(arg(args, 0))
+	return Boolean(!math.IsNaN(n) && !math.IsInf(n, 0)), nil
+}
+
+// toInt32 converts a number to a 32-bit integer the way bitwise operators do
+func toInt32(n float64) int32 {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0
+	}
+	return int32(uint32(int64(math.Mod(math.Trunc(n), 1<<32))))
+}
+
+// toInteger converts a value to an integer, truncating it; NaN becomes 0
+func toInteger(v Value) float64 {
+	n := ToNumber(v)
+	if math.IsNaN(n) {
+		return 0
+	}
+	return math.Trunc(n)
+}
+
+// relativeIndex resolves an index argument counting from the end when negative, as slice
+// and its kin do, clamped to 0..length; undefined gives def
+func relativeIndex(v Value, length int, def int) int {
+	if _, ok := v.(Undefined); ok {
+		return def
+	}
+	n := toInteger(v)
+	if n < 0 {
+		n += float64(length)
+	}
+	return int(math.Max(0, math.Min(n, float64(length))))
+}
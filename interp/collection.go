@@ -0,0 +1,225 @@
+package interp
+
+// collection is the internal state of a Map or a Set: its entries in insertion order, with
+// an index from keys to positions
+// Deleted entries stay in the list, marked, so that a forEach running meanwhile goes on
+// with the entries that follow
+type collection struct {
+	entries []entry
+	index   map[any]int
+	size    int
+}
+
+// entry is a key of a collection with its value, the key again in a set
+type entry struct {
+	key, value Value
+	deleted    bool
+}
+
+// nanKey stands for NaN in the index of a collection, where all NaNs are the same key
+type nanKey struct{}
+
+// collectionKey returns the key under which a value is indexed: keys are compared with
+// SameValueZero, so -0 is 0 and NaN is itself
+func collectionKey(v Value) any {
+	if n, ok := v.(Number); ok {
+		switch {
+		case n != n:
+			return nanKey{}
+		case n == 0:
+			return Number(0)
+		}
+	}
+	return v
+}
+
+// canonicalZero turns -0 into 0, the way keys are stored
+func canonicalZero(v Value) Value {
+	if n, ok := v.(Number); ok && n == 0 {
+		return Number(0)
+	}
+	return v
+}
+
+// get returns the value of a key
+func (c *collection) get(key Value) (Value, bool) {
+	if i, ok := c.index[collectionKey(key)]; ok {
+		return c.entries[i].value, true
+	}
+	return nil, false
+}
+
+// set adds a key or updates its value, and reports whether the key is new
+func (c *collection) set(key, value Value) bool {
+	k := collectionKey(key)
+	if i, ok := c.index[k]; ok {
+		c.entries[i].value = value
+		return false
+	}
+	if c.index == nil {
+		c.index = map[any]int{}
+	}
+	c.index[k] = len(c.entries)
+	c.entries = append(c.entries, entry{key: canonicalZero(key), value: value})
+	c.size++
+	return true
+}
+
+// remove deletes a key and reports whether it was there
+func (c *collection) remove(key Value) bool {
+	k := collectionKey(key)
+	i, ok := c.index[k]
+	if !ok {
+		return false
+	}
+	delete(c.index, k)
+	c.entries[i] = entry{deleted: true}
+	c.size--
+	return true
+}
+
+// clear deletes all keys
+func (c *collection) clear() {
+	for i := range c.entries {
+		c.entries[i] = entry{deleted: true}
+	}
+	c.index = nil
+	c.size = 0
+}
+
+// forEach calls fn with the live entries, including those added while it runs
+func (c *collection) forEach(fn func(e entry) error) error {
+	for i := 0; i < len(c.entries); i++ {
+		if e := c.entries[i]; !e.deleted {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// defineCollections defines Map and Set
+// Their keys, values and entries methods return arrays rather than iterators
+func (rt *Runtime) defineCollections() {
+	for _, class := range []string{"Map", "Set"} {
+		rt.constructor(class, 0, nil, func(args []Value) (Value, error) {
+			if err := rt.meter.Allocate(1); err != nil {
+				return nil, err
+			}
+			c := &collection{}
+			o := &Object{Class: class, data: c}
+			if isNullish(arg(args, 0)) {
+				return o, nil
+			}
+			values, err := rt.iterate(args[0])
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range values {
+				if err := rt.meter.Allocate(1); err != nil {
+					return nil, err
+				}
+				if class == "Set" {
+					v = canonicalZero(v)
+					c.set(v, v)
+					continue
+				}
+				if !isObject(v) {
+					return nil, typeError("Iterator value %s is not an entry object", Inspect(v))
+				}
+				key, err := rt.GetProperty(v, "0")
+				if err != nil {
+					return nil, err
+				}
+				value, err := rt.GetProperty(v, "1")
+				if err != nil {
+					return nil, err
+				}
+				c.set(key, value)
+			}
+			return o, nil
+		})
+	}
+
+	for _, class := range []string{"Map", "Set"} {
+		proto := rt.protos[class]
+		method := func(name string, length int, fn func(o *Object, c *collection, args []Value) (Value, error)) {
+			rt.method(proto, name, length, func(this Value, args []Value) (Value, error) {
+				if o, ok := this.(*Object); ok && o.Class == class {
+					if c, ok := o.data.(*collection); ok {
+						return fn(o, c, args)
+					}
+				}
+				return nil, typeError("Method %s.prototype.%s called on incompatible receiver %s", class, name, describeValue(this))
+			})
+		}
+		// list is method for keys, values and entries, which list what pick returns for each entry
+		list := func(name string, pick func(e entry) (Value, error)) {
+			method(name, 0, func(o *Object, c *collection, args []Value) (Value, error) {
+				values := make([]Value, 0, c.size)
+				err := c.forEach(func(e entry) error {
+					v, err := pick(e)
+					values = append(values, v)
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+				return rt.array(values)
+			})
+		}
+
+		method("has", 1, func(o *Object, c *collection, args []Value) (Value, error) {
+			_, ok := c.get(arg(args, 0))
+			return Boolean(ok), nil
+		})
+		method("delete", 1, func(o *Object, c *collection, args []Value) (Value, error) {
+			return Boolean(c.remove(arg(args, 0))), nil
+		})
+		method("clear", 0, func(o *Object, c *collection, args []Value) (Value, error) {
+			c.clear()
+			return Undefined{}, nil
+		})
+		method("forEach", 1, func(o *Object, c *collection, args []Value) (Value, error) {
+			fn, err := callable(arg(args, 0))
+			if err != nil {
+				return nil, err
+			}
+			return Undefined{}, c.forEach(func(e entry) error {
+				_, err := fn.Call(arg(args, 1), e.value, e.key, o)
+				return err
+			})
+		})
+		list("keys", func(e entry) (Value, error) { return e.key, nil })
+		list("values", func(e entry) (Value, error) { return e.value, nil })
+		list("entries", func(e entry) (Value, error) { return rt.array([]Value{e.key, e.value}) })
+
+		if class == "Map" {
+			method("get", 1, func(o *Object, c *collection, args []Value) (Value, error) {
+				if v, ok := c.get(arg(args, 0)); ok {
+					return v, nil
+				}
+				return Undefined{}, nil
+			})
+			method("set", 2, func(o *Object, c *collection, args []Value) (Value, error) {
+				if c.set(arg(args, 0), arg(args, 1)) {
+					if err := rt.meter.Allocate(1); err != nil {
+						return nil, err
+					}
+				}
+				return o, nil
+			})
+		} else {
+			method("add", 1, func(o *Object, c *collection, args []Value) (Value, error) {
+				v := canonicalZero(arg(args, 0))
+				if c.set(v, v) {
+					if err := rt.meter.Allocate(1); err != nil {
+						return nil, err
+					}
+				}
+				return o, nil
+			})
+		}
+	}
+}
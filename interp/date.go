@@ -0,0 +1,308 @@
+package interp
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// date is the internal state of a Date object: its time value in milliseconds since the
+// epoch, NaN for an invalid date, and the time zone its local methods use
+type date struct {
+	ms  float64
+	loc *time.Location
+}
+
+// String formats a date as Date.prototype.toString does
+func (d *date) String() string {
+	if math.IsNaN(d.ms) {
+		return "Invalid Date"
+	}
+	return d.time().In(d.loc).Format("Mon Jan 02 2006 15:04:05 GMT-0700 (MST)")
+}
+
+// time converts the time value of a valid date to a time.Time in UTC
+func (d *date) time() time.Time {
+	return time.UnixMilli(int64(d.ms)).UTC()
+}
+
+// export converts a date to a time.Time in its time zone, the zero time if it is invalid
+func (d *date) export() time.Time {
+	if math.IsNaN(d.ms) {
+		return time.Time{}
+	}
+	return d.time().In(d.loc)
+}
+
+// isoString formats a valid date as Date.prototype.toISOString does, with six digits and a
+// sign for years outside 0 to 9999
+func (d *date) isoString() string {
+	t := d.time()
+	year := fmt.Sprintf("%04d", t.Year())
+	if t.Year() < 0 || t.Year() > 9999 {
+		year = fmt.Sprintf("%+07d", t.Year())
+	}
+	return year + t.Format("-01-02T15:04:05.000Z")
+}
+
+// maxTime is the largest time value a date can hold, 100 million days from the epoch
+const maxTime = 8.64e15
+
+// timeClip makes a time value an integer, NaN if it is out of range
+func timeClip(ms float64) float64 {
+	if math.IsNaN(ms) || math.Abs(ms) > maxTime {
+		return math.NaN()
+	}
+	return math.Trunc(ms) + 0 // + 0 turns -0 into 0
+}
+
+// dateFields are the components of a date, in the order of the Date constructor's arguments:
+// year, month from 0, day of the month, hours, minutes, seconds and milliseconds
+type dateFields [7]float64
+
+// fields splits a valid date into its components in a time zone
+func (d *date) fields(loc *time.Location) dateFields {
+	t := d.time().In(loc)
+	return dateFields{
+		float64(t.Year()), float64(t.Month() - 1), float64(t.Day()),
+		float64(t.Hour()), float64(t.Minute()), float64(t.Second()), float64(t.Nanosecond() / 1e6),
+	}
+}
+
+// makeTime computes the time value of components in a time zone; out of range components
+// carry over to the next ones, as in JavaScript
+func makeTime(f dateFields, loc *time.Location) float64 {
+	var n [7]int
+	for i, x := range f {
+		if math.IsNaN(x) || math.Abs(x) > 1e12 {
+			return math.NaN()
+		}
+		n[i] = int(x)
+	}
+	t := time.Date(n[0], time.Month(n[1]+1), n[2], n[3], n[4], n[5], 0, loc)
+	return timeClip(float64(t.UnixMilli()) + float64(n[6]))
+}
+
+// isoDate matches the date time string format of JavaScript, a simplification of ISO 8601
+var isoDate = regexp.MustCompile(`^([+-]\d{6}|\d{4})(?:-(\d\d)(?:-(\d\d))?)?(?:T(\d\d):(\d\d)(?::(\d\d)(?:\.(\d{1,9}))?)?(Z|[+-]\d\d:\d\d)?)?$`)
+
+// dateLayouts are the other formats Date.parse accepts, those of the date's own methods
+var dateLayouts = []string{
+	"Mon Jan 02 2006 15:04:05 GMT-0700 (MST)",
+	"Mon Jan 02 2006 15:04:05 GMT-0700",
+	"Mon, 02 Jan 2006 15:04:05 GMT",
+	"Mon Jan 02 2006",
+}
+
+// parseDate parses a date string, NaN if it has none of the supported formats
+// ISO dates without a time are UTC, ISO date times without an offset are local
+func parseDate(s string, loc *time.Location) float64 {
+	if m := isoDate.FindStringSubmatch(s); m != nil {
+		if m[1] == "-000000" {
+			return math.NaN()
+		}
+		f := dateFields{0, 0, 1}
+		for i, part := range m[1:7] {
+			if part != "" {
+				f[i], _ = strconv.ParseFloat(part, 64)
+			}
+		}
+		f[1]--
+		if m[7] != "" {
+			ms, _ := strconv.ParseFloat("0."+m[7], 64)
+			f[6] = math.Floor(ms * 1000)
+		}
+		if f[1] < 0 || f[1] > 11 || f[2] < 1 || f[2] > 31 || f[3] > 24 || f[4] > 59 || f[5] > 59 ||
+			f[3] == 24 && (f[4] != 0 || f[5] != 0 || f[6] != 0) {
+			return math.NaN()
+		}
+		zone := loc
+		switch offset := m[8]; {
+		case offset == "Z" || m[4] == "":
+			zone = time.UTC
+		case offset != "":
+			h, _ := strconv.Atoi(offset[1:3])
+			mi, _ := strconv.Atoi(offset[4:6])
+			seconds := h*3600 + mi*60
+			if offset[0] == '-' {
+				seconds = -seconds
+			}
+			zone = time.FixedZone("", seconds)
+		}
+		return makeTime(f, zone)
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return timeClip(float64(t.UnixMilli()))
+		}
+	}
+	return math.NaN()
+}
+
+// defineDate defines Date and the methods of Date.prototype
+// Dates read the current time from the clock of the Options and use their time zone
+func (rt *Runtime) defineDate() {
+	now := func() float64 { return timeClip(float64(rt.now().UnixMilli())) }
+	ctor := rt.constructor("Date", 7, func(this Value, args []Value) (Value, error) {
+		return String((&date{ms: now(), loc: rt.location}).String()), nil
+	}, func(args []Value) (Value, error) {
+		ms := now()
+		switch len(args) {
+		case 0:
+		case 1:
+			v := args[0]
+			if o, ok := v.(*Object); ok {
+				if d, ok := o.data.(*date); ok {
+					v = Number(d.ms)
+				}
+			}
+			if s, ok := ToPrimitive(v).(String); ok {
+				ms = parseDate(string(s), rt.location)
+			} else {
+				ms = timeClip(ToNumber(v))
+			}
+		default:
+			ms = makeTime(dateArgs(args), rt.location)
+		}
+		return rt.newDate(ms)
+	})
+	rt.method(&ctor.Object, "now", 0, func(this Value, args []Value) (Value, error) {
+		return Number(now()), nil
+	})
+	rt.method(&ctor.Object, "parse", 1, func(this Value, args []Value) (Value, error) {
+		return Number(parseDate(ToString(arg(args, 0)), rt.location)), nil
+	})
+	rt.method(&ctor.Object, "UTC", 7, func(this Value, args []Value) (Value, error) {
+		return Number(makeTime(dateArgs(args), time.UTC)), nil
+	})
+
+	proto := rt.protos["Date"]
+	method := func(name string, length int, fn func(d *date, args []Value) (Value, error)) {
+		rt.method(proto, name, length, func(this Value, args []Value) (Value, error) {
+			if o, ok := this.(*Object); ok {
+				if d, ok := o.data.(*date); ok {
+					return fn(d, args)
+				}
+			}
+			return nil, typeError("this is not a Date object.")
+		})
+	}
+	// format is method for the methods formatting a date, "Invalid Date" when it is invalid
+	format := func(name string, fn func(d *date) string) {
+		method(name, 0, func(d *date, args []Value) (Value, error) {
+			if math.IsNaN(d.ms) {
+				return String("Invalid Date"), nil
+			}
+			return String(fn(d)), nil
+		})
+	}
+
+	method("getTime", 0, func(d *date, args []Value) (Value, error) { return Number(d.ms), nil })
+	method("valueOf", 0, func(d *date, args []Value) (Value, error) { return Number(d.ms), nil })
+	method("setTime", 1, func(d *date, args []Value) (Value, error) {
+		d.ms = timeClip(ToNumber(arg(args, 0)))
+		return Number(d.ms), nil
+	})
+	method("getTimezoneOffset", 0, func(d *date, args []Value) (Value, error) {
+		if math.IsNaN(d.ms) {
+			return Number(math.NaN()), nil
+		}
+		_, offset := d.time().In(d.loc).Zone()
+		return Number(-offset / 60), nil
+	})
+	method("toISOString", 0, func(d *date, args []Value) (Value, error) {
+		if math.IsNaN(d.ms) {
+			return nil, rangeError("Invalid time value")
+		}
+		return String(d.isoString()), nil
+	})
+	method("toJSON", 1, func(d *date, args []Value) (Value, error) {
+		if math.IsNaN(d.ms) {
+			return Null{}, nil
+		}
+		return String(d.isoString()), nil
+	})
+	format("toString", (*date).String)
+	format("toDateString", func(d *date) string { return d.time().In(d.loc).Format("Mon Jan 02 2006") })
+	format("toTimeString", func(d *date) string { return d.time().In(d.loc).Format("15:04:05 GMT-0700 (MST)") })
+	format("toUTCString", func(d *date) string { return d.time().Format("Mon, 02 Jan 2006 15:04:05 GMT") })
+
+	// The getters and setters of the components, in local time and in UTC
+	// Each setter takes its component and optionally the following ones, like setHours(h, m, s, ms)
+	components := []struct {
+		name  string
+		field int // Index in dateFields, -1 for the day of the week
+		count int // Number of components the setter takes
+	}{
+		{"FullYear", 0, 3}, {"Month", 1, 2}, {"Date", 2, 1}, {"Day", -1, 0},
+		{"Hours", 3, 4}, {"Minutes", 4, 3}, {"Seconds", 5, 2}, {"Milliseconds", 6, 1},
+	}
+	for _, utc := range []bool{false, true} {
+		zone := func(d *date) *time.Location {
+			if utc {
+				return time.UTC
+			}
+			return d.loc
+		}
+		prefix := ""
+		if utc {
+			prefix = "UTC"
+		}
+		for _, c := range components {
+			method("get"+prefix+c.name, 0, func(d *date, args []Value) (Value, error) {
+				switch {
+				case math.IsNaN(d.ms):
+					return Number(math.NaN()), nil
+				case c.field < 0:
+					return Number(d.time().In(zone(d)).Weekday()), nil
+				}
+				return Number(d.fields(zone(d))[c.field]), nil
+			})
+			if c.count == 0 {
+				continue
+			}
+			method("set"+prefix+c.name, c.count, func(d *date, args []Value) (Value, error) {
+				var f dateFields
+				switch {
+				case !math.IsNaN(d.ms):
+					f = d.fields(zone(d))
+				case c.field == 0:
+					f = dateFields{0, 0, 1} // setFullYear makes an invalid date valid again
+				default:
+					return Number(math.NaN()), nil
+				}
+				if len(args) == 0 {
+					args = []Value{Undefined{}}
+				}
+				for i := 0; i < c.count && i < len(args); i++ {
+					f[c.field+i] = ToNumber(args[i])
+				}
+				d.ms = makeTime(f, zone(d))
+				return Number(d.ms), nil
+			})
+		}
+	}
+}
+
+// dateArgs converts the arguments of the Date constructor or Date.UTC to components
+// Missing ones are the start of their period; years 0 to 99 mean 1900 to 1999
+func dateArgs(args []Value) dateFields {
+	f := dateFields{math.NaN(), 0, 1}
+	for i := 0; i < len(args) && i < len(f); i++ {
+		f[i] = ToNumber(args[i])
+	}
+	if y := math.Trunc(f[0]); y >= 0 && y <= 99 {
+		f[0] = 1900 + y
+	}
+	return f
+}
+
+// newDate creates a Date object
+func (rt *Runtime) newDate(ms float64) (*Object, error) {
+	if err := rt.meter.Allocate(1); err != nil {
+		return nil, err
+	}
+	return &Object{Class: "Date", data: &date{ms: ms, loc: rt.location}}, nil
+}
@@ -0,0 +1,102 @@
+package interp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// errorTypes are the error classes, Error first and the others inheriting from it
+var errorTypes = []string{"Error", "TypeError", "RangeError", "SyntaxError", "ReferenceError"}
+
+// errorData marks the objects created by the error constructors
+type errorData struct{}
+
+// errorf creates an exception without position
+func errorf(name, format string, args ...any) *Error {
+	return &Error{Name: name, Message: fmt.Sprintf(format, args...)}
+}
+
+// errorString formats an error object as Error.prototype.toString does: "Name: message",
+// or only one of them when the other is empty
+// The name is the class of the error unless the object has a name property of its own
+func errorString(o *Object) string {
+	name, message := o.Class, ""
+	if v, ok := o.own("name"); ok {
+		name = ToString(v)
+	}
+	if v, ok := o.own("message"); ok {
+		message = ToString(v)
+	}
+	switch {
+	case name == "":
+		return message
+	case message == "":
+		return name
+	}
+	return name + ": " + message
+}
+
+// newError creates an error object of class name
+func (rt *Runtime) newError(name, message string) (*Object, error) {
+	if err := rt.meter.Allocate(1); err != nil {
+		return nil, err
+	}
+	o := &Object{Class: name, data: errorData{}}
+	if message != "" {
+		o.Set("message", String(message))
+	}
+	return o, nil
+}
+
+// defineErrors defines Error and its subclasses, which create error objects with or without new
+func (rt *Runtime) defineErrors() {
+	for _, name := range errorTypes {
+		construct := func(args []Value) (Value, error) {
+			message := ""
+			if v := arg(args, 0); v != (Undefined{}) {
+				message = ToString(v)
+			}
+			return rt.newError(name, message)
+		}
+		rt.constructor(name, 1, func(this Value, args []Value) (Value, error) {
+			return construct(args)
+		}, construct)
+		proto := rt.protos[name]
+		proto.Set("name", String(name))
+		proto.Set("message", String(""))
+	}
+	rt.method(rt.protos["Error"], "toString", 0, func(this Value, args []Value) (Value, error) {
+		o, ok := this.(*Object)
+		if !ok {
+			return nil, typeError("Error.prototype.toString called on %s", describeValue(this))
+		}
+		return String(errorString(o)), nil
+	})
+}
+
+// thrown converts an exception into the value a promise is rejected with, an error object
+// Exceptions caused by the host, like a limit or a cancelled context, cannot be handled by
+// the program and are returned as they are
+func (rt *Runtime) thrown(err error) (Value, error) {
+	e, ok := err.(*Error)
+	if !ok || e.Cause != nil {
+		return nil, err
+	}
+	o, allocErr := rt.newError(e.Name, e.Message)
+	if allocErr != nil {
+		return nil, allocErr
+	}
+	return o, nil
+}
+
+// unhandledRejection is the error reported for a promise rejected without a handler:
+// the error it was rejected with, or an Error describing any other value
+func unhandledRejection(reason Value) *Error {
+	if o, ok := reason.(*Object); ok {
+		if _, ok := o.data.(errorData); ok {
+			name, message, _ := strings.Cut(errorString(o), ": ")
+			return &Error{Name: name, Message: message, Cause: ErrUnhandledRejection}
+		}
+	}
+	return &Error{Name: "Error", Message: "Unhandled promise rejection: " + Inspect(reason), Cause: ErrUnhandledRejection}
+}
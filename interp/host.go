@@ -0,0 +1,519 @@
+package interp
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeType is the reflect type of time.Time, converted to and from Date
+var timeType = reflect.TypeFor[time.Time]()
+
+// valueType is the reflect type of the Value interface
+var valueType = reflect.TypeFor[Value]()
+
+// errorType is the reflect type of the error interface
+var errorType = reflect.TypeFor[error]()
+
+// ToValue converts a Go value to a JavaScript value, so it can be given to a script
+//   - nil, nil pointers, slices and maps become null, and a Value is kept as it is
+//   - bools, numbers and strings become the matching primitive
+//   - slices and arrays become arrays, converted element by element
+//   - maps with string or integer keys and structs become objects; struct fields are
+//     named after their js tag, or the field name, and a tag of "-" leaves the field out
+//   - a time.Time becomes a Date in its location
+//   - pointers and interfaces are converted as what they point to
+//   - functions become native functions converting their arguments with ExportTo and
+//     their results with ToValue; a last result of type error is thrown as an Error
+//
+// Other kinds, like channels and complex numbers, cannot be converted, and neither can
+// values that contain themselves, like a map holding itself
+func ToValue(x any) (Value, error) {
+	if x == nil {
+		return Null{}, nil
+	}
+	if v, ok := x.(Value); ok {
+		return v, nil
+	}
+	return toValue(reflect.ValueOf(x), map[visit]bool{})
+}
+
+// visit identifies a pointer, map or slice being converted by toValue
+type visit struct {
+	ptr uintptr
+	len int // Slices sharing an array from the same element differ by their length
+	typ reflect.Type
+}
+
+// toValue converts a reflect value to a JavaScript value
+// seen holds the pointers, maps and slices being converted, so that reaching one of them
+// again, a cycle that would recurse forever, is an error instead
+func toValue(rv reflect.Value, seen map[visit]bool) (Value, error) {
+	if rv.Type().Implements(valueType) && rv.Kind() != reflect.Interface {
+		return rv.Interface().(Value), nil
+	}
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if !rv.IsNil() {
+			key := visit{ptr: rv.Pointer(), typ: rv.Type()}
+			if rv.Kind() == reflect.Slice {
+				key.len = rv.Len()
+			}
+			if seen[key] {
+				return nil, &Error{Name: "TypeError", Message: "cannot convert cyclic " + rv.Type().String() + " to a JavaScript value"}
+			}
+			seen[key] = true
+			defer delete(seen, key)
+		}
+	}
+	if rv.Type() == timeType {
+		t := rv.Interface().(time.Time)
+		return &Object{Class: "Date", data: &date{ms: timeClip(float64(t.UnixMilli())), loc: t.Location()}}, nil
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return Boolean(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Number(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Number(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return Number(rv.Float()), nil
+	case reflect.String:
+		return String(rv.String()), nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return Null{}, nil
+		}
+		return toValue(rv.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return Null{}, nil
+		}
+		elements := make([]Value, rv.Len())
+		for i := range elements {
+			v, err := toValue(rv.Index(i), seen)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = v
+		}
+		return &Object{Class: "Array", elements: elements}, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return Null{}, nil
+		}
+		return mapToObject(rv, seen)
+	case reflect.Struct:
+		o := NewObject()
+		for _, field := range structFields(rv.Type()) {
+			v, err := toValue(rv.FieldByIndex(field.index), seen)
+			if err != nil {
+				return nil, err
+			}
+			o.Set(field.name, v)
+		}
+		return o, nil
+	case reflect.Func:
+		if rv.IsNil() {
+			return Null{}, nil
+		}
+		return nativeFunction(rv), nil
+	}
+	return nil, &Error{Name: "TypeError", Message: "cannot convert " + rv.Type().String() + " to a JavaScript value"}
+}
+
+// mapToObject converts a map to an object whose properties are in the order of the sorted keys
+func mapToObject(rv reflect.Value, seen map[visit]bool) (Value, error) {
+	keys := make([]string, 0, rv.Len())
+	values := map[string]reflect.Value{}
+	iter := rv.MapRange()
+	for iter.Next() {
+		var key string
+		switch k := iter.Key(); k.Kind() {
+		case reflect.String:
+			key = k.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			key = strconv.FormatInt(k.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			key = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return nil, &Error{Name: "TypeError", Message: "cannot convert " + rv.Type().String() + " to a JavaScript value"}
+		}
+		keys = append(keys, key)
+		values[key] = iter.Value()
+	}
+	slices.Sort(keys)
+	o := NewObject()
+	for _, key := range keys {
+		v, err := toValue(values[key], seen)
+		if err != nil {
+			return nil, err
+		}
+		o.Set(key, v)
+	}
+	return o, nil
+}
+
+// field is an exported struct field and the property it maps to
+type field struct {
+	name  string
+	index []int
+}
+
+// structFields lists the fields of a struct type converted to properties, those of embedded
+// structs included as if they were declared in the outer one
+// Embedded pointers are ordinary fields, as they may be nil
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous && f.Type.Kind() == reflect.Struct || throughPointer(t, f.Index) {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("js"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		fields = append(fields, field{name, f.Index})
+	}
+	return fields
+}
+
+// throughPointer reports whether the field at index is promoted from an embedded pointer
+func throughPointer(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		t = t.Field(i).Type
+		if t.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+	return false
+}
+
+// nativeFunction wraps a Go function in a function value
+func nativeFunction(fn reflect.Value) *Function {
+	t := fn.Type()
+	return NewNativeFunction("", func(this Value, args []Value) (Value, error) {
+		// Missing arguments are undefined, extra ones are dropped unless the function is variadic
+		fixed := t.NumIn()
+		if t.IsVariadic() {
+			fixed--
+		}
+		in := make([]reflect.Value, 0, len(args))
+		for i := 0; i < fixed || t.IsVariadic() && i < len(args); i++ {
+			pt := t.In(min(i, t.NumIn()-1))
+			if i >= fixed {
+				pt = pt.Elem()
+			}
+			var arg Value = Undefined{}
+			if i < len(args) {
+				arg = args[i]
+			}
+			v, err := exportTo(arg, pt, "argument "+strconv.Itoa(i+1))
+			if err != nil {
+				return nil, err
+			}
+			in = append(in, v)
+		}
+		out := fn.Call(in)
+
+		if n := len(out); n > 0 && t.Out(n-1) == errorType {
+			if err, _ := out[n-1].Interface().(error); err != nil {
+				if e, ok := err.(*Error); ok {
+					return nil, e
+				}
+				return nil, &Error{Name: "Error", Message: err.Error()}
+			}
+			out = out[:n-1]
+		}
+		if len(out) == 0 {
+			return Undefined{}, nil
+		}
+		return toValue(out[0], map[visit]bool{})
+	})
+}
+
+// Export converts a JavaScript value to the natural Go value: nil for undefined and null,
+// bool, float64 and string for primitives, []any for arrays, map[string]any for other
+// objects, time.Time for dates, and func(args ...any) (any, error) for functions
+// An invalid date exports as the zero time.Time
+func Export(v Value) any {
+	return export(v, map[*Object]any{})
+}
+
+// export converts a value, reusing the results for objects met before so cycles terminate
+func export(v Value, seen map[*Object]any) any {
+	switch v := v.(type) {
+	case Boolean:
+		return bool(v)
+	case Number:
+		return float64(v)
+	case String:
+		return string(v)
+	case *Function:
+		return func(args ...any) (any, error) {
+			values := make([]Value, len(args))
+			for i, arg := range args {
+				var err error
+				if values[i], err = ToValue(arg); err != nil {
+					return nil, err
+				}
+			}
+			result, err := v.Call(Undefined{}, values...)
+			if err != nil {
+				return nil, err
+			}
+			return Export(result), nil
+		}
+	case *Object:
+		if x, ok := seen[v]; ok {
+			return x
+		}
+		if d, ok := v.data.(*date); ok {
+			return d.export()
+		}
+		if v.Class == "Array" {
+			a := make([]any, len(v.elements))
+			seen[v] = a
+			for i, e := range v.elements {
+				a[i] = export(e, seen)
+			}
+			return a
+		}
+		m := make(map[string]any, len(v.keys))
+		seen[v] = m
+		for _, key := range v.keys {
+			m[key] = export(v.properties[key], seen)
+		}
+		return m
+	}
+	return nil
+}
+
+// ExportTo converts a JavaScript value to the Go variable target points to, following the
+// conversions of ToValue in reverse
+// Numbers stored in integer types must be whole and in range; undefined and null give the
+// zero value, and properties missing from an object leave their field as it is
+// Errors are TypeErrors naming where the mismatch is, such as "config.port"
+func ExportTo(v Value, target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &Error{Name: "TypeError", Message: "ExportTo needs a non-nil pointer, not " + fmt.Sprintf("%T", target)}
+	}
+	x, err := exportTo(v, rv.Elem().Type(), "value")
+	if err != nil {
+		return err
+	}
+	if x.IsValid() {
+		rv.Elem().Set(x)
+	}
+	return nil
+}
+
+// exportTo converts a value to a Go value of type t; path names the value in errors
+func exportTo(v Value, t reflect.Type, path string) (reflect.Value, error) {
+	if t == valueType {
+		return reflect.ValueOf(&v).Elem(), nil
+	}
+	if isNullish(v) {
+		return reflect.Zero(t), nil
+	}
+	mismatch := func() (reflect.Value, error) {
+		return reflect.Value{}, &Error{Name: "TypeError", Message: fmt.Sprintf("%s: cannot convert %s to %s", path, describeValue(v), t)}
+	}
+
+	if t == timeType {
+		o, ok := v.(*Object)
+		if !ok {
+			return mismatch()
+		}
+		d, ok := o.data.(*date)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(d.export()), nil
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			x := Export(v)
+			if x == nil {
+				return reflect.Zero(t), nil
+			}
+			return reflect.ValueOf(x), nil
+		}
+		if rv := reflect.ValueOf(v); rv.Type().Implements(t) {
+			return rv.Convert(t), nil
+		}
+		return mismatch()
+	case reflect.Bool:
+		b, ok := v.(Boolean)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(bool(b)).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		number, ok := v.(Number)
+		n := float64(number)
+		if !ok || n != math.Trunc(n) || math.IsInf(n, 0) {
+			return mismatch()
+		}
+		rv := reflect.New(t).Elem()
+		if t.Kind() >= reflect.Uint {
+			if n < 0 || n >= math.Exp2(float64(t.Bits())) {
+				return mismatch()
+			}
+			rv.SetUint(uint64(n))
+		} else {
+			if n < -math.Exp2(float64(t.Bits()-1)) || n >= math.Exp2(float64(t.Bits()-1)) {
+				return mismatch()
+			}
+			rv.SetInt(int64(n))
+		}
+		return rv, nil
+	case reflect.Float32, reflect.Float64:
+		n, ok := v.(Number)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(float64(n)).Convert(t), nil
+	case reflect.String:
+		s, ok := v.(String)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(string(s)).Convert(t), nil
+	case reflect.Pointer:
+		x, err := exportTo(v, t.Elem(), path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(x)
+		return p, nil
+	case reflect.Slice, reflect.Array:
+		a, ok := v.(*Object)
+		if !ok || a.Class != "Array" || t.Kind() == reflect.Array && len(a.elements) != t.Len() {
+			return mismatch()
+		}
+		rv := reflect.New(t).Elem()
+		if t.Kind() == reflect.Slice {
+			rv = reflect.MakeSlice(t, len(a.elements), len(a.elements))
+		}
+		for i, e := range a.elements {
+			x, err := exportTo(e, t.Elem(), path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			rv.Index(i).Set(x)
+		}
+		return rv, nil
+	case reflect.Map:
+		o, ok := v.(*Object)
+		if !ok || t.Key().Kind() != reflect.String {
+			return mismatch()
+		}
+		rv := reflect.MakeMapWithSize(t, len(o.keys))
+		for _, key := range o.Keys() {
+			x, err := exportTo(o.Get(key), t.Elem(), path+"."+key)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			rv.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), x)
+		}
+		return rv, nil
+	case reflect.Struct:
+		o, ok := v.(*Object)
+		if !ok {
+			return mismatch()
+		}
+		rv := reflect.New(t).Elem()
+		for _, f := range structFields(t) {
+			if _, ok := o.properties[f.name]; !ok {
+				continue
+			}
+			x, err := exportTo(o.properties[f.name], t.FieldByIndex(f.index).Type, path+"."+f.name)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			rv.FieldByIndex(f.index).Set(x)
+		}
+		return rv, nil
+	case reflect.Func:
+		f, ok := v.(*Function)
+		if !ok {
+			return mismatch()
+		}
+		return goFunction(f, t), nil
+	}
+	return mismatch()
+}
+
+// goFunction wraps a function value in a Go function of type t
+// Arguments are converted with ToValue and the result with ExportTo; if the last result of t
+// is an error, it receives the errors of the call and conversions, otherwise they panic
+func goFunction(f *Function, t reflect.Type) reflect.Value {
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, t.NumOut())
+		for i := range out {
+			out[i] = reflect.Zero(t.Out(i))
+		}
+		fail := func(err error) []reflect.Value {
+			if len(out) == 0 || t.Out(len(out)-1) != errorType {
+				panic(err)
+			}
+			out[len(out)-1] = reflect.ValueOf(&err).Elem()
+			return out
+		}
+
+		if t.IsVariadic() {
+			last := in[len(in)-1]
+			in = in[:len(in)-1]
+			for i := range last.Len() {
+				in = append(in, last.Index(i))
+			}
+		}
+		args := make([]Value, len(in))
+		for i, arg := range in {
+			v, err := toValue(arg, map[visit]bool{})
+			if err != nil {
+				return fail(err)
+			}
+			args[i] = v
+		}
+		result, err := f.Call(Undefined{}, args...)
+		if err != nil {
+			return fail(err)
+		}
+		if len(out) > 0 && t.Out(0) != errorType {
+			x, err := exportTo(result, t.Out(0), "result")
+			if err != nil {
+				return fail(err)
+			}
+			out[0] = x
+		}
+		return out
+	})
+}
+
+// describeValue names a value in conversion errors: its type, and its text for primitives
+func describeValue(v Value) string {
+	switch v := v.(type) {
+	case *Object:
+		if v.Class == "Array" {
+			return "array"
+		}
+		return "object"
+	case *Function:
+		return "function"
+	}
+	return v.TypeOf() + " " + Inspect(v)
+}
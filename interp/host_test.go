@@ -0,0 +1,58 @@
+package interp
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+type node struct {
+	Name string
+	Next *node
+}
+
+func TestToValueCycles(t *testing.T) {
+	m := map[string]any{}
+	m["self"] = m
+	s := []any{nil}
+	s[0] = s
+	n := &node{Name: "a"}
+	n.Next = &node{Name: "b", Next: n}
+
+	for name, x := range map[string]any{"map": m, "slice": s, "pointer": n} {
+		_, err := ToValue(x)
+		if err == nil || !strings.Contains(err.Error(), "cyclic") {
+			t.Errorf("%s: ToValue error = %v, want a cycle error", name, err)
+		}
+	}
+}
+
+func TestToValueShared(t *testing.T) {
+	// The same value reached twice without a cycle converts both times
+	shared := &node{Name: "shared"}
+	v, err := ToValue([]*node{shared, shared})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Inspect(v), "[ { Name: 'shared', Next: null }, { Name: 'shared', Next: null } ]"; got != want {
+		t.Errorf("Inspect = %s, want %s", got, want)
+	}
+}
+
+func TestNilValue(t *testing.T) {
+	if got := ToString(nil); got != "undefined" {
+		t.Errorf("ToString(nil) = %q, want \"undefined\"", got)
+	}
+	if got := Inspect(nil); got != "undefined" {
+		t.Errorf("Inspect(nil) = %q, want \"undefined\"", got)
+	}
+	if got := ToNumber(nil); !math.IsNaN(got) {
+		t.Errorf("ToNumber(nil) = %v, want NaN", got)
+	}
+	if ToBoolean(nil) {
+		t.Error("ToBoolean(nil) = true, want false")
+	}
+	if _, ok := ToPrimitive(nil).(Undefined); !ok {
+		t.Errorf("ToPrimitive(nil) = %#v, want Undefined", ToPrimitive(nil))
+	}
+}
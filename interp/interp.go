@@ -0,0 +1,879 @@
+// Package interp runs JavaScript programs by walking their AST
+// Values follow JavaScript semantics: the coercions of ==, + and the relational operators,
+// closures, default parameters, hoisting of var and function declarations and the temporal
+// dead zone of let and const
+package interp
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"goast/ast"
+	"goast/codegen"
+	"goast/constant"
+	"goast/parser"
+)
+
+// Options configures an Interpreter
+// A nil *Options is valid
+type Options struct {
+	// Echo, when set, receives the value of every top-level expression statement,
+	// the way a console shows results
+	Echo func(expr ast.Node, result Value)
+
+	// Stdout and Stderr receive the output of console.log and console.error,
+	// os.Stdout and os.Stderr when nil
+	Stdout, Stderr io.Writer
+
+	// Limits bounds the resources programs may use
+	Limits Limits
+
+	// Now is the clock of Date, time.Now when nil; a fixed clock makes programs deterministic
+	Now func() time.Time
+
+	// Location is the time zone of Date's local methods, UTC when nil
+	Location *time.Location
+}
+
+// Interpreter runs programs in a global scope kept from one run to the next
+// Declarations made by a program stay visible to the programs, calls and expressions that follow
+type Interpreter struct {
+	opts   Options
+	global *environment
+	src    *source // Source of the code running, for error positions
+	depth  int     // Nesting of function calls
+	meter  *Meter
+	rt     *Runtime
+	active bool // Set while code runs, so calls back from native functions share its limits
+}
+
+// source is the text a program was parsed from
+type source struct {
+	filename string
+	text     string
+	lines    ast.Lines
+}
+
+// newSource indexes the lines of a source text
+func newSource(filename, text string) *source {
+	return &source{filename: filename, text: text, lines: ast.NewLines(text)}
+}
+
+// Error is a JavaScript exception raised while running a program, such as a TypeError
+type Error struct {
+	Name     string // Error class: ReferenceError, TypeError, RangeError or SyntaxError
+	Message  string
+	Filename string
+	Line     int // 1-based, 0 if the position is unknown
+	Column   int // 1-based
+
+	// Cause is the Go error behind the exception, such as ErrStepLimit or context.Canceled
+	Cause error
+}
+
+func (e *Error) Error() string {
+	message := e.Name + ": " + e.Message
+	if e.Line == 0 {
+		return message
+	}
+	position := fmt.Sprintf("%d:%d", e.Line, e.Column)
+	if e.Filename != "" {
+		position = e.Filename + ":" + position
+	}
+	return position + ": " + message
+}
+
+// Unwrap returns the cause of the error, for errors.Is and errors.As
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// New creates an interpreter with a fresh global scope
+func New(opts *Options) *Interpreter {
+	in := &Interpreter{global: newEnvironment(nil)}
+	if opts != nil {
+		in.opts = *opts
+	}
+	in.meter = NewMeter(in.opts.Limits)
+	in.global.define("undefined", "const", Undefined{})
+	in.global.define("NaN", "const", Number(math.NaN()))
+	in.global.define("Infinity", "const", Number(math.Inf(1)))
+	in.rt = NewRuntime(opts, in.meter)
+	for name, v := range in.rt.Globals {
+		in.global.define(name, "var", v)
+	}
+	return in
+}
+
+// RunSource parses and runs a program, then returns its completion value like Run
+// Errors carry positions in src; a program with syntax errors never starts running and
+// the parser's ErrorList is returned
+func (in *Interpreter) RunSource(filename, src string) (Value, error) {
+	program, err := parser.ParseFile(filename, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	return in.run(program, newSource(filename, src))
+}
+
+// Run runs a program and returns its completion value, the value of the last expression
+// statement executed at top level, or Undefined
+// Errors are *Error values; the positions they carry are unknown for programs run this way,
+// RunSource knows the source and reports them
+func (in *Interpreter) Run(program *ast.Program) (Value, error) {
+	return in.run(program, nil)
+}
+
+// run runs a program parsed from src
+func (in *Interpreter) run(program *ast.Program, src *source) (_ Value, err error) {
+	defer in.begin()(&err)
+	defer in.enter(src)()
+	if err := in.hoist(program.Body, in.global, in.global); err != nil {
+		return nil, err
+	}
+
+	var result Value = Undefined{}
+	for _, stmt := range program.Body {
+		if stmt, ok := stmt.(*ast.ExpressionStatement); ok {
+			v, err := in.eval(stmt.Expression, in.global)
+			if err != nil {
+				return nil, err
+			}
+			if in.opts.Echo != nil {
+				in.opts.Echo(stmt.Expression, v)
+			}
+			result = v
+			continue
+		}
+		c, err := in.exec(stmt, in.global)
+		if err != nil {
+			return nil, err
+		}
+		if c.returned {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Eval evaluates an expression in the global scope
+func (in *Interpreter) Eval(expr ast.Node) (_ Value, err error) {
+	defer in.begin()(&err)
+	return in.eval(expr, in.global)
+}
+
+// EvalSource parses and evaluates an expression in the global scope
+func (in *Interpreter) EvalSource(src string) (_ Value, err error) {
+	expr, err := parser.ParseExpression(src, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer in.begin()(&err)
+	defer in.enter(newSource("", src))()
+	return in.eval(expr, in.global)
+}
+
+// Call calls a function with the given arguments
+func (in *Interpreter) Call(fn Value, args ...Value) (Value, error) {
+	f, ok := fn.(*Function)
+	if !ok {
+		return nil, in.throw(nil, "TypeError", "%s is not a function", Inspect(fn))
+	}
+	return in.call(nil, f, Undefined{}, args)
+}
+
+// Get returns the value of a global variable, false if it does not exist or is not initialized yet
+func (in *Interpreter) Get(name string) (Value, bool) {
+	b := in.global.vars[name]
+	if b == nil || !b.initialized {
+		return nil, false
+	}
+	return b.value, true
+}
+
+// Set assigns a global variable, declaring it with var if needed
+func (in *Interpreter) Set(name string, v Value) {
+	if b := in.global.vars[name]; b != nil {
+		b.value, b.initialized = v, true
+		return
+	}
+	in.global.define(name, "var", v)
+}
+
+// Define converts a Go value with ToValue and assigns it to a global variable, declaring it
+// with var if needed
+// A Go function defined this way takes the name of the variable
+func (in *Interpreter) Define(name string, x any) error {
+	v, err := ToValue(x)
+	if err != nil {
+		return err
+	}
+	if f, ok := v.(*Function); ok && f.Name == "" {
+		f.Name = name
+	}
+	in.Set(name, v)
+	return nil
+}
+
+// Invoke calls the global function name, such as one declared by a FunctionDeclaration,
+// with arguments converted by ToValue
+func (in *Interpreter) Invoke(name string, args ...any) (Value, error) {
+	fn, ok := in.Get(name)
+	if !ok {
+		return nil, in.throw(nil, "ReferenceError", "%s is not defined", name)
+	}
+	values := make([]Value, len(args))
+	for i, arg := range args {
+		var err error
+		if values[i], err = ToValue(arg); err != nil {
+			return nil, err
+		}
+	}
+	return in.Call(fn, values...)
+}
+
+// SetLimits replaces the limits of Options, for the runs and calls that follow
+func (in *Interpreter) SetLimits(limits Limits) {
+	in.opts.Limits = limits
+	in.meter.SetLimits(limits)
+}
+
+// begin starts the counters of the limits over when code starts running from Go, and
+// returns the function marking the end of the run, which is given the error of the run
+// Code run by a native function during a run counts towards that run, and so do the
+// promise jobs the run queued, which run at its end; their error is the run's if it had none
+func (in *Interpreter) begin() func(err *error) {
+	if in.active {
+		return func(*error) {}
+	}
+	in.meter.Reset()
+	in.active = true
+	return func(err *error) {
+		defer func() { in.active = false }()
+		if jobsErr := in.rt.RunMicrotasks(); *err == nil {
+			*err = jobsErr
+		}
+	}
+}
+
+// enter makes src the source of the running code and returns the function restoring the previous one
+func (in *Interpreter) enter(src *source) func() {
+	previous := in.src
+	in.src = src
+	return func() { in.src = previous }
+}
+
+// throw builds an Error located at node
+func (in *Interpreter) throw(node ast.Node, name, format string, args ...any) *Error {
+	e := &Error{Name: name, Message: fmt.Sprintf(format, args...)}
+	if in.src != nil {
+		e.Filename = in.src.filename
+		if node != nil && node.Range().IsValid() && node.Range().Start < len(in.src.text) {
+			e.Line, e.Column = in.src.lines.Position(node.Range().Start)
+		}
+	}
+	return e
+}
+
+// locate gives an error raised without a position, by a native function or a property access,
+// the position of node
+func (in *Interpreter) locate(err error, node ast.Node) error {
+	if e, ok := err.(*Error); ok && e.Line == 0 && e.Filename == "" {
+		located := in.throw(node, e.Name, "%s", e.Message)
+		e.Filename, e.Line, e.Column = located.Filename, located.Line, located.Column
+	}
+	return err
+}
+
+// environment is a scope holding variable bindings
+type environment struct {
+	parent *environment
+	vars   map[string]*binding
+}
+
+// binding is a variable in an environment
+type binding struct {
+	value       Value
+	kind        string // "var", "let", "const", "function" or "param"
+	initialized bool   // False in the temporal dead zone of let and const
+}
+
+func newEnvironment(parent *environment) *environment {
+	return &environment{parent: parent, vars: map[string]*binding{}}
+}
+
+// lookup finds the binding of a name in this environment or an enclosing one
+func (e *environment) lookup(name string) *binding {
+	for ; e != nil; e = e.parent {
+		if b, ok := e.vars[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+// define creates or replaces an initialized binding
+func (e *environment) define(name, kind string, v Value) {
+	e.vars[name] = &binding{value: v, kind: kind, initialized: true}
+}
+
+// declare creates a binding that cannot be used before its declaration runs
+func (e *environment) declare(name, kind string) {
+	e.vars[name] = &binding{value: Undefined{}, kind: kind}
+}
+
+// hoist declares the names a statement list introduces before any of it runs
+// var declarations, including those inside nested blocks, belong to the function scope fn,
+// nil for blocks since their function hoisted them already; functions, let and const belong
+// to the block itself
+// Creating the functions counts towards the allocation limit
+func (in *Interpreter) hoist(list []ast.Node, fn, block *environment) error {
+	if fn != nil {
+		hoistVars(list, fn)
+	}
+	for _, stmt := range list {
+		switch stmt := stmt.(type) {
+		case *ast.VariableDeclaration:
+			if stmt.Kind != "var" {
+				block.declare(stmt.Name, stmt.Kind)
+			}
+		case *ast.FunctionDeclaration:
+			if err := in.meter.Allocate(1); err != nil {
+				return in.locate(err, stmt)
+			}
+			block.define(stmt.Name, "function", in.closure(stmt, block))
+		}
+	}
+	return nil
+}
+
+// hoistVars declares the var declarations of a statement list and its blocks as undefined
+// Redeclaring keeps the current value
+func hoistVars(list []ast.Node, fn *environment) {
+	for _, stmt := range list {
+		switch stmt := stmt.(type) {
+		case *ast.VariableDeclaration:
+			if _, ok := fn.vars[stmt.Name]; !ok && stmt.Kind == "var" {
+				fn.define(stmt.Name, "var", Undefined{})
+			}
+		case *ast.IfStatement:
+			hoistVars(stmt.Consequent, fn)
+		}
+	}
+}
+
+// closure creates the function value of a declaration, capturing env
+func (in *Interpreter) closure(decl *ast.FunctionDeclaration, env *environment) *Function {
+	return &Function{Object: Object{Class: "Function"}, Name: decl.Name, decl: decl, env: env, src: in.src, in: in}
+}
+
+// completion is how a statement finished: normally, or by returning a value
+type completion struct {
+	returned bool
+	value    Value
+}
+
+// execList runs statements in order until one returns
+func (in *Interpreter) execList(list []ast.Node, env *environment) (completion, error) {
+	for _, stmt := range list {
+		c, err := in.exec(stmt, env)
+		if err != nil || c.returned {
+			return c, err
+		}
+	}
+	return completion{}, nil
+}
+
+// exec runs one statement
+func (in *Interpreter) exec(node ast.Node, env *environment) (completion, error) {
+	if err := in.meter.Step(); err != nil {
+		return completion{}, in.locate(err, node)
+	}
+	switch n := node.(type) {
+	case *ast.Comment, *ast.FunctionDeclaration:
+		// Functions were created when their block was entered
+
+	case *ast.VariableDeclaration:
+		var v Value = Undefined{}
+		if n.Value != nil {
+			var err error
+			if v, err = in.eval(n.Value, env); err != nil {
+				return completion{}, err
+			}
+		}
+		if n.Kind == "var" {
+			if n.Value != nil {
+				env.lookup(n.Name).value = v
+			}
+			break
+		}
+		b := env.vars[n.Name]
+		if b == nil { // Not hoisted, the statement was run on its own
+			env.declare(n.Name, n.Kind)
+			b = env.vars[n.Name]
+		}
+		b.value, b.initialized = v, true
+
+	case *ast.IfStatement:
+		test, err := in.eval(n.Test, env)
+		if err != nil || !ToBoolean(test) {
+			return completion{}, err
+		}
+		block := newEnvironment(env)
+		if err := in.hoist(n.Consequent, nil, block); err != nil {
+			return completion{}, err
+		}
+		return in.execList(n.Consequent, block)
+
+	case *ast.ReturnStatement:
+		var v Value = Undefined{}
+		if n.Argument != nil {
+			var err error
+			if v, err = in.eval(n.Argument, env); err != nil {
+				return completion{}, err
+			}
+		}
+		return completion{returned: true, value: v}, nil
+
+	case *ast.ExpressionStatement:
+		_, err := in.eval(n.Expression, env)
+		return completion{}, err
+
+	case *ast.ErrorNode:
+		return completion{}, in.throw(n, "SyntaxError", "%s", n.Message)
+
+	default:
+		return completion{}, in.throw(node, "SyntaxError", "unsupported statement %s", node.Type())
+	}
+	return completion{}, nil
+}
+
+// eval evaluates an expression
+func (in *Interpreter) eval(node ast.Node, env *environment) (Value, error) {
+	if err := in.meter.Step(); err != nil {
+		return nil, in.locate(err, node)
+	}
+	switch n := node.(type) {
+	case *ast.NumericLiteral:
+		return Number(constant.StringToNumber(n.Value)), nil
+	case *ast.StringLiteral:
+		return String(n.Value), nil
+	case *ast.BooleanLiteral:
+		return Boolean(n.Value), nil
+	case *ast.NullLiteral:
+		return Null{}, nil
+
+	case *ast.Identifier:
+		b := env.lookup(n.Name)
+		if b == nil {
+			return nil, in.throw(n, "ReferenceError", "%s is not defined", n.Name)
+		}
+		if !b.initialized {
+			return nil, in.throw(n, "ReferenceError", "Cannot access '%s' before initialization", n.Name)
+		}
+		return b.value, nil
+
+	case *ast.BinaryExpression:
+		if n.Operator == "=" {
+			return in.assign(n, env)
+		}
+		left, err := in.eval(n.Left, env)
+		if err != nil {
+			return nil, err
+		}
+		right, err := in.eval(n.Right, env)
+		if err != nil {
+			return nil, err
+		}
+		v, err := BinaryOperation(n.Operator, left, right)
+		if err != nil {
+			return nil, in.throw(n, "SyntaxError", "%s", err)
+		}
+		if s, ok := v.(String); ok && n.Operator == "+" {
+			if err := in.meter.String(s); err != nil {
+				return nil, in.locate(err, n)
+			}
+		}
+		return v, nil
+
+	case *ast.MemberExpression:
+		object, key, err := in.member(n, env)
+		if err != nil {
+			return nil, err
+		}
+		v, err := in.rt.GetProperty(object, key)
+		if err != nil {
+			return nil, in.locate(err, n)
+		}
+		return v, nil
+
+	case *ast.CallExpression:
+		// A method call passes the object it was read from as this
+		var callee, this Value = nil, Undefined{}
+		var err error
+		if member, ok := n.Callee.(*ast.MemberExpression); ok {
+			var key string
+			if this, key, err = in.member(member, env); err != nil {
+				return nil, err
+			}
+			if callee, err = in.rt.GetProperty(this, key); err != nil {
+				return nil, in.locate(err, member)
+			}
+		} else if callee, err = in.eval(n.Callee, env); err != nil {
+			return nil, err
+		}
+		args, err := in.evalList(n.Arguments, env)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := callee.(*Function)
+		if !ok {
+			return nil, in.throw(n, "TypeError", "%s is not a function", codegen.Generate(n.Callee, nil))
+		}
+		return in.call(n, f, this, args)
+
+	case *ast.NewExpression:
+		callee, err := in.eval(n.Callee, env)
+		if err != nil {
+			return nil, err
+		}
+		args, err := in.evalList(n.Arguments, env)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := callee.(*Function)
+		if !ok || f.Construct == nil {
+			return nil, in.throw(n.Callee, "TypeError", "%s is not a constructor", codegen.Generate(n.Callee, nil))
+		}
+		return in.construct(n, f, args)
+
+	case *ast.ArrayExpression:
+		elements, err := in.evalList(n.Elements, env)
+		if err != nil {
+			return nil, err
+		}
+		a, err := in.rt.array(elements)
+		if err != nil {
+			return nil, in.locate(err, n)
+		}
+		return a, nil
+
+	case *ast.ObjectExpression:
+		o, err := in.rt.object()
+		if err != nil {
+			return nil, in.locate(err, n)
+		}
+		for _, p := range n.Properties {
+			v, err := in.eval(p.Value, env)
+			if err != nil {
+				return nil, err
+			}
+			if err := in.meter.Grow(o, p.Key, v); err != nil {
+				return nil, in.locate(err, &p)
+			}
+			o.Set(p.Key, v)
+		}
+		return o, nil
+
+	case *ast.InvalidExpression:
+		return nil, in.throw(n, "SyntaxError", "invalid expression")
+	}
+	return nil, in.throw(node, "SyntaxError", "unsupported expression %s", node.Type())
+}
+
+// member evaluates the object of a member expression and the name of the property it
+// accesses, converting a computed key to a string
+func (in *Interpreter) member(n *ast.MemberExpression, env *environment) (Value, string, error) {
+	object, err := in.eval(n.Object, env)
+	if err != nil {
+		return nil, "", err
+	}
+	if n.Index == nil {
+		return object, n.Property, nil
+	}
+	key, err := in.eval(n.Index, env)
+	if err != nil {
+		return nil, "", err
+	}
+	return object, ToString(key), nil
+}
+
+// evalList evaluates a list of expressions in order, arguments or array elements
+func (in *Interpreter) evalList(list []ast.Node, env *environment) ([]Value, error) {
+	values := make([]Value, len(list))
+	for i, node := range list {
+		var err error
+		if values[i], err = in.eval(node, env); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// assign evaluates an assignment
+// Assigning an undeclared name creates a global variable, as in sloppy mode JavaScript
+func (in *Interpreter) assign(n *ast.BinaryExpression, env *environment) (Value, error) {
+	if member, ok := n.Left.(*ast.MemberExpression); ok {
+		object, key, err := in.member(member, env)
+		if err != nil {
+			return nil, err
+		}
+		v, err := in.eval(n.Right, env)
+		if err != nil {
+			return nil, err
+		}
+		if err := in.meter.Grow(object, key, v); err != nil {
+			return nil, in.locate(err, member)
+		}
+		if err := SetProperty(object, key, v); err != nil {
+			return nil, in.locate(err, member)
+		}
+		return v, nil
+	}
+	id, ok := n.Left.(*ast.Identifier)
+	if !ok {
+		return nil, in.throw(n.Left, "SyntaxError", "Invalid left-hand side in assignment")
+	}
+	v, err := in.eval(n.Right, env)
+	if err != nil {
+		return nil, err
+	}
+	b := env.lookup(id.Name)
+	switch {
+	case b == nil:
+		in.global.define(id.Name, "var", v)
+	case !b.initialized:
+		return nil, in.throw(id, "ReferenceError", "Cannot access '%s' before initialization", id.Name)
+	case b.kind == "const":
+		return nil, in.throw(id, "TypeError", "Assignment to constant variable.")
+	default:
+		b.value = v
+	}
+	return v, nil
+}
+
+// call runs a function with the given receiver and arguments, node is the call expression
+// if there is one
+// Missing arguments are undefined, and undefined arguments take the parameter's default value,
+// evaluated in the function's scope so it can refer to earlier parameters
+func (in *Interpreter) call(node ast.Node, f *Function, this Value, args []Value) (_ Value, err error) {
+	defer in.begin()(&err)
+	if err := in.meter.Call(in.depth); err != nil {
+		return nil, in.locate(err, node)
+	}
+	in.depth++
+	defer func() { in.depth-- }()
+	if f.Native != nil {
+		v, err := f.Native(this, args)
+		if err != nil {
+			return nil, in.locate(err, node)
+		}
+		return v, nil
+	}
+	defer in.enter(f.src)()
+
+	env := newEnvironment(f.env)
+	params := f.decl.Params
+	for _, param := range params {
+		env.declare(param.Name, "param") // Later parameters are in their dead zone for defaults
+	}
+	for i, param := range params {
+		var v Value = Undefined{}
+		if i < len(args) {
+			v = args[i]
+		}
+		if _, ok := v.(Undefined); ok && param.DefaultValue != nil {
+			var err error
+			if v, err = in.eval(param.DefaultValue, env); err != nil {
+				return nil, err
+			}
+		}
+		env.define(param.Name, "param", v)
+	}
+
+	if err := in.hoist(f.decl.Body, env, env); err != nil {
+		return nil, err
+	}
+	c, err := in.execList(f.decl.Body, env)
+	if err != nil {
+		return nil, err
+	}
+	if c.returned {
+		return c.value, nil
+	}
+	return Undefined{}, nil
+}
+
+// construct calls a constructor for a new expression
+func (in *Interpreter) construct(node ast.Node, f *Function, args []Value) (Value, error) {
+	if err := in.meter.Call(in.depth); err != nil {
+		return nil, in.locate(err, node)
+	}
+	in.depth++
+	defer func() { in.depth-- }()
+	v, err := f.Construct(args)
+	if err != nil {
+		return nil, in.locate(err, node)
+	}
+	return v, nil
+}
+
+// Inspect formats a value for display the way Node.js does: strings quoted, objects as
+// { key: value } and functions as [Function: name]
+func Inspect(v Value) string {
+	var sb strings.Builder
+	inspect(&sb, v, map[*Object]bool{})
+	return sb.String()
+}
+
+// inspect writes a value, printing objects already being written as [Circular]
+func inspect(sb *strings.Builder, v Value, seen map[*Object]bool) {
+	switch v := v.(type) {
+	case String:
+		sb.WriteString(codegen.Quote(string(v), '\''))
+	case Number:
+		if v == 0 && math.Signbit(float64(v)) {
+			sb.WriteString("-0")
+		} else {
+			sb.WriteString(ToString(v))
+		}
+	case *Function:
+		if v.Name == "" {
+			sb.WriteString("[Function (anonymous)]")
+		} else {
+			sb.WriteString("[Function: " + v.Name + "]")
+		}
+	case *Object:
+		if seen[v] {
+			sb.WriteString("[Circular]")
+			return
+		}
+		if v.Class == "Array" {
+			inspectArray(sb, v, seen)
+			return
+		}
+		if v.data != nil {
+			inspectInternal(sb, v, seen)
+			return
+		}
+		if len(v.keys) == 0 {
+			sb.WriteString("{}")
+			return
+		}
+		seen[v] = true
+		defer delete(seen, v)
+		sb.WriteString("{ ")
+		for i, key := range v.keys {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			if isIdentifierName(key) {
+				sb.WriteString(key)
+			} else {
+				sb.WriteString(codegen.Quote(key, '\''))
+			}
+			sb.WriteString(": ")
+			inspect(sb, v.properties[key], seen)
+		}
+		sb.WriteString(" }")
+	default:
+		sb.WriteString(ToString(v))
+	}
+}
+
+// inspectInternal writes the objects with an internal state the way Node.js does: dates as
+// their ISO string, errors as "Name: message", Map(1) { 'a' => 1 }, Set(1) { 1 } and
+// Promise { 1 }, Promise { <pending> } or Promise { <rejected> reason }
+func inspectInternal(sb *strings.Builder, o *Object, seen map[*Object]bool) {
+	seen[o] = true
+	defer delete(seen, o)
+	switch data := o.data.(type) {
+	case *date:
+		if math.IsNaN(data.ms) {
+			sb.WriteString("Invalid Date")
+		} else {
+			sb.WriteString(data.isoString())
+		}
+	case errorData:
+		sb.WriteString(errorString(o))
+	case *collection:
+		fmt.Fprintf(sb, "%s(%d) {", o.Class, data.size)
+		first := true
+		data.forEach(func(e entry) error {
+			if !first {
+				sb.WriteByte(',')
+			}
+			first = false
+			sb.WriteByte(' ')
+			inspect(sb, e.key, seen)
+			if o.Class == "Map" {
+				sb.WriteString(" => ")
+				inspect(sb, e.value, seen)
+			}
+			return nil
+		})
+		if !first {
+			sb.WriteByte(' ')
+		}
+		sb.WriteByte('}')
+	case *promise:
+		sb.WriteString("Promise { ")
+		switch data.state {
+		case "pending":
+			sb.WriteString("<pending>")
+		case "rejected":
+			sb.WriteString("<rejected> ")
+			inspect(sb, data.value, seen)
+		default:
+			inspect(sb, data.value, seen)
+		}
+		sb.WriteString(" }")
+	}
+}
+
+// inspectArray writes an array as [ 1, 'two' ], followed by its other properties
+func inspectArray(sb *strings.Builder, a *Object, seen map[*Object]bool) {
+	if len(a.elements) == 0 && len(a.keys) == 0 {
+		sb.WriteString("[]")
+		return
+	}
+	seen[a] = true
+	defer delete(seen, a)
+	sb.WriteString("[ ")
+	for i, v := range a.elements {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		inspect(sb, v, seen)
+	}
+	for i, key := range a.keys {
+		if i > 0 || len(a.elements) > 0 {
+			sb.WriteString(", ")
+		}
+		if isIdentifierName(key) {
+			sb.WriteString(key)
+		} else {
+			sb.WriteString(codegen.Quote(key, '\''))
+		}
+		sb.WriteString(": ")
+		inspect(sb, a.properties[key], seen)
+	}
+	sb.WriteString(" ]")
+}
+
+// isIdentifierName reports whether a property name can be written without quotes
+func isIdentifierName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		letter := r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
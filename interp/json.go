@@ -0,0 +1,371 @@
+package interp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"goast/constant"
+)
+
+// jsonObject creates the JSON object with parse and stringify
+func (rt *Runtime) jsonObject() *Object {
+	j := NewObject()
+	rt.method(j, "parse", 2, func(this Value, args []Value) (Value, error) {
+		text := ToString(arg(args, 0))
+		v, err := rt.parseJSON(text)
+		if err != nil {
+			return nil, err
+		}
+		reviver, ok := arg(args, 1).(*Function)
+		if !ok {
+			return v, nil
+		}
+		holder, err := rt.object()
+		if err != nil {
+			return nil, err
+		}
+		holder.Set("", v)
+		return rt.revive(reviver, holder, "")
+	})
+	rt.method(j, "stringify", 3, func(this Value, args []Value) (Value, error) {
+		s := &stringifier{rt: rt, seen: map[*Object]bool{}}
+		switch replacer := arg(args, 1).(type) {
+		case *Function:
+			s.replacer = replacer
+		case *Object:
+			if replacer.Class == "Array" {
+				s.allowed = map[string]bool{}
+				for _, v := range replacer.elements {
+					switch v.(type) {
+					case String, Number:
+						if key := ToString(v); !s.allowed[key] {
+							s.allowed[key] = true
+							s.keys = append(s.keys, key)
+						}
+					}
+				}
+			}
+		}
+		switch space := arg(args, 2).(type) {
+		case Number:
+			s.indent = strings.Repeat(" ", int(math.Max(0, math.Min(10, toInteger(space)))))
+		case String:
+			s.indent = string(space)
+			if utf8.RuneCountInString(s.indent) > 10 {
+				s.indent = string([]rune(s.indent)[:10])
+			}
+		}
+		holder, err := rt.object()
+		if err != nil {
+			return nil, err
+		}
+		holder.Set("", arg(args, 0))
+		ok, err := s.value(holder, "", arg(args, 0), "")
+		if err != nil || !ok {
+			return Undefined{}, err
+		}
+		return rt.string(s.sb.String())
+	})
+	return j
+}
+
+// parseJSON converts JSON text to values, keeping the order of object keys
+func (rt *Runtime) parseJSON(text string) (Value, error) {
+	d := json.NewDecoder(strings.NewReader(text))
+	d.UseNumber()
+	v, err := rt.decodeJSON(d)
+	if err == nil {
+		if _, extra := d.Token(); extra != io.EOF {
+			return nil, errorf("SyntaxError", "Unexpected non-whitespace character after JSON at position %d", d.InputOffset())
+		}
+		return v, nil
+	}
+	if _, ok := err.(*Error); ok {
+		return nil, err
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || err.Error() == "unexpected end of JSON input" {
+		return nil, errorf("SyntaxError", "Unexpected end of JSON input")
+	}
+	// Go explains what it expected after the character, JavaScript only names it
+	message := err.Error()
+	if rest, ok := strings.CutPrefix(message, "invalid character "); ok {
+		if end := strings.Index(rest[1:], "' "); end >= 0 {
+			rest = rest[:end+2]
+		}
+		message = "Unexpected token " + rest
+	}
+	return nil, errorf("SyntaxError", "%s in JSON at position %d", message, d.InputOffset())
+}
+
+// decodeJSON reads one JSON value from a decoder
+func (rt *Runtime) decodeJSON(d *json.Decoder) (Value, error) {
+	if err := rt.meter.Step(); err != nil {
+		return nil, err
+	}
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case nil:
+		return Null{}, nil
+	case bool:
+		return Boolean(token), nil
+	case json.Number:
+		return Number(constant.StringToNumber(string(token))), nil
+	case string:
+		return rt.string(token)
+	case json.Delim:
+		if token == '[' {
+			var elements []Value
+			for d.More() {
+				v, err := rt.decodeJSON(d)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, v)
+			}
+			if _, err := d.Token(); err != nil {
+				return nil, err
+			}
+			return rt.array(elements)
+		}
+		o, err := rt.object()
+		if err != nil {
+			return nil, err
+		}
+		for d.More() {
+			key, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := rt.decodeJSON(d)
+			if err != nil {
+				return nil, err
+			}
+			o.Set(key.(string), v)
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return o, nil
+	}
+	return nil, errorf("SyntaxError", "Unexpected token in JSON at position %d", d.InputOffset())
+}
+
+// revive calls the reviver of JSON.parse on the properties of a parsed value, innermost
+// first, replacing them with its results and deleting those it returns undefined for
+func (rt *Runtime) revive(reviver *Function, holder *Object, key string) (Value, error) {
+	v := holder.Get(key)
+	if o, ok := v.(*Object); ok {
+		for _, k := range o.Keys() {
+			x, err := rt.revive(reviver, o, k)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := x.(Undefined); ok && o.Class != "Array" {
+				o.delete(k)
+			} else {
+				o.Set(k, x)
+			}
+		}
+	}
+	return reviver.Call(holder, String(key), v)
+}
+
+// delete removes a property of an object that is not an array element
+func (o *Object) delete(key string) {
+	if _, ok := o.properties[key]; !ok {
+		return
+	}
+	delete(o.properties, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// stringifier holds the state of a JSON.stringify call
+type stringifier struct {
+	rt       *Runtime
+	sb       bytes.Buffer
+	replacer *Function       // Replacer function, if any
+	allowed  map[string]bool // Keys a replacer array allows, nil for all
+	keys     []string        // Those keys in order
+	indent   string          // Indentation of one level, empty for compact output
+	seen     map[*Object]bool
+}
+
+// value writes the JSON text of the property key of holder, whose value is v, at the
+// indentation prefix; it reports false for values JSON has no text for, like functions
+func (s *stringifier) value(holder *Object, key string, v Value, prefix string) (bool, error) {
+	if err := s.rt.meter.Step(); err != nil {
+		return false, err
+	}
+	if isObject(v) {
+		toJSON, err := s.rt.GetProperty(v, "toJSON")
+		if err != nil {
+			return false, err
+		}
+		if f, ok := toJSON.(*Function); ok {
+			if v, err = f.Call(v, String(key)); err != nil {
+				return false, err
+			}
+		}
+	}
+	if s.replacer != nil {
+		var err error
+		if v, err = s.replacer.Call(holder, String(key), v); err != nil {
+			return false, err
+		}
+	}
+	if err := s.rt.meter.reserve(float64(s.sb.Len())); err != nil {
+		return false, err
+	}
+	switch v := v.(type) {
+	case Null:
+		s.sb.WriteString("null")
+	case Boolean:
+		s.sb.WriteString(ToString(v))
+	case Number:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			s.sb.WriteString("null")
+		} else {
+			s.sb.WriteString(constant.NumberToString(float64(v)))
+		}
+	case String:
+		s.sb.WriteString(quoteJSON(string(v)))
+	case *Object:
+		if s.seen[v] {
+			return false, typeError("Converting circular structure to JSON")
+		}
+		s.seen[v] = true
+		defer delete(s.seen, v)
+		if v.Class == "Array" {
+			return true, s.array(v, prefix)
+		}
+		return true, s.object(v, prefix)
+	default:
+		return false, nil // undefined and functions
+	}
+	return true, nil
+}
+
+// array writes the elements of an array, null for those without JSON text
+func (s *stringifier) array(a *Object, prefix string) error {
+	if len(a.elements) == 0 {
+		s.sb.WriteString("[]")
+		return nil
+	}
+	inner := prefix + s.indent
+	s.sb.WriteByte('[')
+	for i := 0; i < len(a.elements); i++ {
+		if i > 0 {
+			s.sb.WriteByte(',')
+		}
+		s.newline(inner)
+		ok, err := s.value(a, strconv.Itoa(i), a.elements[i], inner)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.sb.WriteString("null")
+		}
+	}
+	s.newline(prefix)
+	s.sb.WriteByte(']')
+	return nil
+}
+
+// object writes the properties of an object, leaving out those without JSON text
+func (s *stringifier) object(o *Object, prefix string) error {
+	keys := o.Keys()
+	if s.allowed != nil {
+		keys = keys[:0:0]
+		for _, key := range s.keys {
+			if _, ok := o.own(key); ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	inner := prefix + s.indent
+	s.sb.WriteByte('{')
+	written := false
+	for _, key := range keys {
+		mark := s.sb.Len()
+		if written {
+			s.sb.WriteByte(',')
+		}
+		s.newline(inner)
+		s.sb.WriteString(quoteJSON(key))
+		s.sb.WriteByte(':')
+		if s.indent != "" {
+			s.sb.WriteByte(' ')
+		}
+		ok, err := s.value(o, key, o.Get(key), inner)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			s.sb.Truncate(mark) // Drop the key of a value without JSON text
+			continue
+		}
+		written = true
+	}
+	if written {
+		s.newline(prefix)
+	}
+	s.sb.WriteByte('}')
+	return nil
+}
+
+// newline starts a new line at an indentation when the output is indented
+func (s *stringifier) newline(prefix string) {
+	if s.indent != "" {
+		s.sb.WriteByte('\n')
+		s.sb.WriteString(prefix)
+	}
+}
+
+// quoteJSON quotes a string the way JSON.stringify does: double quotes, the usual short
+// escapes and \u escapes for the other control characters, nothing else escaped
+func quoteJSON(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				sb.WriteString(`\u00`)
+				sb.WriteByte("0123456789abcdef"[r>>4])
+				sb.WriteByte("0123456789abcdef"[r&0xF])
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
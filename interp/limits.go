@@ -0,0 +1,169 @@
+package interp
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+// defaultMaxCallDepth bounds the nesting of function calls when Limits leaves it unset
+// Runaway recursion raises a RangeError instead of overflowing the Go stack
+const defaultMaxCallDepth = 10000
+
+// maxCallDepth is the largest MaxCallDepth allowed, larger ones are lowered to it
+// A JavaScript call takes about a kilobyte of Go stack on either engine, so this nesting
+// stays well inside the 1 GB a goroutine may grow to; beyond it the Go runtime would
+// abort the process with a stack overflow, which cannot be recovered
+const maxCallDepth = 100000
+
+// Limits bounds the resources a program may use, for running untrusted scripts
+// A zero field means no limit, except MaxCallDepth which defaults to 10000 and is at most 100000
+// The counters start over with every run, call or evaluation made from Go
+type Limits struct {
+	// Context interrupts the program when it is canceled or its deadline passes
+	Context context.Context
+
+	// MaxSteps bounds the work done: the statements and expressions evaluated by the
+	// interpreter, or the instructions executed by the VM
+	MaxSteps int64
+
+	// MaxCallDepth bounds the nesting of function calls, up to 100000 so that recursion
+	// cannot overflow the Go stack
+	MaxCallDepth int
+
+	// MaxAllocations bounds the values created: functions, objects, strings built by
+	// concatenation and array elements
+	MaxAllocations int64
+
+	// MaxStringLength bounds the length in bytes of the strings built by concatenation
+	MaxStringLength int
+}
+
+// Errors of the limits, the Cause of the *Error a program stopped by a limit returns
+// Interrupted programs return an *Error whose Cause is the context's error instead
+// They are matched with errors.Is
+var (
+	ErrStepLimit       = errors.New("step limit exceeded")
+	ErrAllocationLimit = errors.New("allocation limit exceeded")
+	ErrStringLimit     = errors.New("string length limit exceeded")
+	ErrCallDepth       = errors.New("maximum call depth exceeded")
+)
+
+// contextCheckInterval is the number of steps between two looks at the context
+const contextCheckInterval = 1024
+
+// Meter enforces Limits while a program runs
+// The interpreter and the VM each keep one and report their work to it
+type Meter struct {
+	limits      Limits
+	maxSteps    int64 // MaxSteps, or the largest int64 for no limit
+	steps       int64
+	allocations int64
+}
+
+// NewMeter creates a meter enforcing the given limits
+func NewMeter(limits Limits) *Meter {
+	m := &Meter{}
+	m.SetLimits(limits)
+	return m
+}
+
+// SetLimits replaces the limits and starts the counters over
+func (m *Meter) SetLimits(limits Limits) {
+	if limits.MaxCallDepth <= 0 {
+		limits.MaxCallDepth = defaultMaxCallDepth
+	}
+	limits.MaxCallDepth = min(limits.MaxCallDepth, maxCallDepth)
+	m.limits = limits
+	m.maxSteps = limits.MaxSteps
+	if m.maxSteps <= 0 {
+		m.maxSteps = math.MaxInt64
+	}
+	m.Reset()
+}
+
+// Reset starts the counters over, before a new run
+func (m *Meter) Reset() {
+	m.steps, m.allocations = 0, 0
+}
+
+// Step counts one step of work
+// It fails when the step budget is spent or, looking every 1024 steps, when the context is done
+func (m *Meter) Step() error {
+	m.steps++
+	if m.steps%contextCheckInterval != 0 && m.steps <= m.maxSteps {
+		return nil
+	}
+	return m.check()
+}
+
+// check is the slow path of Step
+func (m *Meter) check() error {
+	if m.steps > m.maxSteps {
+		return &Error{Name: "RangeError", Message: "Step limit exceeded", Cause: ErrStepLimit}
+	}
+	if ctx := m.limits.Context; ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return &Error{Name: "Error", Message: "Execution interrupted: " + err.Error(), Cause: err}
+		}
+	}
+	return nil
+}
+
+// Allocate counts n values created
+func (m *Meter) Allocate(n int) error {
+	m.allocations += int64(n)
+	if m.limits.MaxAllocations > 0 && m.allocations > m.limits.MaxAllocations {
+		return &Error{Name: "RangeError", Message: "Allocation limit exceeded", Cause: ErrAllocationLimit}
+	}
+	return nil
+}
+
+// String counts a string built by the program, failing if it is too long
+func (m *Meter) String(s String) error {
+	if m.limits.MaxStringLength > 0 && len(s) > m.limits.MaxStringLength {
+		return &Error{Name: "RangeError", Message: "Invalid string length", Cause: ErrStringLimit}
+	}
+	return m.Allocate(1)
+}
+
+// Call checks that a call nested depth calls deep is allowed
+func (m *Meter) Call(depth int) error {
+	if depth >= m.limits.MaxCallDepth {
+		return &Error{Name: "RangeError", Message: "Maximum call stack size exceeded", Cause: ErrCallDepth}
+	}
+	return nil
+}
+
+// Grow counts the elements an assignment to a property of an array adds to it
+func (m *Meter) Grow(v Value, key string, x Value) error {
+	o, ok := v.(*Object)
+	if !ok || o.Class != "Array" {
+		return nil
+	}
+	n := float64(len(o.elements))
+	if key == "length" {
+		n = ToNumber(x) // Invalid lengths leave the array alone
+	} else if i, ok := arrayIndex(key); ok {
+		n = float64(i + 1)
+	}
+	if !(n > float64(len(o.elements))) || n > maxArrayLength {
+		return nil
+	}
+	return m.Allocate(int(n) - len(o.elements))
+}
+
+// maxStringLength bounds the strings that repeat and padding build even without limits,
+// as engines refuse strings of more than about 2^29 characters
+const maxStringLength = 1 << 29
+
+// reserve checks, before building it, that a string of n bytes may be built
+func (m *Meter) reserve(n float64) error {
+	if m.limits.MaxStringLength > 0 && n > float64(m.limits.MaxStringLength) {
+		return &Error{Name: "RangeError", Message: "Invalid string length", Cause: ErrStringLimit}
+	}
+	if n > maxStringLength {
+		return &Error{Name: "RangeError", Message: "Invalid string length"}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package interp
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMeter checks each limit of a Meter at its boundary
+func TestMeter(t *testing.T) {
+	m := NewMeter(Limits{MaxSteps: 3, MaxStringLength: 4, MaxCallDepth: 2})
+	for i := range 3 {
+		if err := m.Step(); err != nil {
+			t.Fatalf("step %d: %v", i+1, err)
+		}
+	}
+	if err := m.Step(); !errors.Is(err, ErrStepLimit) {
+		t.Errorf("step 4: got %v, want ErrStepLimit", err)
+	}
+	m.Reset()
+	if err := m.Step(); err != nil {
+		t.Errorf("step after Reset: %v", err)
+	}
+
+	if err := m.String("abcd"); err != nil {
+		t.Errorf("4 bytes: %v", err)
+	}
+	if err := m.String("abcde"); !errors.Is(err, ErrStringLimit) {
+		t.Errorf("5 bytes: got %v, want ErrStringLimit", err)
+	}
+
+	if err := m.Call(1); err != nil {
+		t.Errorf("depth 1: %v", err)
+	}
+	if err := m.Call(2); !errors.Is(err, ErrCallDepth) {
+		t.Errorf("depth 2: got %v, want ErrCallDepth", err)
+	}
+}
+
+// TestMeterCallDepth checks the default call depth and the cap on larger ones
+func TestMeterCallDepth(t *testing.T) {
+	tests := []struct {
+		limit, want int
+	}{
+		{0, defaultMaxCallDepth},
+		{-1, defaultMaxCallDepth},
+		{50, 50},
+		{maxCallDepth, maxCallDepth},
+		{1000000000, maxCallDepth},
+	}
+	for _, test := range tests {
+		m := NewMeter(Limits{MaxCallDepth: test.limit})
+		if err := m.Call(test.want - 1); err != nil {
+			t.Errorf("MaxCallDepth %d: depth %d refused", test.limit, test.want-1)
+		}
+		if err := m.Call(test.want); !errors.Is(err, ErrCallDepth) {
+			t.Errorf("MaxCallDepth %d: depth %d allowed", test.limit, test.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package interp
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"goast/constant"
+)
+
+// defineNumber defines Number with its constants and static functions, and the methods of
+// Number.prototype
+func (rt *Runtime) defineNumber() {
+	ctor := rt.constructor("Number", 1, func(this Value, args []Value) (Value, error) {
+		if len(args) == 0 {
+			return Number(0), nil
+		}
+		return Number(ToNumber(args[0])), nil
+	}, nil)
+	for name, n := range map[string]float64{
+		"MAX_SAFE_INTEGER":  1<<53 - 1,
+		"MIN_SAFE_INTEGER":  -(1<<53 - 1),
+		"EPSILON":           math.Nextafter(1, 2) - 1,
+		"MAX_VALUE":         math.MaxFloat64,
+		"MIN_VALUE":         math.SmallestNonzeroFloat64,
+		"POSITIVE_INFINITY": math.Inf(1),
+		"NEGATIVE_INFINITY": math.Inf(-1),
+		"NaN":               math.NaN(),
+	} {
+		ctor.Set(name, Number(n))
+	}
+	// Unlike the global functions, these do not convert their argument
+	number := func(fn func(n float64) bool) func(this Value, args []Value) (Value, error) {
+		return func(this Value, args []Value) (Value, error) {
+			n, ok := arg(args, 0).(Number)
+			return Boolean(ok && fn(float64(n))), nil
+		}
+	}
+	isInteger := func(n float64) bool { return !math.IsInf(n, 0) && n == math.Trunc(n) }
+	rt.method(&ctor.Object, "isInteger", 1, number(isInteger))
+	rt.method(&ctor.Object, "isSafeInteger", 1, number(func(n float64) bool {
+		return isInteger(n) && math.Abs(n) <= 1<<53-1
+	}))
+	rt.method(&ctor.Object, "isFinite", 1, number(func(n float64) bool { return !math.IsNaN(n) && !math.IsInf(n, 0) }))
+	rt.method(&ctor.Object, "isNaN", 1, number(math.IsNaN))
+	ctor.Set("parseFloat", rt.Globals["parseFloat"])
+	ctor.Set("parseInt", rt.Globals["parseInt"])
+
+	proto := rt.protos["Number"]
+	method := func(name string, length int, fn func(n float64, args []Value) (Value, error)) {
+		rt.method(proto, name, length, func(this Value, args []Value) (Value, error) {
+			n, ok := this.(Number)
+			if !ok {
+				return nil, typeError("Number.prototype.%s requires that 'this' be a Number", name)
+			}
+			return fn(float64(n), args)
+		})
+	}
+	method("valueOf", 0, func(n float64, args []Value) (Value, error) { return Number(n), nil })
+	method("toString", 1, func(n float64, args []Value) (Value, error) {
+		radix := 10.0
+		if v := arg(args, 0); v != (Undefined{}) {
+			radix = toInteger(v)
+		}
+		if radix < 2 || radix > 36 {
+			return nil, rangeError("toString() radix must be between 2 and 36")
+		}
+		return String(formatRadix(n, int(radix))), nil
+	})
+	method("toFixed", 1, func(n float64, args []Value) (Value, error) {
+		digits := toInteger(arg(args, 0))
+		if digits < 0 || digits > 100 {
+			return nil, rangeError("toFixed() digits argument must be between 0 and 100")
+		}
+		if math.IsNaN(n) || math.Abs(n) >= 1e21 {
+			return String(constant.NumberToString(n)), nil
+		}
+		// Halves round away from zero, where strconv would round them to even
+		return String(new(big.Rat).SetFloat64(n).FloatString(int(digits))), nil
+	})
+	method("toPrecision", 1, func(n float64, args []Value) (Value, error) {
+		if arg(args, 0) == (Undefined{}) || math.IsNaN(n) || math.IsInf(n, 0) {
+			return String(constant.NumberToString(n)), nil
+		}
+		p := toInteger(arg(args, 0))
+		if p < 1 || p > 100 {
+			return nil, rangeError("toPrecision() argument must be between 1 and 100")
+		}
+		return String(toPrecision(n, int(p))), nil
+	})
+}
+
+// formatRadix formats a number in a base between 2 and 36, with up to 20 fraction digits
+// for numbers that are not integers
+func formatRadix(n float64, radix int) string {
+	if radix == 10 || math.IsNaN(n) || math.IsInf(n, 0) {
+		return constant.NumberToString(n)
+	}
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	integer, fraction := math.Modf(n)
+	var digits string
+	if integer < 1<<63 {
+		digits = strconv.FormatUint(uint64(integer), radix)
+	} else {
+		var sb []byte
+		for ; integer >= 1; integer = math.Floor(integer / float64(radix)) {
+			sb = append(sb, "0123456789abcdefghijklmnopqrstuvwxyz"[int(math.Mod(integer, float64(radix)))])
+		}
+		for i, j := 0, len(sb)-1; i < j; i, j = i+1, j-1 {
+			sb[i], sb[j] = sb[j], sb[i]
+		}
+		digits = string(sb)
+	}
+	if fraction > 0 {
+		var sb strings.Builder
+		for i := 0; i < 20 && fraction > 0; i++ {
+			fraction *= float64(radix)
+			d := int(fraction)
+			sb.WriteByte("0123456789abcdefghijklmnopqrstuvwxyz"[d])
+			fraction -= float64(d)
+		}
+		digits += "." + strings.TrimRight(sb.String(), "0")
+	}
+	return sign + digits
+}
+
+// toPrecision formats a number with p significant digits, in exponent notation when the
+// exponent is below -6 or not less than p
+func toPrecision(n float64, p int) string {
+	if n == 0 {
+		s := "0"
+		if p > 1 {
+			s += "." + strings.Repeat("0", p-1)
+		}
+		return s
+	}
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(n, 'e', p-1, 64), "e")
+	e, _ := strconv.Atoi(exponent)
+	if e < -6 || e >= p {
+		if e >= 0 {
+			return mantissa + "e+" + strconv.Itoa(e)
+		}
+		return mantissa + "e" + strconv.Itoa(e)
+	}
+	return strconv.FormatFloat(n, 'f', p-1-e, 64)
+}
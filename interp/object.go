@@ -0,0 +1,302 @@
+package interp
+
+import "goast/constant"
+
+// defineObject defines Object with its static functions and Object.prototype
+func (rt *Runtime) defineObject() {
+	toObject := func(this Value, args []Value) (Value, error) {
+		if v := arg(args, 0); isObject(v) {
+			return v, nil
+		}
+		return rt.object()
+	}
+	ctor := rt.constructor("Object", 1, toObject, func(args []Value) (Value, error) {
+		return toObject(Undefined{}, args)
+	})
+
+	rt.method(&ctor.Object, "keys", 1, func(this Value, args []Value) (Value, error) {
+		keys, err := ownKeys(arg(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		values := make([]Value, len(keys))
+		for i, key := range keys {
+			values[i] = String(key)
+		}
+		return rt.array(values)
+	})
+	rt.method(&ctor.Object, "values", 1, func(this Value, args []Value) (Value, error) {
+		o := arg(args, 0)
+		keys, err := ownKeys(o)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]Value, len(keys))
+		for i, key := range keys {
+			if values[i], err = GetProperty(o, key); err != nil {
+				return nil, err
+			}
+		}
+		return rt.array(values)
+	})
+	rt.method(&ctor.Object, "entries", 1, func(this Value, args []Value) (Value, error) {
+		o := arg(args, 0)
+		keys, err := ownKeys(o)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]Value, len(keys))
+		for i, key := range keys {
+			v, err := GetProperty(o, key)
+			if err != nil {
+				return nil, err
+			}
+			if entries[i], err = rt.array([]Value{String(key), v}); err != nil {
+				return nil, err
+			}
+		}
+		return rt.array(entries)
+	})
+	rt.method(&ctor.Object, "fromEntries", 1, func(this Value, args []Value) (Value, error) {
+		entries, err := rt.iterate(arg(args, 0))
+		if err != nil {
+			return nil, err
+		}
+		o, err := rt.object()
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			key, err := rt.GetProperty(entry, "0")
+			if err != nil {
+				return nil, err
+			}
+			v, err := rt.GetProperty(entry, "1")
+			if err != nil {
+				return nil, err
+			}
+			o.Set(ToString(key), v)
+		}
+		return o, nil
+	})
+	rt.method(&ctor.Object, "assign", 2, func(this Value, args []Value) (Value, error) {
+		target := arg(args, 0)
+		if !isObject(target) {
+			return nil, typeError("Cannot convert %s to object", Inspect(target))
+		}
+		for _, source := range args[1:] {
+			if isNullish(source) {
+				continue
+			}
+			keys, err := ownKeys(source)
+			if err != nil {
+				return nil, err
+			}
+			for _, key := range keys {
+				v, err := GetProperty(source, key)
+				if err != nil {
+					return nil, err
+				}
+				if err := rt.meter.Grow(target, key, v); err != nil {
+					return nil, err
+				}
+				if err := SetProperty(target, key, v); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return target, nil
+	})
+	rt.method(&ctor.Object, "freeze", 1, func(this Value, args []Value) (Value, error) {
+		if o := objectOf(arg(args, 0)); o != nil {
+			o.frozen = true
+		}
+		return arg(args, 0), nil
+	})
+	rt.method(&ctor.Object, "isFrozen", 1, func(this Value, args []Value) (Value, error) {
+		o := objectOf(arg(args, 0))
+		return Boolean(o == nil || o.frozen), nil
+	})
+	rt.method(&ctor.Object, "create", 2, func(this Value, args []Value) (Value, error) {
+		o, err := rt.object()
+		if err != nil {
+			return nil, err
+		}
+		switch proto := arg(args, 0).(type) {
+		case Null:
+			o.proto = noPrototype
+		case *Object:
+			o.proto = proto
+		case *Function:
+			o.proto = &proto.Object
+		default:
+			return nil, typeError("Object prototype may only be an Object or null: %s", Inspect(proto))
+		}
+		return o, nil
+	})
+	rt.method(&ctor.Object, "getPrototypeOf", 1, func(this Value, args []Value) (Value, error) {
+		v := arg(args, 0)
+		if isNullish(v) {
+			return nil, typeError("Cannot convert undefined or null to object")
+		}
+		if proto := rt.protoOf(v); proto != nil {
+			return proto, nil
+		}
+		return Null{}, nil
+	})
+	rt.method(&ctor.Object, "is", 2, func(this Value, args []Value) (Value, error) {
+		return Boolean(sameValue(arg(args, 0), arg(args, 1))), nil
+	})
+
+	proto := rt.protos["Object"]
+	rt.method(proto, "hasOwnProperty", 1, func(this Value, args []Value) (Value, error) {
+		return Boolean(hasOwn(this, ToString(arg(args, 0)))), nil
+	})
+	rt.method(&ctor.Object, "hasOwn", 2, func(this Value, args []Value) (Value, error) {
+		return Boolean(hasOwn(arg(args, 0), ToString(arg(args, 1)))), nil
+	})
+	rt.method(proto, "toString", 0, func(this Value, args []Value) (Value, error) {
+		switch this.(type) {
+		case Undefined:
+			return String("[object Undefined]"), nil
+		case Null:
+			return String("[object Null]"), nil
+		}
+		if o := objectOf(this); o != nil {
+			return String("[object " + o.Class + "]"), nil
+		}
+		return String("[object " + classOf(this) + "]"), nil
+	})
+	rt.method(proto, "valueOf", 0, func(this Value, args []Value) (Value, error) {
+		return this, nil
+	})
+}
+
+// defineFunction defines Function.prototype with call, apply and bind
+// There is no Function constructor: programs cannot be built from strings
+func (rt *Runtime) defineFunction() {
+	proto := rt.protos["Function"]
+	rt.method(proto, "call", 1, func(this Value, args []Value) (Value, error) {
+		if len(args) == 0 {
+			return rt.call(this, Undefined{})
+		}
+		return rt.call(this, args[0], args[1:]...)
+	})
+	rt.method(proto, "apply", 2, func(this Value, args []Value) (Value, error) {
+		var list []Value
+		if a := arg(args, 1); !isNullish(a) {
+			o, ok := a.(*Object)
+			if !ok || o.Class != "Array" {
+				return nil, typeError("CreateListFromArrayLike called on non-object")
+			}
+			list = o.Elements()
+		}
+		return rt.call(this, arg(args, 0), list...)
+	})
+	rt.method(proto, "bind", 1, func(this Value, args []Value) (Value, error) {
+		f, err := callable(this)
+		if err != nil {
+			return nil, err
+		}
+		receiver, bound := arg(args, 0), append([]Value(nil), args[min(1, len(args)):]...)
+		b := rt.native("bound "+f.Name, max(0, f.arity()-len(bound)), func(_ Value, args []Value) (Value, error) {
+			return f.Call(receiver, append(append([]Value(nil), bound...), args...)...)
+		})
+		return b, rt.meter.Allocate(1)
+	})
+	rt.method(proto, "toString", 0, func(this Value, args []Value) (Value, error) {
+		f, ok := this.(*Function)
+		if !ok {
+			return nil, typeError("Function.prototype.toString requires that 'this' be a Function")
+		}
+		return String(f.sourceText()), nil
+	})
+}
+
+// defineBoolean defines Boolean, which converts its argument to a boolean
+func (rt *Runtime) defineBoolean() {
+	convert := func(this Value, args []Value) (Value, error) {
+		return Boolean(ToBoolean(arg(args, 0))), nil
+	}
+	rt.constructor("Boolean", 1, convert, nil)
+	proto := rt.protos["Boolean"]
+	rt.method(proto, "toString", 0, func(this Value, args []Value) (Value, error) {
+		b, ok := this.(Boolean)
+		if !ok {
+			return nil, typeError("Boolean.prototype.toString requires that 'this' be a Boolean")
+		}
+		return String(ToString(b)), nil
+	})
+	rt.method(proto, "valueOf", 0, func(this Value, args []Value) (Value, error) {
+		return this, nil
+	})
+}
+
+// ownKeys returns the names of the own enumerable properties of a value, in order
+// Strings have their indexes; undefined and null cannot be converted to objects
+func ownKeys(v Value) ([]string, error) {
+	switch v := v.(type) {
+	case Undefined, Null:
+		return nil, typeError("Cannot convert undefined or null to object")
+	case String:
+		keys := make([]string, len(utf16Units(string(v))))
+		for i := range keys {
+			keys[i] = constant.NumberToString(float64(i))
+		}
+		return keys, nil
+	}
+	if o := objectOf(v); o != nil {
+		return o.Keys(), nil
+	}
+	return nil, nil
+}
+
+// hasOwn reports whether a value has an own property
+func hasOwn(v Value, key string) bool {
+	if s, ok := v.(String); ok {
+		i, ok := arrayIndex(key)
+		return key == "length" || ok && i < len(utf16Units(string(s)))
+	}
+	if o := objectOf(v); o != nil {
+		_, ok := o.own(key)
+		return ok
+	}
+	return false
+}
+
+// objectOf returns the object behind an object or a function, nil for primitives
+func objectOf(v Value) *Object {
+	switch v := v.(type) {
+	case *Object:
+		return v
+	case *Function:
+		return &v.Object
+	}
+	return nil
+}
+
+// classOf names the type of a primitive the way Object.prototype.toString does
+func classOf(v Value) string {
+	switch v.(type) {
+	case Boolean:
+		return "Boolean"
+	case Number:
+		return "Number"
+	case String:
+		return "String"
+	}
+	return "Object"
+}
+
+// sameValue implements Object.is: strict equality, except that NaN is itself and 0 is not -0
+func sameValue(a, b Value) bool {
+	x, ok := a.(Number)
+	y, ok2 := b.(Number)
+	if ok && ok2 {
+		if x != x && y != y {
+			return true
+		}
+		return x == y && (x != 0 || (1/x > 0) == (1/y > 0))
+	}
+	return StrictEquals(a, b)
+}
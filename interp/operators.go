@@ -0,0 +1,79 @@
+package interp
+
+import (
+	"fmt"
+
+	"goast/constant"
+)
+
+// BinaryOperation applies a binary operator to two values with JavaScript's coercion rules
+// Equality compares objects by identity; the other operators convert objects to primitives
+// and leave the rest to the constant package
+// Assignment is not an operation on values and is rejected, like unknown operators
+func BinaryOperation(operator string, left, right Value) (Value, error) {
+	switch operator {
+	case "==":
+		return Boolean(LooseEquals(left, right)), nil
+	case "!=":
+		return Boolean(!LooseEquals(left, right)), nil
+	case "===":
+		return Boolean(StrictEquals(left, right)), nil
+	case "!==":
+		return Boolean(!StrictEquals(left, right)), nil
+	case "+":
+		return Add(left, right), nil
+	}
+	// Dates become their time value in arithmetic and comparisons
+	if v, ok := constant.BinaryOperation(operator, toPrimitiveNumber(left), toPrimitiveNumber(right)); ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported operator %q", operator)
+}
+
+// Add implements +: string concatenation when either primitive operand is a string,
+// numeric addition otherwise
+func Add(left, right Value) Value {
+	v, _ := constant.BinaryOperation("+", ToPrimitive(left), ToPrimitive(right))
+	return v
+}
+
+// StrictEquals implements ===: same type and same value, NaN is not equal to itself
+// Objects and functions are equal only to themselves
+func StrictEquals(left, right Value) bool {
+	return constant.StrictEquals(left, right)
+}
+
+// LooseEquals implements ==
+// Two objects are equal only when they are the same object, an object compared with a
+// primitive is converted to a primitive first, and primitives compare as constant.LooseEquals
+func LooseEquals(left, right Value) bool {
+	switch {
+	case isNullish(left) || isNullish(right):
+		return isNullish(left) && isNullish(right)
+	case isObject(left) && isObject(right):
+		return left == right
+	case isObject(left):
+		return LooseEquals(ToPrimitive(left), right)
+	case isObject(right):
+		return LooseEquals(left, ToPrimitive(right))
+	}
+	return constant.LooseEquals(left, right)
+}
+
+// isNullish reports whether v is null or undefined
+func isNullish(v Value) bool {
+	switch v.(type) {
+	case Null, Undefined:
+		return true
+	}
+	return false
+}
+
+// isObject reports whether v is an object or a function rather than a primitive
+func isObject(v Value) bool {
+	switch v.(type) {
+	case *Object, *Function:
+		return true
+	}
+	return false
+}
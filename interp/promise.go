@@ -0,0 +1,338 @@
+package interp
+
+// promise is the internal state of a Promise object
+type promise struct {
+	state     string // "pending", "fulfilled" or "rejected"
+	value     Value  // Value or reason once settled
+	reactions []reaction
+	handled   bool // Set once a handler was attached, a rejection is then not reported
+}
+
+// reaction is what happens when a promise settles: the handler for its outcome is called,
+// and what it returns or throws settles the promise then returned
+type reaction struct {
+	onFulfilled, onRejected Value
+	resolve, reject         func(Value) error // Resolving functions of the derived promise
+}
+
+// definePromise defines Promise with its static functions and the methods of Promise.prototype
+// Handlers run as microtasks, once the code that settled the promise has returned
+func (rt *Runtime) definePromise() {
+	ctor := rt.constructor("Promise", 1, nil, func(args []Value) (Value, error) {
+		executor, ok := arg(args, 0).(*Function)
+		if !ok {
+			return nil, typeError("Promise resolver %s is not a function", Inspect(arg(args, 0)))
+		}
+		p, resolve, reject, err := rt.newPromise()
+		if err != nil {
+			return nil, err
+		}
+		resolveFn, rejectFn := rt.native("", 1, valueFunc(resolve)), rt.native("", 1, valueFunc(reject))
+		if _, err := executor.Call(Undefined{}, resolveFn, rejectFn); err != nil {
+			reason, err := rt.thrown(err)
+			if err != nil {
+				return nil, err
+			}
+			if err := reject(reason); err != nil {
+				return nil, err
+			}
+		}
+		return p, nil
+	})
+
+	rt.method(&ctor.Object, "resolve", 1, func(this Value, args []Value) (Value, error) {
+		return rt.promiseResolve(arg(args, 0))
+	})
+	rt.method(&ctor.Object, "reject", 1, func(this Value, args []Value) (Value, error) {
+		p, _, reject, err := rt.newPromise()
+		if err != nil {
+			return nil, err
+		}
+		return p, reject(arg(args, 0))
+	})
+	for _, name := range []string{"all", "allSettled", "race", "any"} {
+		rt.method(&ctor.Object, name, 1, func(this Value, args []Value) (Value, error) {
+			return rt.combine(name, arg(args, 0))
+		})
+	}
+
+	proto := rt.protos["Promise"]
+	method := func(name string, length int, fn func(p *Object, args []Value) (Value, error)) {
+		rt.method(proto, name, length, func(this Value, args []Value) (Value, error) {
+			if o, ok := this.(*Object); ok {
+				if _, ok := o.data.(*promise); ok {
+					return fn(o, args)
+				}
+			}
+			return nil, typeError("Method Promise.prototype.%s called on incompatible receiver %s", name, describeValue(this))
+		})
+	}
+	method("then", 2, func(p *Object, args []Value) (Value, error) {
+		return rt.then(p, arg(args, 0), arg(args, 1))
+	})
+	method("catch", 1, func(p *Object, args []Value) (Value, error) {
+		return rt.then(p, Undefined{}, arg(args, 0))
+	})
+	method("finally", 1, func(p *Object, args []Value) (Value, error) {
+		f, ok := arg(args, 0).(*Function)
+		if !ok {
+			return rt.then(p, arg(args, 0), arg(args, 0))
+		}
+		// after calls f and returns a promise settled like the original once f's result is
+		// settled, or rejected if f fails
+		after := func(settle func(v Value) (Value, error)) Value {
+			return rt.native("", 1, func(this Value, args []Value) (Value, error) {
+				result, err := f.Call(Undefined{})
+				if err != nil {
+					return nil, err
+				}
+				done, err := rt.promiseResolve(result)
+				if err != nil {
+					return nil, err
+				}
+				v := arg(args, 0)
+				return rt.then(done, rt.native("", 0, func(Value, []Value) (Value, error) { return settle(v) }), Undefined{})
+			})
+		}
+		return rt.then(p, after(func(v Value) (Value, error) {
+			return v, nil
+		}), after(func(reason Value) (Value, error) {
+			rejected, _, reject, err := rt.newPromise()
+			if err != nil {
+				return nil, err
+			}
+			return rejected, reject(reason)
+		}))
+	})
+}
+
+// valueFunc adapts a resolving function to a native function
+func valueFunc(fn func(Value) error) func(this Value, args []Value) (Value, error) {
+	return func(this Value, args []Value) (Value, error) {
+		return Undefined{}, fn(arg(args, 0))
+	}
+}
+
+// newPromise creates a pending promise with the functions resolving and rejecting it
+// Only the first call of either has an effect; resolving with a promise or another object
+// with a then method adopts its outcome
+// The functions return only the errors that stop the program, like a limit being reached
+func (rt *Runtime) newPromise() (*Object, func(Value) error, func(Value) error, error) {
+	if err := rt.meter.Allocate(1); err != nil {
+		return nil, nil, nil, err
+	}
+	state := &promise{state: "pending"}
+	p := &Object{Class: "Promise", data: state}
+	resolve, reject := rt.resolvingFunctions(p)
+	return p, resolve, reject, nil
+}
+
+// resolvingFunctions creates a pair of resolving functions for a promise
+func (rt *Runtime) resolvingFunctions(p *Object) (func(Value) error, func(Value) error) {
+	done := false
+	var resolve, reject func(Value) error
+	reject = func(reason Value) error {
+		if done {
+			return nil
+		}
+		done = true
+		return rt.settle(p, "rejected", reason)
+	}
+	resolve = func(v Value) error {
+		if done {
+			return nil
+		}
+		if v == Value(p) {
+			e, err := rt.newError("TypeError", "Chaining cycle detected for promise #<Promise>")
+			if err != nil {
+				return err
+			}
+			return reject(e)
+		}
+		if !isObject(v) {
+			done = true
+			return rt.settle(p, "fulfilled", v)
+		}
+		then, err := rt.GetProperty(v, "then")
+		if err != nil {
+			reason, err := rt.thrown(err)
+			if err != nil {
+				return err
+			}
+			return reject(reason)
+		}
+		f, ok := then.(*Function)
+		if !ok {
+			done = true
+			return rt.settle(p, "fulfilled", v)
+		}
+		// A thenable is adopted in a job of its own, calling its then with new resolving functions
+		done = true
+		rt.enqueue(func() error {
+			resolve, reject := rt.resolvingFunctions(p)
+			_, err := f.Call(v, rt.native("", 1, valueFunc(resolve)), rt.native("", 1, valueFunc(reject)))
+			if err != nil {
+				reason, err := rt.thrown(err)
+				if err != nil {
+					return err
+				}
+				return reject(reason)
+			}
+			return nil
+		})
+		return nil
+	}
+	return resolve, reject
+}
+
+// settle fulfills or rejects a promise and queues the jobs of its reactions
+func (rt *Runtime) settle(p *Object, state string, v Value) error {
+	s := p.data.(*promise)
+	s.state, s.value = state, v
+	if state == "rejected" && !s.handled {
+		rt.rejected = append(rt.rejected, p)
+	}
+	for _, r := range s.reactions {
+		rt.react(s, r)
+	}
+	s.reactions = nil
+	return nil
+}
+
+// react queues the job running a reaction to a settled promise
+func (rt *Runtime) react(s *promise, r reaction) {
+	rt.enqueue(func() error {
+		handler, settle := r.onFulfilled, r.resolve
+		if s.state == "rejected" {
+			handler, settle = r.onRejected, r.reject
+		}
+		f, ok := handler.(*Function)
+		if !ok {
+			return settle(s.value) // Without a handler the outcome passes through
+		}
+		result, err := f.Call(Undefined{}, s.value)
+		if err != nil {
+			reason, err := rt.thrown(err)
+			if err != nil {
+				return err
+			}
+			return r.reject(reason)
+		}
+		return r.resolve(result)
+	})
+}
+
+// then attaches handlers to a promise and returns the promise their outcome settles
+func (rt *Runtime) then(p *Object, onFulfilled, onRejected Value) (Value, error) {
+	derived, resolve, reject, err := rt.newPromise()
+	if err != nil {
+		return nil, err
+	}
+	s := p.data.(*promise)
+	s.handled = true
+	r := reaction{onFulfilled: onFulfilled, onRejected: onRejected, resolve: resolve, reject: reject}
+	if s.state == "pending" {
+		s.reactions = append(s.reactions, r)
+	} else {
+		rt.react(s, r)
+	}
+	return derived, nil
+}
+
+// promiseResolve returns a promise for a value: the value itself if it is a promise
+func (rt *Runtime) promiseResolve(v Value) (*Object, error) {
+	if o, ok := v.(*Object); ok {
+		if _, ok := o.data.(*promise); ok {
+			return o, nil
+		}
+	}
+	p, resolve, _, err := rt.newPromise()
+	if err != nil {
+		return nil, err
+	}
+	return p, resolve(v)
+}
+
+// combine implements Promise.all, allSettled, race and any, which combine the promises for
+// the values of an iterable into one
+func (rt *Runtime) combine(kind string, iterable Value) (Value, error) {
+	combined, resolve, reject, err := rt.newPromise()
+	if err != nil {
+		return nil, err
+	}
+	values, err := rt.iterate(iterable)
+	if err != nil {
+		reason, err := rt.thrown(err)
+		if err != nil {
+			return nil, err
+		}
+		return combined, reject(reason)
+	}
+	results := make([]Value, len(values))
+	remaining := len(values)
+	// finish settles the combined promise with all the results
+	finish := func() error {
+		array, err := rt.array(results)
+		if err != nil {
+			return err
+		}
+		if kind == "any" {
+			e, err := rt.newError("AggregateError", "All promises were rejected")
+			if err != nil {
+				return err
+			}
+			e.Set("errors", array)
+			return reject(e)
+		}
+		return resolve(array)
+	}
+	if remaining == 0 && kind != "race" {
+		return combined, finish()
+	}
+	// done records the result of the promise at index i, and finishes after the last one
+	done := func(i int, v Value) error {
+		results[i] = v
+		if remaining--; remaining > 0 {
+			return nil
+		}
+		return finish()
+	}
+	// record is the handler recording a result, as an outcome object for allSettled
+	record := func(i int, status, key string) Value {
+		return rt.native("", 1, func(this Value, args []Value) (Value, error) {
+			v := arg(args, 0)
+			if kind == "allSettled" {
+				o, err := rt.object()
+				if err != nil {
+					return nil, err
+				}
+				o.Set("status", String(status))
+				o.Set(key, v)
+				v = o
+			}
+			return Undefined{}, done(i, v)
+		})
+	}
+	resolveFn, rejectFn := rt.native("", 1, valueFunc(resolve)), rt.native("", 1, valueFunc(reject))
+	for i, v := range values {
+		p, err := rt.promiseResolve(v)
+		if err != nil {
+			return nil, err
+		}
+		var onFulfilled, onRejected Value
+		switch kind {
+		case "all":
+			onFulfilled, onRejected = record(i, "", ""), rejectFn
+		case "allSettled":
+			onFulfilled, onRejected = record(i, "fulfilled", "value"), record(i, "rejected", "reason")
+		case "race":
+			onFulfilled, onRejected = resolveFn, rejectFn
+		case "any":
+			onFulfilled, onRejected = resolveFn, record(i, "", "")
+		}
+		if _, err := rt.then(p, onFulfilled, onRejected); err != nil {
+			return nil, err
+		}
+	}
+	return combined, nil
+}
@@ -0,0 +1,71 @@
+package interp
+
+import "unicode/utf16"
+
+// GetProperty reads a property of a value, as object.key does
+// Strings have a length and their UTF-16 code units as indexed properties, functions a name
+// and a length; reading a property of undefined or null is a TypeError
+func GetProperty(v Value, key string) (Value, error) {
+	switch v := v.(type) {
+	case Undefined, Null:
+		return nil, &Error{Name: "TypeError", Message: "Cannot read properties of " + ToString(v) + " (reading '" + key + "')"}
+	case String:
+		units := utf16Units(string(v))
+		if key == "length" {
+			return Number(len(units)), nil
+		}
+		if i, ok := arrayIndex(key); ok {
+			if i < len(units) {
+				return String(utf16ToString(units[i : i+1])), nil
+			}
+		}
+	case *Object:
+		return v.Get(key), nil
+	case *Function:
+		if _, ok := v.properties[key]; ok {
+			return v.Get(key), nil
+		}
+		switch key {
+		case "name":
+			return String(v.Name), nil
+		case "length":
+			return Number(v.arity()), nil
+		}
+	}
+	return Undefined{}, nil
+}
+
+// SetProperty assigns a property of a value, as object.key = x does
+// Assignments to the properties of other primitives are ignored, as in sloppy mode, but
+// those to undefined and null are a TypeError
+func SetProperty(v Value, key string, x Value) error {
+	switch v := v.(type) {
+	case Undefined, Null:
+		return &Error{Name: "TypeError", Message: "Cannot set properties of " + ToString(v) + " (setting '" + key + "')"}
+	case *Object:
+		v.Set(key, x)
+	case *Function:
+		v.Set(key, x)
+	}
+	return nil
+}
+
+// arity returns the number of parameters before the first one with a default value, the
+// length JavaScript reports for a function
+func (f *Function) arity() int {
+	if f.decl == nil {
+		return f.Length
+	}
+	for i, param := range f.decl.Params {
+		if param.DefaultValue != nil {
+			return i
+		}
+	}
+	return len(f.decl.Params)
+}
+
+// utf16ToString converts UTF-16 code units back to a string
+// Lone surrogates, which UTF-8 cannot hold, become U+FFFD
+func utf16ToString(units []uint16) string {
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,324 @@
+package interp
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// Runtime is the standard library programs run with: the global objects, the prototypes
+// their methods live on and the queue of promise jobs
+// Every interpreter has its own; other engines sharing these values, like the VM, create
+// one with NewRuntime and call RunMicrotasks when the code they were asked to run returns
+type Runtime struct {
+	// Globals are the variables a program starts with besides undefined, NaN and Infinity
+	Globals map[string]Value
+
+	stdout, stderr io.Writer
+	now            func() time.Time
+	location       *time.Location
+	meter          *Meter
+
+	protos   map[string]*Object // Prototypes by class: Object, Array, Function, String, Date...
+	jobs     []func() error     // Microtasks waiting to run, in order
+	rejected []*Object          // Promises rejected since the queue was last drained
+}
+
+// ErrUnhandledRejection is the Cause of the *Error RunMicrotasks returns when a promise is
+// rejected and nothing handles it
+var ErrUnhandledRejection = errors.New("unhandled promise rejection")
+
+// NewRuntime creates the standard library for an engine
+// opts gives the output of console, the clock and time zone of Date; the allocations and
+// strings of the built-in functions count against meter, which may be nil
+func NewRuntime(opts *Options, meter *Meter) *Runtime {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	rt := &Runtime{stdout: o.Stdout, stderr: o.Stderr, now: o.Now, location: o.Location, meter: meter}
+	if rt.stdout == nil {
+		rt.stdout = os.Stdout
+	}
+	if rt.stderr == nil {
+		rt.stderr = os.Stderr
+	}
+	if rt.now == nil {
+		rt.now = time.Now
+	}
+	if rt.location == nil {
+		rt.location = time.UTC
+	}
+	if rt.meter == nil {
+		rt.meter = NewMeter(Limits{})
+	}
+
+	// Prototypes first, the constructors hang them on their prototype property
+	rt.protos = map[string]*Object{}
+	objectProto := &Object{Class: "Object", proto: noPrototype}
+	rt.protos["Object"] = objectProto
+	for _, class := range []string{"Function", "Array", "String", "Number", "Boolean", "Date", "Map", "Set", "Promise", "Error"} {
+		rt.protos[class] = &Object{Class: "Object", proto: objectProto}
+	}
+	for _, name := range errorTypes[1:] {
+		rt.protos[name] = &Object{Class: "Object", proto: rt.protos["Error"]}
+	}
+
+	rt.Globals = map[string]Value{
+		"console":    console(rt.stdout, rt.stderr),
+		"Math":       mathObject(),
+		"JSON":       rt.jsonObject(),
+		"parseInt":   rt.native("parseInt", 2, parseInt),
+		"parseFloat": rt.native("parseFloat", 1, parseFloat),
+		"isNaN":      rt.native("isNaN", 1, isNaN),
+		"isFinite":   rt.native("isFinite", 1, isFinite),
+	}
+	rt.defineObject()
+	rt.defineFunction()
+	rt.defineArray()
+	rt.defineString()
+	rt.defineNumber()
+	rt.defineBoolean()
+	rt.defineDate()
+	rt.defineCollections()
+	rt.defineErrors()
+	rt.definePromise()
+	return rt
+}
+
+// noPrototype is the prototype of objects that have none, like Object.create(null)
+var noPrototype = &Object{Class: "Object"}
+
+// protoOf returns the prototype of a value, nil at the end of the chain
+func (rt *Runtime) protoOf(v Value) *Object {
+	var o *Object
+	switch v := v.(type) {
+	case String:
+		return rt.protos["String"]
+	case Number:
+		return rt.protos["Number"]
+	case Boolean:
+		return rt.protos["Boolean"]
+	case *Function:
+		o = &v.Object
+		if o.proto == nil {
+			return rt.protos["Function"]
+		}
+	case *Object:
+		o = v
+	default:
+		return nil
+	}
+	switch {
+	case o.proto == noPrototype:
+		return nil
+	case o.proto != nil:
+		return o.proto
+	}
+	switch o.data.(type) {
+	case *date:
+		return rt.protos["Date"]
+	case *collection, *promise:
+		return rt.protos[o.Class]
+	case errorData:
+		if proto, ok := rt.protos[o.Class]; ok {
+			return proto
+		}
+		return rt.protos["Error"]
+	}
+	if o.Class == "Array" {
+		return rt.protos["Array"]
+	}
+	return rt.protos["Object"]
+}
+
+// GetProperty reads a property of a value as GetProperty does, then looks for it along the
+// prototype chain: this is how programs find methods like [1, 2].map or "abc".toUpperCase
+func (rt *Runtime) GetProperty(v Value, key string) (Value, error) {
+	switch o := v.(type) {
+	case *Object:
+		if x, ok := o.own(key); ok {
+			return x, nil
+		}
+		if c, ok := o.data.(*collection); ok && key == "size" {
+			return Number(c.size), nil
+		}
+	case *Function:
+		if _, ok := o.properties[key]; ok || key == "name" || key == "length" {
+			return GetProperty(o, key)
+		}
+	default:
+		if x, err := GetProperty(v, key); err != nil || x != (Undefined{}) {
+			return x, err
+		}
+	}
+	for proto := rt.protoOf(v); proto != nil; proto = rt.protoOf(proto) {
+		if x, ok := proto.own(key); ok {
+			return x, nil
+		}
+	}
+	return Undefined{}, nil
+}
+
+// RunMicrotasks runs the promise jobs queued so far and those they queue in turn, until
+// the queue is empty
+// The first error a job returns, like a limit being reached, stops it and drops the jobs
+// still waiting; once the queue is empty, a promise rejected without a handler is an
+// error whose Cause is ErrUnhandledRejection
+func (rt *Runtime) RunMicrotasks() error {
+	for len(rt.jobs) > 0 {
+		job := rt.jobs[0]
+		rt.jobs = rt.jobs[1:]
+		err := rt.meter.Step()
+		if err == nil {
+			err = job()
+		}
+		if err != nil {
+			rt.jobs, rt.rejected = nil, nil
+			return err
+		}
+	}
+	rejected := rt.rejected
+	rt.rejected = nil
+	for _, p := range rejected {
+		if state := p.data.(*promise); !state.handled {
+			return unhandledRejection(state.value)
+		}
+	}
+	return nil
+}
+
+// enqueue adds a job to the microtask queue
+func (rt *Runtime) enqueue(job func() error) {
+	rt.jobs = append(rt.jobs, job)
+}
+
+// native creates a native function reporting length parameters
+func (rt *Runtime) native(name string, length int, fn func(this Value, args []Value) (Value, error)) *Function {
+	f := NewNativeFunction(name, fn)
+	f.Length = length
+	return f
+}
+
+// method defines a native method on an object
+func (rt *Runtime) method(o *Object, name string, length int, fn func(this Value, args []Value) (Value, error)) {
+	o.Set(name, rt.native(name, length, fn))
+}
+
+// constructor creates the global function of a class, linked to its prototype
+// call runs when the function is called without new, construct when it is called with new;
+// either may be nil, calling a class that needs new being a TypeError
+func (rt *Runtime) constructor(name string, length int, call func(this Value, args []Value) (Value, error), construct func(args []Value) (Value, error)) *Function {
+	if call == nil {
+		call = func(Value, []Value) (Value, error) {
+			return nil, typeError("Class constructor %s cannot be invoked without 'new'", name)
+		}
+	}
+	f := rt.native(name, length, call)
+	f.Construct = construct
+	proto := rt.protos[name]
+	f.Set("prototype", proto)
+	proto.Set("constructor", f)
+	rt.Globals[name] = f
+	return f
+}
+
+// array creates an array from a slice it takes over, counting its elements as allocations
+func (rt *Runtime) array(elements []Value) (*Object, error) {
+	if err := rt.meter.Allocate(len(elements) + 1); err != nil {
+		return nil, err
+	}
+	if elements == nil {
+		elements = []Value{}
+	}
+	return &Object{Class: "Array", elements: elements}, nil
+}
+
+// object creates an empty plain object, counting it as an allocation
+func (rt *Runtime) object() (*Object, error) {
+	if err := rt.meter.Allocate(1); err != nil {
+		return nil, err
+	}
+	return NewObject(), nil
+}
+
+// string checks a string built by a native function against the limits
+func (rt *Runtime) string(s string) (Value, error) {
+	if err := rt.meter.String(String(s)); err != nil {
+		return nil, err
+	}
+	return String(s), nil
+}
+
+// call calls a value that must be a function
+func (rt *Runtime) call(fn Value, this Value, args ...Value) (Value, error) {
+	f, err := callable(fn)
+	if err != nil {
+		return nil, err
+	}
+	return f.Call(this, args...)
+}
+
+// callable checks that a value passed as a callback is a function
+func callable(v Value) (*Function, error) {
+	f, ok := v.(*Function)
+	if !ok {
+		return nil, typeError("%s is not a function", Inspect(v))
+	}
+	return f, nil
+}
+
+// iterate returns the values a for-of loop over v would visit: the elements of an array,
+// the characters of a string, the values of a set or the [key, value] entries of a map
+func (rt *Runtime) iterate(v Value) ([]Value, error) {
+	switch v := v.(type) {
+	case String:
+		var values []Value
+		for _, r := range string(v) {
+			values = append(values, String(string(r)))
+		}
+		return values, nil
+	case *Object:
+		if v.Class == "Array" {
+			return v.Elements(), nil
+		}
+		if c, ok := v.data.(*collection); ok {
+			var values []Value
+			for _, e := range c.entries {
+				if e.deleted {
+					continue
+				}
+				if v.Class == "Map" {
+					pair, err := rt.array([]Value{e.key, e.value})
+					if err != nil {
+						return nil, err
+					}
+					values = append(values, pair)
+				} else {
+					values = append(values, e.key)
+				}
+			}
+			return values, nil
+		}
+	}
+	return nil, typeError("%s is not iterable", Inspect(v))
+}
+
+// arg returns the argument at index i, undefined if it is missing
+func arg(args []Value, i int) Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return Undefined{}
+}
+
+// typeError creates a TypeError without position, the engine locates it at the call
+func typeError(format string, args ...any) *Error {
+	return errorf("TypeError", format, args...)
+}
+
+// rangeError creates a RangeError without position
+func rangeError(format string, args ...any) *Error {
+	return errorf("RangeError", format, args...)
+}
@@ -0,0 +1,351 @@
+package interp
+
+import (
+	"math"
+	"strings"
+	"unicode/utf16"
+
+	"goast/constant"
+)
+
+// defineString defines String and the methods of String.prototype
+// Indexes and lengths count UTF-16 code units, as in JavaScript; patterns are plain strings
+// since there are no regular expressions
+func (rt *Runtime) defineString() {
+	ctor := rt.constructor("String", 1, func(this Value, args []Value) (Value, error) {
+		if len(args) == 0 {
+			return String(""), nil
+		}
+		return String(ToString(args[0])), nil
+	}, nil)
+	rt.method(&ctor.Object, "fromCharCode", 1, func(this Value, args []Value) (Value, error) {
+		units := make([]uint16, len(args))
+		for i, v := range args {
+			units[i] = uint16(toInt32(ToNumber(v)))
+		}
+		return rt.string(utf16ToString(units))
+	})
+	rt.method(&ctor.Object, "fromCodePoint", 1, func(this Value, args []Value) (Value, error) {
+		var sb strings.Builder
+		for _, v := range args {
+			n := ToNumber(v)
+			if n < 0 || n > 0x10FFFF || n != math.Trunc(n) {
+				return nil, rangeError("Invalid code point %s", ToString(v))
+			}
+			sb.WriteRune(rune(n))
+		}
+		return rt.string(sb.String())
+	})
+
+	proto := rt.protos["String"]
+	method := func(name string, length int, fn func(s string, args []Value) (Value, error)) {
+		rt.method(proto, name, length, func(this Value, args []Value) (Value, error) {
+			if isNullish(this) {
+				return nil, typeError("String.prototype.%s called on null or undefined", name)
+			}
+			return fn(ToString(this), args)
+		})
+	}
+	// unitMethod is method for the methods working on code units
+	unitMethod := func(name string, length int, fn func(units []uint16, args []Value) (Value, error)) {
+		method(name, length, func(s string, args []Value) (Value, error) {
+			return fn(utf16Units(s), args)
+		})
+	}
+
+	method("toString", 0, func(s string, args []Value) (Value, error) { return String(s), nil })
+	method("valueOf", 0, func(s string, args []Value) (Value, error) { return String(s), nil })
+
+	unitMethod("charAt", 1, func(units []uint16, args []Value) (Value, error) {
+		i := toInteger(arg(args, 0))
+		if i < 0 || i >= float64(len(units)) {
+			return String(""), nil
+		}
+		return String(utf16ToString(units[int(i) : int(i)+1])), nil
+	})
+	unitMethod("charCodeAt", 1, func(units []uint16, args []Value) (Value, error) {
+		i := toInteger(arg(args, 0))
+		if i < 0 || i >= float64(len(units)) {
+			return Number(math.NaN()), nil
+		}
+		return Number(units[int(i)]), nil
+	})
+	unitMethod("codePointAt", 1, func(units []uint16, args []Value) (Value, error) {
+		i := toInteger(arg(args, 0))
+		if i < 0 || i >= float64(len(units)) {
+			return Undefined{}, nil
+		}
+		u := units[int(i)]
+		if utf16.IsSurrogate(rune(u)) && int(i)+1 < len(units) {
+			if r := utf16.DecodeRune(rune(u), rune(units[int(i)+1])); r != 0xFFFD {
+				return Number(r), nil
+			}
+		}
+		return Number(u), nil
+	})
+	unitMethod("at", 1, func(units []uint16, args []Value) (Value, error) {
+		i := toInteger(arg(args, 0))
+		if i < 0 {
+			i += float64(len(units))
+		}
+		if i < 0 || i >= float64(len(units)) {
+			return Undefined{}, nil
+		}
+		return String(utf16ToString(units[int(i) : int(i)+1])), nil
+	})
+
+	unitMethod("indexOf", 1, func(units []uint16, args []Value) (Value, error) {
+		from := relativeIndex(Number(math.Max(0, toInteger(arg(args, 1)))), len(units), 0)
+		return Number(indexUnits(units, utf16Units(ToString(arg(args, 0))), from)), nil
+	})
+	unitMethod("lastIndexOf", 1, func(units []uint16, args []Value) (Value, error) {
+		needle := utf16Units(ToString(arg(args, 0)))
+		from := len(units)
+		if n := ToNumber(arg(args, 1)); !math.IsNaN(n) {
+			from = int(math.Max(0, math.Min(math.Trunc(n), float64(len(units)))))
+		}
+		for i := min(from, len(units)-len(needle)); i >= 0; i-- {
+			if unitsHavePrefix(units[i:], needle) {
+				return Number(i), nil
+			}
+		}
+		return Number(-1), nil
+	})
+	unitMethod("includes", 1, func(units []uint16, args []Value) (Value, error) {
+		from := relativeIndex(Number(math.Max(0, toInteger(arg(args, 1)))), len(units), 0)
+		return Boolean(indexUnits(units, utf16Units(ToString(arg(args, 0))), from) >= 0), nil
+	})
+	unitMethod("startsWith", 1, func(units []uint16, args []Value) (Value, error) {
+		from := relativeIndex(Number(math.Max(0, toInteger(arg(args, 1)))), len(units), 0)
+		return Boolean(unitsHavePrefix(units[from:], utf16Units(ToString(arg(args, 0))))), nil
+	})
+	unitMethod("endsWith", 1, func(units []uint16, args []Value) (Value, error) {
+		end := relativeIndex(arg(args, 1), len(units), len(units))
+		if n := toInteger(arg(args, 1)); n < 0 {
+			end = 0 // A negative end is clamped, not counted from the end
+		}
+		needle := utf16Units(ToString(arg(args, 0)))
+		return Boolean(len(needle) <= end && unitsHavePrefix(units[end-len(needle):], needle)), nil
+	})
+
+	unitMethod("slice", 2, func(units []uint16, args []Value) (Value, error) {
+		start := relativeIndex(arg(args, 0), len(units), 0)
+		end := relativeIndex(arg(args, 1), len(units), len(units))
+		if end < start {
+			return String(""), nil
+		}
+		return String(utf16ToString(units[start:end])), nil
+	})
+	unitMethod("substring", 2, func(units []uint16, args []Value) (Value, error) {
+		clamp := func(v Value, def int) int {
+			if _, ok := v.(Undefined); ok {
+				return def
+			}
+			return int(math.Max(0, math.Min(toInteger(v), float64(len(units)))))
+		}
+		start, end := clamp(arg(args, 0), 0), clamp(arg(args, 1), len(units))
+		if start > end {
+			start, end = end, start
+		}
+		return String(utf16ToString(units[start:end])), nil
+	})
+	unitMethod("split", 2, func(units []uint16, args []Value) (Value, error) {
+		limit := math.MaxInt
+		if v := arg(args, 1); v != (Undefined{}) {
+			limit = int(uint32(toInt32(ToNumber(v))))
+		}
+		var parts []Value
+		sepValue := arg(args, 0)
+		switch {
+		case limit == 0:
+		case sepValue == (Undefined{}):
+			parts = []Value{String(utf16ToString(units))}
+		default:
+			sep := utf16Units(ToString(sepValue))
+			if len(sep) == 0 {
+				for i := 0; i < len(units) && len(parts) < limit; i++ {
+					parts = append(parts, String(utf16ToString(units[i:i+1])))
+				}
+				break
+			}
+			start := 0
+			for len(parts) < limit {
+				i := indexUnits(units, sep, start)
+				if i < 0 {
+					parts = append(parts, String(utf16ToString(units[start:])))
+					break
+				}
+				parts = append(parts, String(utf16ToString(units[start:i])))
+				start = i + len(sep)
+			}
+		}
+		return rt.array(parts)
+	})
+
+	method("toUpperCase", 0, func(s string, args []Value) (Value, error) { return rt.string(strings.ToUpper(s)) })
+	method("toLowerCase", 0, func(s string, args []Value) (Value, error) { return rt.string(strings.ToLower(s)) })
+	method("trim", 0, func(s string, args []Value) (Value, error) { return String(strings.TrimFunc(s, constant.IsSpace)), nil })
+	method("trimStart", 0, func(s string, args []Value) (Value, error) {
+		return String(strings.TrimLeftFunc(s, constant.IsSpace)), nil
+	})
+	method("trimEnd", 0, func(s string, args []Value) (Value, error) {
+		return String(strings.TrimRightFunc(s, constant.IsSpace)), nil
+	})
+
+	method("concat", 1, func(s string, args []Value) (Value, error) {
+		var sb strings.Builder
+		sb.WriteString(s)
+		for _, v := range args {
+			sb.WriteString(ToString(v))
+		}
+		return rt.string(sb.String())
+	})
+	method("repeat", 1, func(s string, args []Value) (Value, error) {
+		n := ToNumber(arg(args, 0))
+		if math.IsNaN(n) {
+			n = 0
+		}
+		if n < 0 || math.IsInf(n, 0) {
+			return nil, rangeError("Invalid count value: %s", ToString(arg(args, 0)))
+		}
+		n = math.Trunc(n)
+		if err := rt.meter.reserve(n * float64(len(s))); err != nil {
+			return nil, err
+		}
+		return rt.string(strings.Repeat(s, int(n)))
+	})
+	for _, name := range []string{"padStart", "padEnd"} {
+		unitMethod(name, 2, func(units []uint16, args []Value) (Value, error) {
+			length := toInteger(arg(args, 0))
+			filler := " "
+			if v := arg(args, 1); v != (Undefined{}) {
+				filler = ToString(v)
+			}
+			s := utf16ToString(units)
+			if length <= float64(len(units)) || filler == "" {
+				return String(s), nil
+			}
+			if err := rt.meter.reserve(length - float64(len(units)) + float64(len(s))); err != nil {
+				return nil, err
+			}
+			fill := utf16Units(filler)
+			pad := make([]uint16, 0, int(length)-len(units))
+			for len(pad) < cap(pad) {
+				pad = append(pad, fill[:min(len(fill), cap(pad)-len(pad))]...)
+			}
+			if name == "padStart" {
+				return rt.string(utf16ToString(pad) + s)
+			}
+			return rt.string(s + utf16ToString(pad))
+		})
+	}
+
+	for _, name := range []string{"replace", "replaceAll"} {
+		unitMethod(name, 2, func(units []uint16, args []Value) (Value, error) {
+			if o, ok := arg(args, 0).(*Object); ok && o.Class == "RegExp" {
+				return nil, typeError("regular expressions are not supported")
+			}
+			pattern := utf16Units(ToString(arg(args, 0)))
+			replacement := arg(args, 1)
+			var sb strings.Builder
+			start := 0
+			for i := indexUnits(units, pattern, 0); i >= 0; {
+				var replaced string
+				if f, ok := replacement.(*Function); ok {
+					result, err := f.Call(Undefined{}, String(utf16ToString(pattern)), Number(i), String(utf16ToString(units)))
+					if err != nil {
+						return nil, err
+					}
+					replaced = ToString(result)
+				} else {
+					replaced = expandReplacement(ToString(replacement), units, i, len(pattern))
+				}
+				sb.WriteString(utf16ToString(units[start:i]))
+				sb.WriteString(replaced)
+				start = i + len(pattern)
+				if name == "replace" {
+					break
+				}
+				if len(pattern) == 0 {
+					if i >= len(units) {
+						break
+					}
+					sb.WriteString(utf16ToString(units[i : i+1])) // Empty matches between every unit
+					start = i + 1
+				}
+				i = indexUnits(units, pattern, start)
+				if len(pattern) == 0 && i < start {
+					break
+				}
+			}
+			if start <= len(units) {
+				sb.WriteString(utf16ToString(units[start:]))
+			}
+			return rt.string(sb.String())
+		})
+	}
+	method("localeCompare", 1, func(s string, args []Value) (Value, error) {
+		c := constant.CompareStrings(s, ToString(arg(args, 0)))
+		switch {
+		case c < 0:
+			return Number(-1), nil
+		case c > 0:
+			return Number(1), nil
+		}
+		return Number(0), nil
+	})
+}
+
+// indexUnits returns the index of the first occurrence of needle in units at or after from,
+// -1 if there is none
+func indexUnits(units, needle []uint16, from int) int {
+	for i := from; i+len(needle) <= len(units); i++ {
+		if unitsHavePrefix(units[i:], needle) {
+			return i
+		}
+	}
+	return -1
+}
+
+// unitsHavePrefix reports whether units starts with prefix
+func unitsHavePrefix(units, prefix []uint16) bool {
+	if len(prefix) > len(units) {
+		return false
+	}
+	for i, u := range prefix {
+		if units[i] != u {
+			return false
+		}
+	}
+	return true
+}
+
+// expandReplacement substitutes the patterns of a replacement string for a match of n units
+// at index i: $$ is a dollar sign, $& the match, $` what precedes it and $' what follows it
+func expandReplacement(replacement string, units []uint16, i, n int) string {
+	if !strings.Contains(replacement, "$") {
+		return replacement
+	}
+	var sb strings.Builder
+	for j := 0; j < len(replacement); j++ {
+		if replacement[j] != '$' || j+1 == len(replacement) {
+			sb.WriteByte(replacement[j])
+			continue
+		}
+		switch replacement[j+1] {
+		case '$':
+			sb.WriteByte('$')
+		case '&':
+			sb.WriteString(utf16ToString(units[i : i+n]))
+		case '`':
+			sb.WriteString(utf16ToString(units[:i]))
+		case '\'':
+			sb.WriteString(utf16ToString(units[i+n:]))
+		default:
+			sb.WriteByte('$')
+			continue
+		}
+		j++
+	}
+	return sb.String()
+}
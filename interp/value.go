@@ -0,0 +1,289 @@
+package interp
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"goast/ast"
+	"goast/constant"
+)
+
+// Value is a JavaScript value
+// It is one of Undefined, Null, Boolean, Number, String, *Object or *Function
+type Value interface {
+	TypeOf() string // Result of the typeof operator
+}
+
+// The primitive values are the constant package's, so that constant folding and the
+// interpreter agree on every operator and conversion
+type (
+	Undefined = constant.Undefined // Value of missing variables, arguments and return values
+	Null      = constant.Null      // Intentional absence of an object
+	Boolean   = constant.Boolean   // true or false
+	Number    = constant.Number    // Double-precision floating point number, the only numeric type
+	String    = constant.String    // UTF-8 text measured and compared in UTF-16 code units
+)
+
+// Object is a JavaScript object, a collection of properties
+// Arrays are objects of class "Array" keeping their indexed properties as a list of elements
+// Methods are inherited from the prototype of the object's class in the Runtime running the
+// program, unless Object.create gave the object a prototype of its own
+type Object struct {
+	Class      string // Kind of object shown when it is converted to a string, "Object" for plain ones
+	properties map[string]Value
+	keys       []string // Property names in insertion order
+	elements   []Value  // Elements of an array
+	joining    bool     // Set while the array is converted to a string, which skips cycles
+	frozen     bool     // Set by Object.freeze, assignments are then ignored
+	proto      *Object  // Prototype given by Object.create, nil for the one of the class
+	data       any      // Internal state of dates, maps, sets, promises and errors
+}
+
+// Function is a function declared by a FunctionDeclaration, closing over its scope,
+// or a native function implemented in Go
+// Functions are objects and may carry properties
+type Function struct {
+	Object
+	Name string
+
+	// Native is the Go implementation of a native function, nil for declared functions
+	// this is the receiver of the call, Undefined for a plain call
+	Native func(this Value, args []Value) (Value, error)
+
+	// Construct creates an object for new, nil for functions that are not constructors
+	// Only native functions construct: declared functions cannot refer to this
+	Construct func(args []Value) (Value, error)
+
+	// Length is the length a native function reports, its number of parameters
+	Length int
+
+	decl *ast.FunctionDeclaration
+	env  *environment // Scope the function was declared in
+	src  *source      // Source the declaration comes from, for error positions
+	in   *Interpreter // Interpreter running the declaration
+}
+
+func (*Object) TypeOf() string   { return "object" }
+func (*Function) TypeOf() string { return "function" }
+
+// NewNativeFunction creates a function implemented in Go
+func NewNativeFunction(name string, native func(this Value, args []Value) (Value, error)) *Function {
+	return &Function{Object: Object{Class: "Function"}, Name: name, Native: native}
+}
+
+// Call calls the function with a receiver and arguments, from Go or from a native function
+func (f *Function) Call(this Value, args ...Value) (Value, error) {
+	if f.Native != nil {
+		return f.Native(this, args)
+	}
+	return f.in.call(nil, f, this, args)
+}
+
+// NewObject creates an empty plain object
+func NewObject() *Object {
+	return &Object{Class: "Object"}
+}
+
+// NewArray creates an array holding the given elements
+func NewArray(elements ...Value) *Object {
+	return &Object{Class: "Array", elements: append([]Value{}, elements...)}
+}
+
+// Elements returns the elements of an array, nil for other objects
+func (o *Object) Elements() []Value {
+	return append([]Value(nil), o.elements...)
+}
+
+// Get returns the value of a property, Undefined if there is none
+func (o *Object) Get(key string) Value {
+	if o.Class == "Array" {
+		if key == "length" {
+			return Number(len(o.elements))
+		}
+		if i, ok := arrayIndex(key); ok {
+			if i < len(o.elements) {
+				return o.elements[i]
+			}
+			return Undefined{}
+		}
+	}
+	if v, ok := o.properties[key]; ok {
+		return v
+	}
+	return Undefined{}
+}
+
+// own returns an own property, false if the object has none by that name
+func (o *Object) own(key string) (Value, bool) {
+	if o.Class == "Array" {
+		if key == "length" {
+			return Number(len(o.elements)), true
+		}
+		if i, ok := arrayIndex(key); ok {
+			if i < len(o.elements) {
+				return o.elements[i], true
+			}
+			return nil, false
+		}
+	}
+	v, ok := o.properties[key]
+	return v, ok
+}
+
+// Set creates or updates a property
+// Setting an index past the end of an array grows it with undefined elements, and setting
+// its length truncates or extends it; a frozen object ignores the assignment
+func (o *Object) Set(key string, v Value) {
+	if o.frozen {
+		return
+	}
+	if o.Class == "Array" {
+		if key == "length" {
+			n := ToNumber(v)
+			if n >= 0 && n == math.Trunc(n) && n <= maxArrayLength {
+				o.resize(int(n))
+			}
+			return
+		}
+		if i, ok := arrayIndex(key); ok {
+			if i >= len(o.elements) {
+				o.resize(i + 1)
+			}
+			o.elements[i] = v
+			return
+		}
+	}
+	if o.properties == nil {
+		o.properties = map[string]Value{}
+	}
+	if _, ok := o.properties[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.properties[key] = v
+}
+
+// Keys returns the property names in the order they were created, after the indexes of an array
+func (o *Object) Keys() []string {
+	keys := make([]string, 0, len(o.elements)+len(o.keys))
+	for i := range o.elements {
+		keys = append(keys, strconv.Itoa(i))
+	}
+	return append(keys, o.keys...)
+}
+
+// maxArrayLength bounds the length of arrays, far below JavaScript's 2^32-1 to keep a
+// stray assignment like a[1e9] = 0 from allocating the memory of a billion elements
+const maxArrayLength = 1 << 24
+
+// resize sets the number of elements of an array, new ones undefined
+func (o *Object) resize(n int) {
+	for len(o.elements) < n {
+		o.elements = append(o.elements, Undefined{})
+	}
+	o.elements = o.elements[:n]
+}
+
+// arrayIndex parses a property name that is an array index, a number in canonical form
+// Indexes from maxArrayLength on are ordinary properties
+func arrayIndex(key string) (int, bool) {
+	if key == "" || len(key) > 1 && key[0] == '0' {
+		return 0, false
+	}
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || i >= maxArrayLength || key[0] == '+' {
+		return 0, false
+	}
+	return i, true
+}
+
+// ToBoolean converts a value to a boolean, as an if test does
+// The falsy values are undefined, null, false, 0, -0, NaN and the empty string
+// Like every conversion, it takes a nil Value for undefined
+func ToBoolean(v Value) bool {
+	return constant.Truthy(v)
+}
+
+// ToNumber converts a value to a number
+// Objects are converted through ToPrimitive first, except dates which give their time value
+func ToNumber(v Value) float64 {
+	switch v.(type) {
+	case nil:
+		return math.NaN()
+	case *Object, *Function:
+		return constant.ToNumber(toPrimitiveNumber(v))
+	}
+	return constant.ToNumber(v)
+}
+
+// ToString converts a value to a string
+func ToString(v Value) string {
+	return constant.ToString(ToPrimitive(v))
+}
+
+// ToPrimitive converts an object to a primitive value, primitives are returned unchanged
+// Without user-defined valueOf and toString methods, objects become "[object Class]",
+// arrays their elements joined with commas, errors "Name: message", dates their date
+// string and functions their source text
+func ToPrimitive(v Value) Value {
+	switch v := v.(type) {
+	case *Object:
+		switch data := v.data.(type) {
+		case *date:
+			return String(data.String())
+		case errorData:
+			return String(errorString(v))
+		}
+		if v.Class == "Array" {
+			return String(v.join(","))
+		}
+		return String("[object " + v.Class + "]")
+	case *Function:
+		return String(v.sourceText())
+	case nil:
+		return Undefined{}
+	}
+	return v
+}
+
+// toPrimitiveNumber is ToPrimitive preferring numbers, as arithmetic and comparisons do:
+// the only difference is that dates become their time value
+func toPrimitiveNumber(v Value) Value {
+	if o, ok := v.(*Object); ok {
+		if d, ok := o.data.(*date); ok {
+			return Number(d.ms)
+		}
+	}
+	return ToPrimitive(v)
+}
+
+// join converts the elements of an array to strings and joins them with sep
+// undefined and null become empty strings, and so does an array containing itself
+func (o *Object) join(sep string) string {
+	if o.joining {
+		return ""
+	}
+	o.joining = true
+	defer func() { o.joining = false }()
+	parts := make([]string, len(o.elements))
+	for i, v := range o.elements {
+		if !isNullish(v) {
+			parts[i] = ToString(v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// sourceText returns the source code of a function, as Function.prototype.toString does
+func (f *Function) sourceText() string {
+	if f.decl != nil && f.src != nil && f.decl.Span.IsValid() && f.decl.Span.End <= len(f.src.text) {
+		return f.src.text[f.decl.Span.Start:f.decl.Span.End]
+	}
+	return "function " + f.Name + "() { [native code] }"
+}
+
+// utf16Units returns the UTF-16 code units of a string, the units JavaScript strings are made of
+func utf16Units(s string) []uint16 {
+	return utf16.Encode([]rune(s))
+}
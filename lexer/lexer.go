@@ -0,0 +1,318 @@
+// Package lexer breaks JavaScript source code into tokens
+package lexer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token represents a lexical token in our JavaScript parser
+// Type is the token category (like "FUNCTION", "IDENTIFIER", etc.)
+// Value stores the actual text from the source code
+// Start and End are byte offsets into the source, Line and Column are 1-based
+type Token struct {
+	Type   string
+	Value  string
+	Start  int // Offset of the first character of the token
+	End    int // Offset just past the last character of the token
+	Line   int // Line on which the token starts
+	Column int // Column (in bytes) at which the token starts
+}
+
+// Lexer breaks input source code into tokens
+// It scans through the input character by character to identify tokens
+type Lexer struct {
+	input     string  // The full source code text being analyzed
+	pos       int     // Current position in the input (points to current character)
+	tokens    []Token // Collection of tokens found so far
+	line      int     // Line number reached by the position tracking
+	lineStart int     // Offset of the first character of that line
+	scanned   int     // Offset up to which newlines have been counted
+	errors    []*Error
+}
+
+// Error is a problem found while tokenizing, such as a string missing its closing quote
+// The lexer still produces a token for the faulty input so that parsing can go on
+type Error struct {
+	Message string
+	Token   Token // Token holding the faulty input, of type ILLEGAL for characters no token starts with, which are left out of the stream
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: syntax error: %s", e.Token.Line, e.Token.Column, e.Message)
+}
+
+// Errors returns the problems found by the last call to Tokenize, in source order
+func (l *Lexer) Errors() []*Error {
+	return l.errors
+}
+
+// NewLexer creates a new lexer instance with the given input
+// This is a constructor function that initializes a lexer ready for tokenization
+func NewLexer(input string) *Lexer {
+	return &Lexer{
+		input:  input,
+		pos:    0,         // Start at the beginning of input
+		tokens: []Token{}, // Empty token list
+		line:   1,         // Lines are counted from 1
+	}
+}
+
+// Tokenize processes the entire input and converts it to tokens
+// This is the main lexical analysis function that identifies all tokens in the source
+func (l *Lexer) Tokenize() []Token {
+	// Loop through the entire input
+	for l.pos < len(l.input) {
+		char := l.input[l.pos]
+
+		// Skip whitespace (spaces, tabs, newlines)
+		// Whitespace generally has no semantic meaning in JavaScript
+		if char < utf8.RuneSelf && unicode.IsSpace(rune(char)) {
+			l.pos++
+			continue
+		}
+
+		// Handle single-line comments (// comment)
+		// Comments are preserved in our AST for documentation purposes
+		if char == '/' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '/' {
+			// Skip to end of line
+			start := l.pos
+			for l.pos < len(l.input) && l.input[l.pos] != '\n' {
+				l.pos++
+			}
+			l.addToken("COMMENT", l.input[start:l.pos], start)
+			continue
+		}
+
+		// Handle identifiers and keywords
+		// Identifiers include variable names, function names, etc.
+		// Keywords are reserved words like 'function', 'return', etc.
+		if isAlpha(char) {
+			start := l.pos
+			// Collect all alphanumeric characters that form this identifier
+			for l.pos < len(l.input) && (isAlpha(l.input[l.pos]) || isDigit(l.input[l.pos])) {
+				l.pos++
+			}
+			value := l.input[start:l.pos]
+
+			// Check if the identifier is actually a keyword
+			tokenType := "IDENTIFIER"
+			switch value {
+			case "function":
+				tokenType = "FUNCTION" // Function declaration keyword
+			case "return":
+				tokenType = "RETURN" // Return statement keyword
+			case "const":
+				tokenType = "CONST" // Constant variable declaration
+			case "let":
+				tokenType = "LET" // Block-scoped variable declaration
+			case "var":
+				tokenType = "VAR" // Function-scoped variable declaration
+			case "if":
+				tokenType = "IF" // If statement keyword
+			case "true", "false":
+				tokenType = "BOOLEAN" // Boolean literal
+			case "null":
+				tokenType = "NULL" // Null literal
+			case "new":
+				tokenType = "NEW" // Constructor call
+			}
+
+			l.addToken(tokenType, value, start)
+			continue
+		}
+
+		// Handle string literals ("string" or 'string')
+		if char == '"' || char == '\'' {
+			quote := char
+			start := l.pos
+			l.pos++ // Skip the opening quote
+			// Continue until finding the matching closing quote
+			for l.pos < len(l.input) && l.input[l.pos] != quote && l.input[l.pos] != '\n' {
+				if l.input[l.pos] == '\\' && l.pos+1 < len(l.input) {
+					l.pos++ // An escaped character never closes the string
+				}
+				l.pos++
+			}
+			// Skip the closing quote; a string cannot span lines, so without one it ends with
+			// its line or the input
+			terminated := l.pos < len(l.input) && l.input[l.pos] == quote
+			if terminated {
+				l.pos++
+			}
+			l.addToken("STRING", l.input[start:l.pos], start)
+			if !terminated {
+				l.errorf("unterminated string literal")
+			}
+			continue
+		}
+
+		// Handle special characters and syntax elements
+		switch char {
+		case '(':
+			l.addToken("LEFT_PAREN", "(", l.pos)
+		case ')':
+			l.addToken("RIGHT_PAREN", ")", l.pos)
+		case '{':
+			l.addToken("LEFT_BRACE", "{", l.pos)
+		case '}':
+			l.addToken("RIGHT_BRACE", "}", l.pos)
+		case ';':
+			l.addToken("SEMICOLON", ";", l.pos)
+		case ',':
+			l.addToken("COMMA", ",", l.pos)
+		case '.':
+			l.addToken("DOT", ".", l.pos)
+		case '[':
+			l.addToken("LEFT_BRACKET", "[", l.pos)
+		case ']':
+			l.addToken("RIGHT_BRACKET", "]", l.pos)
+		case ':':
+			l.addToken("COLON", ":", l.pos)
+		case '=':
+			// Check for strict equality (===) and equality (==)
+			if strings.HasPrefix(l.input[l.pos:], "===") {
+				l.addToken("STRICT_EQUALITY", "===", l.pos)
+				l.pos += 2 // Skip the other two '=' characters
+			} else if l.pos+1 < len(l.input) && l.input[l.pos+1] == '=' {
+				l.addToken("EQUALITY", "==", l.pos)
+				l.pos++ // Skip the next '=' since we're handling both at once
+			} else {
+				l.addToken("EQUALS", "=", l.pos)
+			}
+		case '!':
+			// Only the inequality operators (!== and !=) are supported, a lone '!' is an error
+			if strings.HasPrefix(l.input[l.pos:], "!==") {
+				l.addToken("STRICT_INEQUALITY", "!==", l.pos)
+				l.pos += 2
+			} else if strings.HasPrefix(l.input[l.pos:], "!=") {
+				l.addToken("INEQUALITY", "!=", l.pos)
+				l.pos++
+			} else {
+				l.illegal()
+				continue
+			}
+		case '>':
+			// Check for greater than or equal (>=)
+			if l.pos+1 < len(l.input) && l.input[l.pos+1] == '=' {
+				l.addToken("GREATER_EQUAL", ">=", l.pos)
+				l.pos++ // Skip the next '=' since we're handling both at once
+			} else {
+				l.addToken("GREATER_THAN", ">", l.pos)
+			}
+		case '<':
+			// Check for less than or equal (<=)
+			if l.pos+1 < len(l.input) && l.input[l.pos+1] == '=' {
+				l.addToken("LESS_EQUAL", "<=", l.pos)
+				l.pos++ // Skip the next '=' since we're handling both at once
+			} else {
+				l.addToken("LESS_THAN", "<", l.pos)
+			}
+		case '+':
+			l.addToken("PLUS", "+", l.pos)
+		case '-':
+			l.addToken("MINUS", "-", l.pos)
+		case '*':
+			l.addToken("MULTIPLY", "*", l.pos)
+		case '/':
+			// Check if it's a comment (already handled above) or division
+			if l.pos+1 < len(l.input) && l.input[l.pos+1] == '/' {
+				// This is a comment, skip it (already handled in comment section)
+				l.pos++
+				continue
+			} else {
+				l.addToken("DIVIDE", "/", l.pos)
+			}
+		case '%':
+			l.addToken("MODULO", "%", l.pos)
+		default:
+			// Handle numeric literals (including decimals)
+			if isDigit(char) {
+				start := l.pos
+				for l.pos < len(l.input) && isDigit(l.input[l.pos]) {
+					l.pos++
+				}
+				// Handle decimal part if present
+				if l.pos < len(l.input) && l.input[l.pos] == '.' {
+					l.pos++ // Skip the decimal point
+					// Consume digits after decimal point
+					for l.pos < len(l.input) && isDigit(l.input[l.pos]) {
+						l.pos++
+					}
+				}
+				l.addToken("NUMBER", l.input[start:l.pos], start)
+				continue
+			}
+
+			// Skip unknown characters, reporting all but the other Unicode spaces
+			l.illegal()
+			continue
+		}
+		l.pos++
+	}
+
+	// Add an EOF (End Of File) token to indicate the end of input
+	l.addToken("EOF", "", l.pos)
+	return l.tokens
+}
+
+// addToken appends a token starting at the given offset
+// Positions are derived from the offset so every token can be traced back to the source
+func (l *Lexer) addToken(tokenType, value string, start int) {
+	l.tokens = append(l.tokens, l.token(tokenType, value, start))
+}
+
+// token builds a token starting at the given offset, which must not precede the last token
+func (l *Lexer) token(tokenType, value string, start int) Token {
+	// Count the newlines between the previous token and this one
+	for ; l.scanned < start && l.scanned < len(l.input); l.scanned++ {
+		if l.input[l.scanned] == '\n' {
+			l.line++
+			l.lineStart = l.scanned + 1
+		}
+	}
+	return Token{
+		Type:   tokenType,
+		Value:  value,
+		Start:  start,
+		End:    start + len(value),
+		Line:   l.line,
+		Column: start - l.lineStart + 1,
+	}
+}
+
+// illegal skips the character at the current position, which no token can start with, and
+// reports it unless it is a space outside ASCII, like a no-break space
+func (l *Lexer) illegal() {
+	r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+	start := l.pos
+	l.pos += size
+	if unicode.IsSpace(r) {
+		return
+	}
+	message := fmt.Sprintf("unexpected character %q", r)
+	if r == utf8.RuneError && size == 1 {
+		message = fmt.Sprintf("invalid UTF-8 byte 0x%02x", l.input[start])
+	}
+	tok := l.token("ILLEGAL", l.input[start:l.pos], start)
+	l.errors = append(l.errors, &Error{Message: message, Token: tok})
+}
+
+// errorf reports a problem with the last token added
+func (l *Lexer) errorf(format string, args ...any) {
+	l.errors = append(l.errors, &Error{Message: fmt.Sprintf(format, args...), Token: l.tokens[len(l.tokens)-1]})
+}
+
+// isAlpha checks if a character is alphabetic, an underscore or a dollar sign
+// Used to determine the start of identifiers
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$'
+}
+
+// isDigit checks if a character is a numeric digit
+// Used for the non-first characters of identifiers
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
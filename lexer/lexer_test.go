@@ -0,0 +1,99 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+)
+
+// FuzzTokenize checks that tokenizing any input terminates with well-formed tokens:
+// in source order, inside the input, and ending with a single EOF
+func FuzzTokenize(f *testing.F) {
+	for _, seed := range []string{
+		"const sum = 10 + 5;",
+		"function f(a, b = 1) {\n  return a === b;\n}",
+		"\"unterminated",
+		"'unterminated\nconst a = 1;",
+		"\"escaped \\\" quote\"",
+		"\"ends with a backslash\\",
+		"// comment without newline",
+		"a @ b # c",
+		"1.2.3",
+		strings.Repeat("(", 1000),
+		strings.Repeat("{[", 500),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		tokens := NewLexer(src).Tokenize()
+		if len(tokens) == 0 || tokens[len(tokens)-1].Type != "EOF" {
+			t.Fatalf("tokens do not end with EOF: %v", tokens)
+		}
+		end := 0
+		for i, tok := range tokens {
+			if tok.Type == "EOF" && i != len(tokens)-1 {
+				t.Fatalf("EOF token at %d before the end", i)
+			}
+			if tok.Start < end || tok.End < tok.Start || tok.End > len(src) {
+				t.Fatalf("token %d %+v out of order or outside the input", i, tok)
+			}
+			if tok.Type != "EOF" && src[tok.Start:tok.End] != tok.Value {
+				t.Fatalf("token %d %+v does not match the source %q", i, tok, src[tok.Start:tok.End])
+			}
+			end = tok.End
+		}
+	})
+}
+
+func TestUnterminatedString(t *testing.T) {
+	tests := []struct {
+		src    string
+		errors []string
+	}{
+		{`"abc"`, nil},
+		{`"abc`, []string{"1:1: syntax error: unterminated string literal"}},
+		{"const a = 'abc;\nconst b = 'x';", []string{"1:11: syntax error: unterminated string literal"}},
+		{"\"a\\\nb\"", nil}, // An escaped newline continues the string
+	}
+	for _, tt := range tests {
+		lx := NewLexer(tt.src)
+		lx.Tokenize()
+		var got []string
+		for _, err := range lx.Errors() {
+			got = append(got, err.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.errors, "\n") {
+			t.Errorf("%q: errors %q, want %q", tt.src, got, tt.errors)
+		}
+	}
+}
+
+func TestIllegalCharacters(t *testing.T) {
+	tests := []struct {
+		src    string
+		errors []string
+	}{
+		{"const a = 1 @ 2;", []string{"1:13: syntax error: unexpected character '@'"}},
+		{"a\n!b", []string{"2:1: syntax error: unexpected character '!'"}},
+		{"a # b é", []string{"1:3: syntax error: unexpected character '#'", "1:7: syntax error: unexpected character 'é'"}},
+		{"a\xffb", []string{"1:2: syntax error: invalid UTF-8 byte 0xff"}},
+		{"a\u00a0b\u2003c", nil}, // Unicode spaces separate tokens
+		{"'@' // @", nil},
+		{"const $a = _b$1;", nil}, // $ is a letter in names
+	}
+	for _, tt := range tests {
+		lx := NewLexer(tt.src)
+		tokens := lx.Tokenize()
+		var got []string
+		for _, err := range lx.Errors() {
+			got = append(got, err.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.errors, "\n") {
+			t.Errorf("%q: errors %q, want %q", tt.src, got, tt.errors)
+		}
+		for _, tok := range tokens {
+			if tok.Type == "ILLEGAL" {
+				t.Errorf("%q: ILLEGAL token in the stream", tt.src)
+			}
+		}
+	}
+}
@@ -0,0 +1,474 @@
+package lint
+
+import (
+	"strings"
+
+	"goast/ast"
+	"goast/cfg"
+	"goast/dataflow"
+	"goast/scope"
+)
+
+// builtinGlobals are the globals every JavaScript environment provides, plus console
+var builtinGlobals = []string{
+	"Array", "Boolean", "Date", "Error", "Infinity", "JSON", "Map", "Math", "NaN", "Number",
+	"Object", "Promise", "RegExp", "Set", "String", "Symbol", "console", "eval", "globalThis",
+	"isFinite", "isNaN", "parseFloat", "parseInt", "undefined",
+}
+
+func init() {
+	Register(noUnusedVars{}, Warning)
+	Register(noUndef{}, Error)
+	Register(eqeqeq{}, Warning)
+	Register(noUnreachable{}, Warning)
+	Register(noConstantCondition{}, Warning)
+	Register(consistentReturn{}, Warning)
+	Register(noUselessAssignment{}, Warning)
+	Register(noUseBeforeDefine{}, Warning)
+	Register(noDupeParams{}, Error)
+	Register(noConstAssign{}, Error)
+	Register(noShadow{}, Warning)
+	Register(noVar{}, Warning)
+}
+
+// noUnusedVars reports variables, functions and parameters that are never read
+// Like ESLint's default "after-used", a parameter is only reported when no later parameter is used
+// Local variables that are never mentioned again are removed by the fix, provided their
+// initializer has no side effects; globals may be used by other scripts and are kept
+type noUnusedVars struct{}
+
+func (noUnusedVars) Name() string        { return "no-unused-vars" }
+func (noUnusedVars) Description() string { return "disallow variables that are never used" }
+
+func (noUnusedVars) Check(ctx *Context, node ast.Node) {
+	if _, ok := node.(*ast.Program); !ok {
+		return
+	}
+	for _, s := range ctx.Scopes.Scopes {
+		// Parameters before the last used one are needed to reach it
+		lastUsed := -1
+		if fn, ok := s.Node.(*ast.FunctionDeclaration); ok && s.Kind == scope.Function {
+			for i := range fn.Params {
+				if v := s.Variable(fn.Params[i].Name); v != nil && isRead(v) {
+					lastUsed = i
+				}
+			}
+		}
+
+		for _, v := range s.Variables {
+			if isRead(v) {
+				continue
+			}
+			if param, ok := v.Declarations[0].(*ast.Parameter); ok && paramIndex(s, param) < lastUsed {
+				continue
+			}
+			fix := removeUnused(ctx, v)
+			if isAssigned(v) {
+				ctx.ReportFix(nameSpan(v.Declarations[0]), fix, "'%s' is assigned a value but never used", v.Name)
+			} else {
+				ctx.ReportFix(nameSpan(v.Declarations[0]), fix, "'%s' is defined but never used", v.Name)
+			}
+		}
+	}
+}
+
+// removeUnused returns the fix deleting the declaration of an unused variable, or nil if
+// deleting it could change what the program does
+func removeUnused(ctx *Context, v *scope.Variable) Fix {
+	if v.Scope.Kind == scope.Global || v.Scope.Kind == scope.Module || len(v.Declarations) != 1 || len(v.References) > 0 {
+		return nil
+	}
+	decl, ok := v.Declarations[0].(*ast.VariableDeclaration)
+	if !ok || !decl.Span.IsValid() || decl.Span.End > len(ctx.Source) || !pure(decl.Value) {
+		return nil
+	}
+	return Fix{removeStatement(ctx.Source, decl.Span)}
+}
+
+// pure reports whether evaluating an expression has no side effects
+// Reading a variable is considered pure, assignments, calls and new are not
+func pure(node ast.Node) bool {
+	if node == nil {
+		return true
+	}
+	result := true
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BinaryExpression:
+			if n.Operator == "=" {
+				result = false
+			}
+		case *ast.CallExpression, *ast.NewExpression, *ast.InvalidExpression, *ast.ErrorNode:
+			result = false
+		}
+		return result
+	})
+	return result
+}
+
+// isRead reports whether a variable is read anywhere
+func isRead(v *scope.Variable) bool {
+	for _, ref := range v.References {
+		if !ref.Write {
+			return true
+		}
+	}
+	return false
+}
+
+// isAssigned reports whether a variable gets a value, from its declaration or an assignment
+func isAssigned(v *scope.Variable) bool {
+	for _, decl := range v.Declarations {
+		if d, ok := decl.(*ast.VariableDeclaration); ok && d.Value != nil {
+			return true
+		}
+	}
+	return len(v.References) > 0
+}
+
+// paramIndex returns the position of param in the parameter list of the function of s
+func paramIndex(s *scope.Scope, param *ast.Parameter) int {
+	fn := s.Node.(*ast.FunctionDeclaration)
+	for i := range fn.Params {
+		if &fn.Params[i] == param {
+			return i
+		}
+	}
+	return -1
+}
+
+// nameSpan returns the location of the name introduced by a declaration
+func nameSpan(decl ast.Node) ast.Span {
+	switch d := decl.(type) {
+	case *ast.VariableDeclaration:
+		return d.NameSpan
+	case *ast.FunctionDeclaration:
+		return d.NameSpan
+	case *ast.Parameter:
+		return d.NameSpan
+	}
+	return decl.Range()
+}
+
+// noUndef reports references to names that are neither declared nor known globals
+type noUndef struct{}
+
+func (noUndef) Name() string        { return "no-undef" }
+func (noUndef) Description() string { return "disallow the use of undeclared variables" }
+
+func (noUndef) Check(ctx *Context, node ast.Node) {
+	if _, ok := node.(*ast.Program); !ok {
+		return
+	}
+	for _, ref := range ctx.Scopes.Unresolved {
+		if !ctx.Globals[ref.Identifier.Name] {
+			ctx.Report(ref.Identifier.Span, "'%s' is not defined", ref.Identifier.Name)
+		}
+	}
+}
+
+// eqeqeq reports == and !=, whose type coercions are a common source of bugs
+// The fix switches to the strict operator, which changes the result when the operands
+// have different types; that is usually the bug being fixed, but deserves a review
+type eqeqeq struct{}
+
+func (eqeqeq) Name() string        { return "eqeqeq" }
+func (eqeqeq) Description() string { return "require the use of === and !==" }
+
+func (eqeqeq) Check(ctx *Context, node ast.Node) {
+	n, ok := node.(*ast.BinaryExpression)
+	if !ok || n.Operator != "==" && n.Operator != "!=" {
+		return
+	}
+	span, ok := operatorSpan(ctx.Source, n)
+	if !ok {
+		ctx.Report(n.Span, "Expected '%s=' and instead saw '%s'", n.Operator, n.Operator)
+		return
+	}
+	fix := Fix{{Span: span, Text: n.Operator + "="}}
+	ctx.ReportFix(span, fix, "Expected '%s=' and instead saw '%s'", n.Operator, n.Operator)
+}
+
+// operatorSpan locates the operator of a binary expression in the source
+// The AST does not record it, so it is searched between the operands
+func operatorSpan(src string, n *ast.BinaryExpression) (ast.Span, bool) {
+	start, end := n.Left.Range().End, n.Right.Range().Start
+	if n.Left.Range().IsValid() && start <= end && end <= len(src) {
+		if i := strings.Index(src[start:end], n.Operator); i >= 0 {
+			return ast.Span{Start: start + i, End: start + i + len(n.Operator)}, true
+		}
+	}
+	return ast.Span{}, false
+}
+
+// noUnreachable reports statements control never reaches, those following a return in the
+// same block, using the control-flow graph of each function
+// Function declarations are hoisted and are not reported, nor are comments
+type noUnreachable struct{}
+
+func (noUnreachable) Name() string        { return "no-unreachable" }
+func (noUnreachable) Description() string { return "disallow unreachable code after return" }
+
+func (noUnreachable) Check(ctx *Context, node ast.Node) {
+	switch node.(type) {
+	case *ast.Program, *ast.FunctionDeclaration:
+		// One report covers the rest of the block
+		for _, stmt := range ctx.Graph(node).Unreachable() {
+			ctx.Report(stmt.Range(), "Unreachable code")
+		}
+	}
+}
+
+// noConstantCondition reports if statements whose test is always truthy or always falsy,
+// so that one of their paths is never taken
+type noConstantCondition struct{}
+
+func (noConstantCondition) Name() string        { return "no-constant-condition" }
+func (noConstantCondition) Description() string { return "disallow constant expressions in conditions" }
+
+func (noConstantCondition) Check(ctx *Context, node ast.Node) {
+	switch node.(type) {
+	case *ast.Program, *ast.FunctionDeclaration:
+		for _, n := range ctx.Graph(node).ConstantConditions() {
+			truthy, _ := cfg.ConstantTest(n.Test)
+			value := "falsy"
+			if truthy {
+				value = "truthy"
+			}
+			ctx.Report(n.Test.Range(), "Unexpected constant condition, always %s", value)
+		}
+	}
+}
+
+// consistentReturn reports functions that return a value on some paths and reach the end of
+// their body on others, returning undefined without saying so
+type consistentReturn struct{}
+
+func (consistentReturn) Name() string { return "consistent-return" }
+func (consistentReturn) Description() string {
+	return "require functions returning a value to return one on every path"
+}
+
+func (consistentReturn) Check(ctx *Context, node ast.Node) {
+	if f, ok := node.(*ast.FunctionDeclaration); ok && ctx.Graph(f).MissingReturn() {
+		ctx.Report(f.NameSpan, "Expected to return a value at the end of function '%s'", f.Name)
+	}
+}
+
+// noUselessAssignment reports values stored in a variable that no path reads before the
+// variable is written again or the function returns, found by liveness analysis
+type noUselessAssignment struct{}
+
+func (noUselessAssignment) Name() string { return "no-useless-assignment" }
+func (noUselessAssignment) Description() string {
+	return "disallow assignments whose value is never read"
+}
+
+func (noUselessAssignment) Check(ctx *Context, node ast.Node) {
+	switch node.(type) {
+	case *ast.Program, *ast.FunctionDeclaration:
+		f := dataflow.New(ctx.Graph(node), ctx.Scopes)
+		for _, a := range f.UselessAssignments() {
+			ctx.Report(a.Span, "The value assigned to '%s' is never read", f.Variables[a.Variable].Name)
+		}
+	}
+}
+
+// noUseBeforeDefine reports reads of local variables that some path reaches before the
+// variable is written, found by definite assignment analysis
+// A var is undefined there, and reading a let or a const throws a ReferenceError
+type noUseBeforeDefine struct{}
+
+func (noUseBeforeDefine) Name() string { return "no-use-before-define" }
+func (noUseBeforeDefine) Description() string {
+	return "disallow reading variables before they are defined"
+}
+
+func (noUseBeforeDefine) Check(ctx *Context, node ast.Node) {
+	switch node.(type) {
+	case *ast.Program, *ast.FunctionDeclaration:
+		f := dataflow.New(ctx.Graph(node), ctx.Scopes)
+		for _, use := range f.EarlyUses() {
+			name := f.Variables[use.Variable].Name
+			if use.Always {
+				ctx.Report(use.Span, "'%s' is used before it is defined", name)
+			} else {
+				ctx.Report(use.Span, "'%s' may be used before it is defined", name)
+			}
+		}
+	}
+}
+
+// noDupeParams reports functions with two parameters of the same name
+type noDupeParams struct{}
+
+func (noDupeParams) Name() string        { return "no-dupe-params" }
+func (noDupeParams) Description() string { return "disallow duplicate parameter names" }
+
+func (noDupeParams) Check(ctx *Context, node ast.Node) {
+	fn, ok := node.(*ast.FunctionDeclaration)
+	if !ok {
+		return
+	}
+	seen := map[string]bool{}
+	for _, param := range fn.Params {
+		if seen[param.Name] {
+			ctx.Report(param.NameSpan, "Duplicate param '%s'", param.Name)
+		}
+		seen[param.Name] = true
+	}
+}
+
+// noConstAssign reports assignments to constants
+type noConstAssign struct{}
+
+func (noConstAssign) Name() string        { return "no-const-assign" }
+func (noConstAssign) Description() string { return "disallow reassigning const variables" }
+
+func (noConstAssign) Check(ctx *Context, node ast.Node) {
+	if _, ok := node.(*ast.Program); !ok {
+		return
+	}
+	for _, ref := range ctx.Scopes.References {
+		if ref.Write && ref.Resolved != nil && ref.Resolved.Kind == "const" {
+			ctx.Report(ref.Identifier.Span, "'%s' is constant", ref.Identifier.Name)
+		}
+	}
+}
+
+// noShadow reports declarations hiding a variable of an enclosing scope
+type noShadow struct{}
+
+func (noShadow) Name() string { return "no-shadow" }
+func (noShadow) Description() string {
+	return "disallow declarations that shadow variables of an enclosing scope"
+}
+
+func (noShadow) Check(ctx *Context, node ast.Node) {
+	if _, ok := node.(*ast.Program); !ok {
+		return
+	}
+	for _, s := range ctx.Scopes.Scopes {
+		if s.Parent == nil {
+			continue
+		}
+		for _, v := range s.Variables {
+			if outer := s.Parent.Lookup(v.Name); outer != nil {
+				ctx.Report(nameSpan(v.Declarations[0]), "'%s' is already declared in the upper scope", v.Name)
+			}
+		}
+	}
+}
+
+// noVar reports var declarations, whose function scoping and hoisting surprise readers
+// The fix turns them into const when the variable is initialized and never reassigned, and
+// into let otherwise, but only when every use follows the declaration inside its block,
+// where block scoping cannot change the meaning, and no use is in a function that may be
+// called before the declaration runs
+type noVar struct{}
+
+func (noVar) Name() string        { return "no-var" }
+func (noVar) Description() string { return "require let or const instead of var" }
+
+func (noVar) Check(ctx *Context, node ast.Node) {
+	var list []ast.Node
+	switch n := node.(type) {
+	case *ast.Program:
+		list = n.Body
+	case *ast.FunctionDeclaration:
+		list = n.Body
+	case *ast.IfStatement:
+		list = n.Consequent
+	default:
+		return
+	}
+	block := ctx.Scopes.Scope(node)
+
+	for _, stmt := range list {
+		decl, ok := stmt.(*ast.VariableDeclaration)
+		if !ok || decl.Kind != "var" {
+			continue
+		}
+		keyword := ast.Span{Start: decl.Span.Start, End: decl.Span.Start + len("var")}
+		fix := Fix(nil)
+		if kind := blockScopedKind(ctx, block, decl); kind != "" && keyword.End <= len(ctx.Source) && ctx.Source[keyword.Start:keyword.End] == "var" {
+			fix = Fix{{Span: keyword, Text: kind}}
+		}
+		ctx.ReportFix(keyword, fix, "Unexpected var, use let or const instead")
+	}
+}
+
+// blockScopedKind returns the declaration kind that can replace var for decl in block,
+// or "" if converting it is unsafe
+func blockScopedKind(ctx *Context, block *scope.Scope, decl *ast.VariableDeclaration) string {
+	if block == nil {
+		return ""
+	}
+	v := block.FunctionScope().Variable(decl.Name)
+	if v == nil || len(v.Declarations) != 1 {
+		return "" // Redeclarations are an error with let and const
+	}
+	if block.Kind != scope.Function && block.Kind != scope.Global && block.Kind != scope.Module {
+		// A var in a block moves into the block, it must not collide with the enclosing scope
+		if outer := block.Parent.Lookup(decl.Name); outer != nil && outer != v {
+			return ""
+		}
+	}
+	if block.Kind == scope.Global && ctx.Globals[decl.Name] {
+		return ""
+	}
+
+	written := false
+	top := block.FunctionScope()
+	for _, ref := range v.References {
+		if !within(ref.From, block) || ref.Identifier.Span.Start < decl.Span.End {
+			return ""
+		}
+		if fn := ref.From.FunctionScope(); fn != top && !calledAfter(fn, top, decl) {
+			return "" // The function may run before the declaration, where let would throw
+		}
+		written = written || ref.Write
+	}
+	if decl.Value != nil && !written {
+		return "const"
+	}
+	return "let"
+}
+
+// calledAfter reports whether the function of scope fn, nested in the function scope top,
+// can only run after decl does
+// Function declarations are hoisted, so a function declared after decl can still be called
+// before it: this holds only if the outermost function around fn is referred to after decl,
+// directly from top, where the order of the source is the order of execution
+func calledAfter(fn, top *scope.Scope, decl *ast.VariableDeclaration) bool {
+	for fn.Parent.FunctionScope() != top {
+		fn = fn.Parent.FunctionScope()
+	}
+	f, ok := fn.Node.(*ast.FunctionDeclaration)
+	if !ok {
+		return false
+	}
+	v := fn.Parent.Lookup(f.Name)
+	if v == nil {
+		return false
+	}
+	for _, ref := range v.References {
+		if within(ref.From, fn) {
+			continue // Recursive calls run once the function was called from outside
+		}
+		if ref.From.FunctionScope() != top || ref.Identifier.Span.Start < decl.Span.End {
+			return false
+		}
+	}
+	return true
+}
+
+// within reports whether s is inner or one of its enclosing scopes is
+func within(s, outer *scope.Scope) bool {
+	for ; s != nil; s = s.Parent {
+		if s == outer {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package lint
+
+import (
+	"testing"
+)
+
+func TestNoVarFix(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		fix  string // Replacement for var, "" if the fix must be refused
+	}{
+		{"initialized", "var x = 1;\nconsole.log(x);\n", "const"},
+		{"reassigned", "var x = 1;\nx = 2;\nconsole.log(x);\n", "let"},
+		{"used before", "console.log(x);\nvar x = 1;\n", ""},
+		{"hoisted function called before", "g();\nvar x = 1;\nfunction g() {\n  return x;\n}\n", ""},
+		{"hoisted function called after", "var x = 1;\nfunction g() {\n  return x;\n}\ng();\n", "const"},
+		{"called through another function", "h();\nvar x = 1;\nfunction g() {\n  return x;\n}\nfunction h() {\n  return g();\n}\n", ""},
+		{"called from a function", "var x = 1;\nfunction g() {\n  return x;\n}\nfunction h() {\n  return g();\n}\nh();\n", ""},
+		{"recursive", "var x = 1;\nfunction g(n) {\n  if (n) {\n    return g(0);\n  }\n  return x;\n}\ng(1);\n", "const"},
+	}
+	cfg := only("no-var")
+
+	for _, tt := range tests {
+		diagnostics, err := Source("test.js", tt.src, cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(diagnostics) != 1 {
+			t.Fatalf("%s: got %d diagnostics, want 1", tt.name, len(diagnostics))
+		}
+		fix := ""
+		if f := diagnostics[0].Fix; len(f) == 1 {
+			fix = f[0].Text
+		}
+		if fix != tt.fix {
+			t.Errorf("%s: fix = %q, want %q", tt.name, fix, tt.fix)
+		}
+	}
+}
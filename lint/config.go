@@ -0,0 +1,101 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Severity tells how a rule's reports are treated
+type Severity int
+
+const (
+	Off     Severity = iota // The rule does not run
+	Warning                 // Reports are shown but do not fail the run
+	Error                   // Reports make the run fail
+)
+
+// String returns the name used for the severity in configuration files
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warn"
+	case Error:
+		return "error"
+	}
+	return "off"
+}
+
+// UnmarshalJSON accepts the names "off", "warn" and "error" as well as
+// ESLint's numbers 0, 1 and 2
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var number int
+		if err := json.Unmarshal(data, &number); err != nil || number < 0 || number > 2 {
+			return fmt.Errorf("invalid severity %s, expected \"off\", \"warn\" or \"error\"", data)
+		}
+		*s = Severity(number)
+		return nil
+	}
+	switch strings.ToLower(name) {
+	case "off":
+		*s = Off
+	case "warn", "warning":
+		*s = Warning
+	case "error":
+		*s = Error
+	default:
+		return fmt.Errorf("invalid severity %q, expected \"off\", \"warn\" or \"error\"", name)
+	}
+	return nil
+}
+
+// MarshalJSON writes the severity by name
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Config selects the rules to run
+// A nil *Config is valid and runs every rule with its default severity
+//
+// In a configuration file it looks like
+//
+//	{
+//	  "rules": {"eqeqeq": "error", "no-shadow": "off"},
+//	  "globals": ["require", "module"]
+//	}
+type Config struct {
+	Rules   map[string]Severity `json:"rules"`   // Severity per rule, rules not listed keep their default
+	Globals []string            `json:"globals"` // Globals available besides the standard built-ins
+}
+
+// LoadConfig reads a configuration file
+// Unknown rule names are reported, since a typo would otherwise silently disable nothing
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for name := range cfg.Rules {
+		if _, ok := registry[name]; !ok {
+			return nil, fmt.Errorf("%s: unknown rule %q", path, name)
+		}
+	}
+	return &cfg, nil
+}
+
+// severity returns the configured severity of a rule
+func (c *Config) severity(name string) Severity {
+	if c != nil {
+		if s, ok := c.Rules[name]; ok {
+			return s
+		}
+	}
+	return DefaultSeverity(name)
+}
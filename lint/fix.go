@@ -0,0 +1,105 @@
+package lint
+
+import (
+	"sort"
+	"strings"
+
+	"goast/ast"
+)
+
+// maxFixPasses bounds the lint and fix cycles of FixSource, fixes that keep producing
+// new problems would otherwise loop forever
+const maxFixPasses = 10
+
+// Edit replaces the source text covered by Span with Text
+// An empty span inserts, an empty text deletes
+type Edit struct {
+	Span ast.Span
+	Text string
+}
+
+// Fix is the set of edits correcting one problem, applied all together or not at all
+type Fix []Edit
+
+// span returns the smallest span covering every edit of the fix
+func (f Fix) span() ast.Span {
+	s := f[0].Span
+	for _, e := range f[1:] {
+		s.Start = min(s.Start, e.Span.Start)
+		s.End = max(s.End, e.Span.End)
+	}
+	return s
+}
+
+// ApplyFixes applies the fixes of diagnostics to src and returns the result with the number
+// of fixes applied
+// Fixes are taken in source order; a fix overlapping one already taken is left for a later pass
+func ApplyFixes(src string, diagnostics []Diagnostic) (string, int) {
+	var fixes []Fix
+	for _, d := range diagnostics {
+		if len(d.Fix) > 0 {
+			fixes = append(fixes, d.Fix)
+		}
+	}
+	sort.SliceStable(fixes, func(i, j int) bool { return fixes[i].span().Start < fixes[j].span().Start })
+
+	var edits []Edit
+	applied, end := 0, 0
+	for _, fix := range fixes {
+		span := fix.span()
+		if span.Start < end || span.End > len(src) {
+			continue
+		}
+		edits = append(edits, fix...)
+		end = max(span.End, span.Start+1) // Two insertions at the same offset would be ambiguous
+		applied++
+	}
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Span.Start < edits[j].Span.Start })
+
+	var out strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.Span.Start < last {
+			continue // Edits of one fix overlapping each other, keep the first
+		}
+		out.WriteString(src[last:e.Span.Start])
+		out.WriteString(e.Text)
+		last = e.Span.End
+	}
+	out.WriteString(src[last:])
+	return out.String(), applied
+}
+
+// FixSource lints src and applies the fixes, then lints the result again until no fix applies
+// It returns the fixed source with the problems remaining in it
+func FixSource(filename, src string, cfg *Config) (string, []Diagnostic, error) {
+	for pass := 0; ; pass++ {
+		diagnostics, err := Source(filename, src, cfg)
+		if err != nil {
+			return src, nil, err
+		}
+		if pass == maxFixPasses {
+			return src, diagnostics, nil
+		}
+		fixed, applied := ApplyFixes(src, diagnostics)
+		if applied == 0 {
+			return src, diagnostics, nil
+		}
+		src = fixed
+	}
+}
+
+// removeStatement returns the edit deleting a statement
+// When the statement is alone on its line, the whole line goes, indentation and newline included
+func removeStatement(src string, span ast.Span) Edit {
+	start, end := span.Start, span.End
+	lineStart := strings.LastIndexByte(src[:start], '\n') + 1
+	lineEnd := len(src)
+	if i := strings.IndexByte(src[end:], '\n'); i >= 0 {
+		lineEnd = end + i
+	}
+	if strings.TrimSpace(src[lineStart:start]) == "" && strings.TrimSpace(src[end:lineEnd]) == "" {
+		start, end = lineStart, min(lineEnd+1, len(src))
+	}
+	return Edit{Span: ast.Span{Start: start, End: end}}
+}
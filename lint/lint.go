@@ -0,0 +1,153 @@
+// Package lint finds likely mistakes in JavaScript programs, in the spirit of ESLint
+// Checks are Rules registered in a registry (see builtin.go for the built-in ones), each
+// with a Severity that a Config can change; a "// goast-disable-next-line" comment silences
+// reports on the line after it
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"goast/ast"
+	"goast/cfg"
+	"goast/parser"
+	"goast/scope"
+)
+
+// Diagnostic is a problem reported by a rule
+type Diagnostic struct {
+	Filename string
+	Line     int // 1-based, 0 if the position is unknown
+	Column   int // 1-based
+	Span     ast.Span
+	Rule     string
+	Severity Severity
+	Message  string
+	Fix      Fix // Edits correcting the problem, nil if the rule cannot fix it
+}
+
+// Error formats the diagnostic like the parser's syntax errors:
+// "file:line:col: severity: message (rule)"
+func (d Diagnostic) Error() string {
+	severity := "warning"
+	if d.Severity == Error {
+		severity = "error"
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", d.Filename, d.Line, d.Column, severity, d.Message, d.Rule)
+}
+
+// disableComment is the prefix of comments silencing the next line
+const disableComment = "goast-disable-next-line"
+
+// Source lints JavaScript source code with the rules enabled by cfg
+// Syntax errors are not diagnostics: parsing stops Source, which returns the parser's
+// ErrorList and no diagnostics
+func Source(filename, src string, cfg *Config) ([]Diagnostic, error) {
+	program, err := parser.ParseFile(filename, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Program(filename, src, program, cfg), nil
+}
+
+// Program lints a parsed program
+// src is the source it was parsed from, needed for line numbers; the diagnostics are
+// sorted by position
+func Program(filename, src string, program *ast.Program, cfg *Config) []Diagnostic {
+	l := &linter{lines: ast.NewLines(src)}
+
+	globals := map[string]bool{}
+	for _, name := range builtinGlobals {
+		globals[name] = true
+	}
+	if cfg != nil {
+		for _, name := range cfg.Globals {
+			globals[name] = true
+		}
+	}
+
+	analysis := scope.Analyze(program, nil)
+	var contexts []*Context
+	for _, rule := range Rules() {
+		severity := cfg.severity(rule.Name())
+		if severity == Off {
+			continue
+		}
+		contexts = append(contexts, &Context{
+			Filename: filename,
+			Source:   src,
+			Program:  program,
+			Scopes:   analysis,
+			Globals:  globals,
+			rule:     rule.Name(),
+			severity: severity,
+			linter:   l,
+		})
+	}
+
+	l.disabled = disabledLines(program, l)
+	ast.Inspect(program, func(node ast.Node) bool {
+		for _, ctx := range contexts {
+			registry[ctx.rule].rule.Check(ctx, node)
+		}
+		return true
+	})
+
+	for i := range l.diagnostics {
+		l.diagnostics[i].Filename = filename
+	}
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		return l.diagnostics[i].Span.Start < l.diagnostics[j].Span.Start
+	})
+	return l.diagnostics
+}
+
+// linter holds the state of one Program call
+type linter struct {
+	lines       ast.Lines               // Line table of the source
+	disabled    map[int]map[string]bool // Rules disabled per line, an empty set disables all
+	diagnostics []Diagnostic
+	graphs      map[ast.Node]*cfg.Graph // Control-flow graphs built for Context.Graph
+}
+
+// report adds a diagnostic unless a disable comment silences it
+func (l *linter) report(d Diagnostic) {
+	if d.Span.IsValid() {
+		d.Line, d.Column = l.lines.Position(d.Span.Start)
+	}
+	if rules, ok := l.disabled[d.Line]; ok && (len(rules) == 0 || rules[d.Rule]) {
+		return
+	}
+	l.diagnostics = append(l.diagnostics, d)
+}
+
+// disabledLines reads the disable comments of a program
+// "// goast-disable-next-line" silences every rule on the next line,
+// "// goast-disable-next-line eqeqeq, no-undef" only the listed ones
+func disabledLines(program *ast.Program, l *linter) map[int]map[string]bool {
+	disabled := map[int]map[string]bool{}
+	ast.Inspect(program, func(node ast.Node) bool {
+		comment, ok := node.(*ast.Comment)
+		if !ok || !comment.Span.IsValid() {
+			return true
+		}
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		rest, ok := strings.CutPrefix(text, disableComment)
+		if !ok || rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+			return true
+		}
+		line, _ := l.lines.Position(comment.Span.Start)
+		rules := map[string]bool{}
+		// Anything after "--" is an explanation, as in ESLint
+		rest, _, _ = strings.Cut(rest, "--")
+		for _, name := range strings.Split(rest, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				rules[name] = true
+			}
+		}
+		disabled[line+1] = rules
+		return true
+	})
+	return disabled
+}
@@ -0,0 +1,282 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"goast/ast"
+)
+
+// only returns a configuration running the named rules and none of the others
+func only(names ...string) *Config {
+	cfg := &Config{Rules: map[string]Severity{}}
+	for _, rule := range Rules() {
+		cfg.Rules[rule.Name()] = Off
+	}
+	for _, name := range names {
+		cfg.Rules[name] = Warning
+	}
+	return cfg
+}
+
+// format lists diagnostics as the command prints them, one per line
+func format(diagnostics []Diagnostic) string {
+	var lines []string
+	for _, d := range diagnostics {
+		lines = append(lines, d.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TestRules runs each built-in rule alone on a program it reports and on one it accepts
+func TestRules(t *testing.T) {
+	tests := []struct {
+		rule  string
+		src   string
+		want  string // Diagnostics of the rule, one per line
+		clean string // A similar program the rule accepts
+	}{
+		{
+			"no-unused-vars",
+			"function f(a, b, c) {\n  let x = 1;\n  let y;\n  return b;\n}\nf();\n",
+			"test.js:1:18: warning: 'c' is defined but never used (no-unused-vars)\n" +
+				"test.js:2:7: warning: 'x' is assigned a value but never used (no-unused-vars)\n" +
+				"test.js:3:7: warning: 'y' is defined but never used (no-unused-vars)",
+			"function f(a, b) {\n  return b;\n}\nf();\n",
+		},
+		{
+			"no-undef",
+			"console.log(x);\ny = Math.max(1, 2);\n",
+			"test.js:1:13: warning: 'x' is not defined (no-undef)\n" +
+				"test.js:2:1: warning: 'y' is not defined (no-undef)",
+			"let x = 1;\nconsole.log(x, Math.max(1, 2));\n",
+		},
+		{
+			"eqeqeq",
+			"if (a == 1) {\n  log(a != null);\n}\n",
+			"test.js:1:7: warning: Expected '===' and instead saw '==' (eqeqeq)\n" +
+				"test.js:2:9: warning: Expected '!==' and instead saw '!=' (eqeqeq)",
+			"if (a === 1) {\n  log(a !== null);\n}\n",
+		},
+		{
+			"no-unreachable",
+			"function f() {\n  return 1;\n  log(2);\n  function g() {}\n}\n",
+			"test.js:3:3: warning: Unreachable code (no-unreachable)",
+			"function f() {\n  if (x) {\n    return 1;\n  }\n  log(2);\n}\n",
+		},
+		{
+			"no-constant-condition",
+			"if (1) {\n  log(1);\n}\nif (\"\") {\n  log(2);\n}\n",
+			"test.js:1:5: warning: Unexpected constant condition, always truthy (no-constant-condition)\n" +
+				"test.js:4:5: warning: Unexpected constant condition, always falsy (no-constant-condition)",
+			"if (x) {\n  log(1);\n}\n",
+		},
+		{
+			"consistent-return",
+			"function f(x) {\n  if (x) {\n    return 1;\n  }\n}\n",
+			"test.js:1:10: warning: Expected to return a value at the end of function 'f' (consistent-return)",
+			"function f(x) {\n  if (x) {\n    return 1;\n  }\n  return 0;\n}\n",
+		},
+		{
+			"no-useless-assignment",
+			"function f() {\n  let x = 1;\n  x = 2;\n  return x;\n}\n",
+			"test.js:2:7: warning: The value assigned to 'x' is never read (no-useless-assignment)",
+			"function f() {\n  let x = 1;\n  log(x);\n  x = 2;\n  return x;\n}\n",
+		},
+		{
+			"no-use-before-define",
+			"function f(c) {\n  log(x);\n  var x = 1;\n  var y;\n  if (c) {\n    y = 2;\n  }\n  return y;\n}\n",
+			"test.js:2:7: warning: 'x' is used before it is defined (no-use-before-define)\n" +
+				"test.js:8:10: warning: 'y' may be used before it is defined (no-use-before-define)",
+			"function f(c) {\n  var x = 1;\n  log(x);\n}\n",
+		},
+		{
+			"no-dupe-params",
+			"function f(a, b, a) {\n  return a;\n}\n",
+			"test.js:1:18: warning: Duplicate param 'a' (no-dupe-params)",
+			"function f(a, b) {\n  return a;\n}\n",
+		},
+		{
+			"no-const-assign",
+			"const a = 1;\na = 2;\n",
+			"test.js:2:1: warning: 'a' is constant (no-const-assign)",
+			"let a = 1;\na = 2;\n",
+		},
+		{
+			"no-shadow",
+			"let a = 1;\nfunction f(a) {\n  let f = a;\n  return f;\n}\n",
+			"test.js:2:12: warning: 'a' is already declared in the upper scope (no-shadow)\n" +
+				"test.js:3:7: warning: 'f' is already declared in the upper scope (no-shadow)",
+			"let a = 1;\nfunction f(b) {\n  let g = b;\n  return g;\n}\n",
+		},
+		{
+			"no-var",
+			"var a = 1;\nfunction f() {\n  var b;\n}\n",
+			"test.js:1:1: warning: Unexpected var, use let or const instead (no-var)\n" +
+				"test.js:3:3: warning: Unexpected var, use let or const instead (no-var)",
+			"let a = 1;\nfunction f() {\n  const b = 2;\n}\n",
+		},
+	}
+	tested := map[string]bool{}
+	for _, tt := range tests {
+		tested[tt.rule] = true
+		cfg := only(tt.rule)
+		diagnostics, err := Source("test.js", tt.src, cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.rule, err)
+		}
+		if got := format(diagnostics); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.rule, got, tt.want)
+		}
+		diagnostics, err = Source("test.js", tt.clean, cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.rule, err)
+		}
+		if len(diagnostics) != 0 {
+			t.Errorf("%s: clean program reported\n%s", tt.rule, format(diagnostics))
+		}
+	}
+	for _, rule := range Rules() {
+		if !tested[rule.Name()] {
+			t.Errorf("rule %s has no test", rule.Name())
+		}
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	src := "x == 1;\n"
+	diagnostics, _ := Source("test.js", src, nil)
+	if got, want := format(diagnostics), "test.js:1:1: error: 'x' is not defined (no-undef)\n"+
+		"test.js:1:3: warning: Expected '===' and instead saw '==' (eqeqeq)"; got != want {
+		t.Errorf("default severities: got\n%s\nwant\n%s", got, want)
+	}
+	diagnostics, _ = Source("test.js", src, &Config{Rules: map[string]Severity{"no-undef": Off, "eqeqeq": Error}})
+	if got, want := format(diagnostics), "test.js:1:3: error: Expected '===' and instead saw '==' (eqeqeq)"; got != want {
+		t.Errorf("configured severities: got\n%s\nwant\n%s", got, want)
+	}
+	diagnostics, _ = Source("test.js", src, &Config{Globals: []string{"x"}})
+	if len(diagnostics) != 1 || diagnostics[0].Rule != "eqeqeq" {
+		t.Errorf("configured global: got\n%s", format(diagnostics))
+	}
+}
+
+func TestDisableComments(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string // Rules still reported, by line
+	}{
+		{"all rules", "// goast-disable-next-line\nx == 1;\n", ""},
+		{"listed rule", "// goast-disable-next-line eqeqeq\nx == 1;\n", "2:no-undef"},
+		{"several rules", "// goast-disable-next-line eqeqeq, no-undef\nx == 1;\n", ""},
+		{"other rule", "// goast-disable-next-line no-var\nx == 1;\n", "2:no-undef 2:eqeqeq"},
+		{"explanation", "// goast-disable-next-line no-undef -- defined by the page\nx == 1;\n", "2:eqeqeq"},
+		{"next line only", "// goast-disable-next-line\nx == 1;\ny == 2;\n", "3:no-undef 3:eqeqeq"},
+		{"not a disable comment", "// goast-disable-next-lines\nx == 1;\n", "2:no-undef 2:eqeqeq"},
+		{"inside a function", "function f() {\n  // goast-disable-next-line eqeqeq\n  return x == 1;\n}\nf();\n", "3:no-undef"},
+	}
+	cfg := only("no-undef", "eqeqeq")
+	for _, tt := range tests {
+		diagnostics, err := Source("test.js", tt.src, cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, d := range diagnostics {
+			got = append(got, fmt.Sprintf("%d:%s", d.Line, d.Rule))
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+// TestFixSource checks the output of -fix: the source with every fixable problem corrected,
+// over as many passes as needed, and the problems left in it
+func TestFixSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  []string
+		src    string
+		fixed  string
+		remain string // Rules of the problems left, by line
+	}{
+		{
+			"eqeqeq", []string{"eqeqeq"},
+			"if (a == 1) {\n  log(a != b, a === c);\n}\n",
+			"if (a === 1) {\n  log(a !== b, a === c);\n}\n",
+			"",
+		},
+		{
+			"no-var", []string{"no-var"},
+			"var a = 1;\nvar b = 1;\nb = 2;\nlog(c);\nvar c = 3;\nlog(a, b);\n",
+			"const a = 1;\nlet b = 1;\nb = 2;\nlog(c);\nvar c = 3;\nlog(a, b);\n",
+			"5:no-var",
+		},
+		{
+			"unused local", []string{"no-unused-vars"},
+			"function f() {\n  let x = 1;\n  let y = g();\n  const z = 2; log(z);\n  return 0;\n}\nf();\n",
+			"function f() {\n  let y = g();\n  const z = 2; log(z);\n  return 0;\n}\nf();\n",
+			"2:no-unused-vars", // y, moved up by the removal of x
+		},
+		{
+			"unused global kept", []string{"no-unused-vars"},
+			"let x = 1;\n",
+			"let x = 1;\n",
+			"1:no-unused-vars",
+		},
+		{
+			// Removing b leaves a unused, which the second pass removes
+			"several passes", []string{"no-unused-vars"},
+			"function f() {\n  let a = 1;\n  let b = a;\n  return 0;\n}\nf();\n",
+			"function f() {\n  return 0;\n}\nf();\n",
+			"",
+		},
+		{
+			"rules together", []string{"eqeqeq", "no-var", "no-unused-vars"},
+			"function f(x) {\n  var unused = 2;\n  var y = x == 1;\n  return y;\n}\nf(1);\n",
+			"function f(x) {\n  const y = x === 1;\n  return y;\n}\nf(1);\n",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		fixed, diagnostics, err := FixSource("test.js", tt.src, only(tt.rules...))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if fixed != tt.fixed {
+			t.Errorf("%s: fixed source\n%s\nwant\n%s", tt.name, fixed, tt.fixed)
+		}
+		var remain []string
+		for _, d := range diagnostics {
+			remain = append(remain, fmt.Sprintf("%d:%s", d.Line, d.Rule))
+		}
+		if strings.Join(remain, " ") != tt.remain {
+			t.Errorf("%s: remaining problems %q, want %q", tt.name, strings.Join(remain, " "), tt.remain)
+		}
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	span := func(start, end int) ast.Span { return ast.Span{Start: start, End: end} }
+	src := "abcdef"
+	diagnostics := []Diagnostic{
+		{Fix: Fix{{Span: span(1, 3), Text: "X"}}},
+		{Fix: Fix{{Span: span(2, 4), Text: "Y"}}}, // Overlaps the first, left for a later pass
+		{Fix: Fix{{Span: span(4, 4), Text: "+"}, {Span: span(5, 6)}}},
+		{}, // No fix
+	}
+	fixed, applied := ApplyFixes(src, diagnostics)
+	if fixed != "aXd+e" || applied != 2 {
+		t.Errorf("got %q with %d fixes, want %q with 2", fixed, applied, "aXd+e")
+	}
+}
+
+func TestFixSyntaxError(t *testing.T) {
+	src := "let a = ;\n"
+	fixed, diagnostics, err := FixSource("test.js", src, nil)
+	if err == nil || fixed != src || diagnostics != nil {
+		t.Errorf("got %q, %v, %v; want the source unchanged and the syntax error", fixed, diagnostics, err)
+	}
+}
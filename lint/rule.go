@@ -0,0 +1,95 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"goast/ast"
+	"goast/cfg"
+	"goast/scope"
+)
+
+// Rule is a check run by the linter
+// Check is called for every node of the program in source order, starting with the
+// Program itself, which is where rules looking at the whole program do their work
+type Rule interface {
+	Name() string        // Name used in configuration files and disable comments, such as "eqeqeq"
+	Description() string // One line describing what the rule reports
+	Check(ctx *Context, node ast.Node)
+}
+
+// registered is a rule together with the severity it has unless configured otherwise
+type registered struct {
+	rule     Rule
+	severity Severity
+}
+
+// registry holds every known rule by name
+var registry = map[string]registered{}
+
+// Register makes a rule available to the linter with a default severity
+// It panics if a rule with the same name is already registered
+func Register(rule Rule, severity Severity) {
+	if _, ok := registry[rule.Name()]; ok {
+		panic(fmt.Sprintf("lint: rule %q registered twice", rule.Name()))
+	}
+	registry[rule.Name()] = registered{rule, severity}
+}
+
+// Rules returns every registered rule sorted by name
+func Rules() []Rule {
+	rules := make([]Rule, 0, len(registry))
+	for _, r := range registry {
+		rules = append(rules, r.rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name() < rules[j].Name() })
+	return rules
+}
+
+// DefaultSeverity returns the severity of a rule when no configuration mentions it
+func DefaultSeverity(name string) Severity {
+	return registry[name].severity
+}
+
+// Context gives a rule access to the program being linted and collects its reports
+type Context struct {
+	Filename string
+	Source   string
+	Program  *ast.Program
+	Scopes   *scope.Analysis // Scope analysis of Program
+	Globals  map[string]bool // Names of globals the environment provides
+
+	rule     string
+	severity Severity
+	linter   *linter
+}
+
+// Graph returns the control-flow graph of a FunctionDeclaration or of the Program, built
+// once for all the rules asking for it
+func (c *Context) Graph(node ast.Node) *cfg.Graph {
+	g, ok := c.linter.graphs[node]
+	if !ok {
+		if c.linter.graphs == nil {
+			c.linter.graphs = map[ast.Node]*cfg.Graph{}
+		}
+		g = cfg.New(node)
+		c.linter.graphs[node] = g
+	}
+	return g
+}
+
+// Report records a problem at span
+func (c *Context) Report(span ast.Span, format string, args ...any) {
+	c.ReportFix(span, nil, format, args...)
+}
+
+// ReportFix records a problem at span together with the edits fixing it
+func (c *Context) ReportFix(span ast.Span, fix Fix, format string, args ...any) {
+	c.linter.report(Diagnostic{
+		Rule:     c.rule,
+		Severity: c.severity,
+		Message:  fmt.Sprintf(format, args...),
+		Span:     span,
+		Fix:      fix,
+	})
+}
@@ -0,0 +1,257 @@
+// Package metrics measures the size and complexity of JavaScript code
+// Every function of a file gets its cyclomatic and cognitive complexity, its maximum nesting
+// depth, its parameter count, its lines of code and its Halstead measures; the file gets the
+// same measures for all of its code
+package metrics
+
+import (
+	"fmt"
+	"math"
+
+	"goast/ast"
+	"goast/lexer"
+	"goast/parser"
+)
+
+// File holds the measures of a file and of each of its functions, in source order
+// Its complexities count the decision points of its top-level code and of all its functions,
+// and its nesting is the deepest found in any of them
+type File struct {
+	Filename  string      `json:"file"`
+	Functions []*Function `json:"functions"`
+	Measures
+}
+
+// Function holds the measures of a function declaration
+type Function struct {
+	Name   string `json:"name"`
+	Line   int    `json:"line"`   // Position of the function name, 1-based
+	Column int    `json:"column"` // Column in bytes, 1-based
+	Params int    `json:"params"`
+	Measures
+}
+
+// Measures are the measures common to functions and files
+// Complexity and nesting only count the code of a function itself, nested functions being
+// measured on their own, while lines and Halstead measures cover its whole text
+type Measures struct {
+	Cyclomatic int      `json:"cyclomatic"` // Independent paths: 1 plus the decision points
+	Cognitive  int      `json:"cognitive"`  // How hard the control flow is to follow
+	Nesting    int      `json:"nesting"`    // Deepest nesting of blocks, 0 for straight-line code
+	Lines      int      `json:"loc"`        // Lines holding code, blank and comment lines excluded
+	Halstead   Halstead `json:"halstead"`
+}
+
+// Halstead holds the Halstead measures of a run of tokens
+// Literals and identifiers are operands; keywords, punctuation and operators are operators,
+// each pair of brackets counting once
+type Halstead struct {
+	DistinctOperators int     `json:"distinctOperators"` // n1
+	DistinctOperands  int     `json:"distinctOperands"`  // n2
+	Operators         int     `json:"operators"`         // N1
+	Operands          int     `json:"operands"`          // N2
+	Vocabulary        int     `json:"vocabulary"`        // n1 + n2
+	Length            int     `json:"length"`            // N1 + N2
+	Volume            float64 `json:"volume"`            // Length × log2(Vocabulary)
+	Difficulty        float64 `json:"difficulty"`        // n1/2 × N2/n2
+	Effort            float64 `json:"effort"`            // Difficulty × Volume
+}
+
+// Source measures JavaScript source code, one File with the measures of every function
+// Code that does not parse has no functions to measure, so the parser's ErrorList is
+// returned instead
+func Source(filename, src string) (*File, error) {
+	program, err := parser.ParseFile(filename, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Program(filename, src, program), nil
+}
+
+// Program measures a parsed program
+// src is the source it was parsed from, tokenized again for the size measures
+func Program(filename, src string, program *ast.Program) *File {
+	tokens := lexer.NewLexer(src).Tokenize()
+	file := &File{Filename: filename}
+
+	top := &counter{}
+	top.statements(program.Body)
+	total := *top
+	ast.Inspect(program, func(node ast.Node) bool {
+		fn, ok := node.(*ast.FunctionDeclaration)
+		if !ok {
+			return true
+		}
+		c := &counter{}
+		c.statements(fn.Body)
+		total.decisions += c.decisions
+		total.cognitive += c.cognitive
+		total.nesting = max(total.nesting, c.nesting)
+
+		own := within(tokens, fn.Span)
+		line, column := position(tokens, fn.NameSpan.Start)
+		file.Functions = append(file.Functions, &Function{
+			Name:     fn.Name,
+			Line:     line,
+			Column:   column,
+			Params:   len(fn.Params),
+			Measures: c.measures(own),
+		})
+		return true
+	})
+	file.Measures = total.measures(tokens)
+	return file
+}
+
+// within returns the tokens inside a span
+func within(tokens []lexer.Token, span ast.Span) []lexer.Token {
+	var inside []lexer.Token
+	for _, tok := range tokens {
+		if tok.Start >= span.Start && tok.End <= span.End {
+			inside = append(inside, tok)
+		}
+	}
+	return inside
+}
+
+// position returns the line and column of the token starting at an offset
+func position(tokens []lexer.Token, offset int) (int, int) {
+	for _, tok := range tokens {
+		if tok.Start == offset {
+			return tok.Line, tok.Column
+		}
+	}
+	return 0, 0
+}
+
+// counter computes the complexity and nesting of the code of one function, or of the top
+// level of a program
+//
+// Cyclomatic complexity counts a decision for every if. Loops, switch cases, conditional
+// expressions and the logical operators &&, || and ?? would count too, but the parser has
+// none of them, so if is the only decision point there is.
+// Cognitive complexity follows SonarSource's definition: an if costs 1 plus the number of
+// blocks it is nested in
+type counter struct {
+	decisions int
+	cognitive int
+	nesting   int // Deepest depth reached
+	depth     int // Blocks the current statement is nested in
+}
+
+// statements counts the statements of a block
+func (c *counter) statements(list []ast.Node) {
+	for _, stmt := range list {
+		c.statement(stmt)
+	}
+}
+
+// statement counts a statement, nested blocks included
+// Function declarations are measured on their own
+func (c *counter) statement(node ast.Node) {
+	if n, ok := node.(*ast.IfStatement); ok {
+		c.decisions++
+		c.cognitive += 1 + c.depth
+		c.depth++
+		c.nesting = max(c.nesting, c.depth)
+		c.statements(n.Consequent)
+		c.depth--
+	}
+}
+
+// measures combines the counts with the size measures of the tokens of the code
+func (c *counter) measures(tokens []lexer.Token) Measures {
+	return Measures{
+		Cyclomatic: 1 + c.decisions,
+		Cognitive:  c.cognitive,
+		Nesting:    c.nesting,
+		Lines:      lines(tokens),
+		Halstead:   halstead(tokens),
+	}
+}
+
+// lines counts the lines on which a token of code starts
+func lines(tokens []lexer.Token) int {
+	seen := map[int]bool{}
+	for _, tok := range tokens {
+		if tok.Type != "COMMENT" && tok.Type != "EOF" {
+			seen[tok.Line] = true
+		}
+	}
+	return len(seen)
+}
+
+// halstead computes the Halstead measures of a run of tokens
+func halstead(tokens []lexer.Token) Halstead {
+	var h Halstead
+	operators, operands := map[string]bool{}, map[string]bool{}
+	for _, tok := range tokens {
+		switch tok.Type {
+		case "COMMENT", "EOF", "RIGHT_PAREN", "RIGHT_BRACE", "RIGHT_BRACKET":
+		case "IDENTIFIER", "NUMBER", "STRING", "BOOLEAN", "NULL":
+			h.Operands++
+			operands[tok.Value] = true
+		default:
+			h.Operators++
+			operators[tok.Value] = true
+		}
+	}
+	h.DistinctOperators, h.DistinctOperands = len(operators), len(operands)
+	h.Vocabulary = h.DistinctOperators + h.DistinctOperands
+	h.Length = h.Operators + h.Operands
+	if h.Vocabulary > 0 {
+		h.Volume = float64(h.Length) * math.Log2(float64(h.Vocabulary))
+	}
+	if h.DistinctOperands > 0 {
+		h.Difficulty = float64(h.DistinctOperators) / 2 * float64(h.Operands) / float64(h.DistinctOperands)
+	}
+	h.Effort = h.Difficulty * h.Volume
+	return h
+}
+
+// Limits are the largest measures a function may have; zero means no limit
+type Limits struct {
+	Cyclomatic int
+	Cognitive  int
+	Nesting    int
+	Params     int
+	Lines      int
+}
+
+// Violation is a measure of a function over its limit
+type Violation struct {
+	Function *Function
+	Measure  string // "cyclomatic complexity", "cognitive complexity", "nesting depth", "parameters" or "lines of code"
+	Value    int
+	Max      int
+}
+
+func (v Violation) String() string {
+	switch v.Measure {
+	case "parameters", "lines of code":
+		return fmt.Sprintf("function '%s' has %d %s (max %d)", v.Function.Name, v.Value, v.Measure, v.Max)
+	}
+	return fmt.Sprintf("function '%s' has a %s of %d (max %d)", v.Function.Name, v.Measure, v.Value, v.Max)
+}
+
+// Check returns the measures of the functions of a file over the limits, in source order
+func (f *File) Check(limits Limits) []Violation {
+	var violations []Violation
+	for _, fn := range f.Functions {
+		for _, m := range []struct {
+			measure    string
+			value, max int
+		}{
+			{"cyclomatic complexity", fn.Cyclomatic, limits.Cyclomatic},
+			{"cognitive complexity", fn.Cognitive, limits.Cognitive},
+			{"nesting depth", fn.Nesting, limits.Nesting},
+			{"parameters", fn.Params, limits.Params},
+			{"lines of code", fn.Lines, limits.Lines},
+		} {
+			if m.max > 0 && m.value > m.max {
+				violations = append(violations, Violation{Function: fn, Measure: m.measure, Value: m.value, Max: m.max})
+			}
+		}
+	}
+	return violations
+}
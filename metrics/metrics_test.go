@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// measure measures a program, failing the test on syntax errors
+func measure(t *testing.T, src string) *File {
+	t.Helper()
+	file, err := Source("test.js", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// TestMeasures checks the complexity, nesting, size and position of single functions
+func TestMeasures(t *testing.T) {
+	tests := []struct {
+		name, src                    string
+		cyclomatic, cognitive, depth int
+		lines, params                int
+	}{
+		{"straight line", "function f(a, b) {\n  let c = a + b;\n  return c;\n}\n", 1, 0, 0, 4, 2},
+		{"one if", "function f(a) {\n  if (a) {\n    return 1;\n  }\n  return 0;\n}\n", 2, 1, 1, 6, 1},
+		{"two ifs", "function f(a) {\n  if (a) {\n    log(1);\n  }\n  if (a > 1) {\n    log(2);\n  }\n}\n", 3, 2, 1, 8, 1},
+		{"nested ifs", "function f(a) {\n  if (a) {\n    if (a > 1) {\n      if (a > 2) {\n        log(3);\n      }\n    }\n  }\n}\n", 4, 6, 3, 9, 1},
+		{"comments and blank lines", "function f() {\n  // nothing\n\n  return;\n}\n", 1, 0, 0, 3, 0},
+		{"nested function", "function f(a) {\n  function g(b) {\n    if (b) {\n      return 1;\n    }\n  }\n  return g(a);\n}\n", 1, 0, 0, 8, 1},
+	}
+	for _, test := range tests {
+		file := measure(t, test.src)
+		fn := file.Functions[0]
+		got := []int{fn.Cyclomatic, fn.Cognitive, fn.Nesting, fn.Lines, fn.Params}
+		want := []int{test.cyclomatic, test.cognitive, test.depth, test.lines, test.params}
+		for i, measure := range []string{"cyclomatic", "cognitive", "nesting", "lines", "params"} {
+			if got[i] != want[i] {
+				t.Errorf("%s: %s = %d, want %d", test.name, measure, got[i], want[i])
+			}
+		}
+		if fn.Name != "f" || fn.Line != 1 || fn.Column != 10 {
+			t.Errorf("%s: got %s at %d:%d, want f at 1:10", test.name, fn.Name, fn.Line, fn.Column)
+		}
+	}
+}
+
+// TestFileMeasures checks that the file adds up the decisions of its top level and of every
+// function and keeps the deepest nesting
+func TestFileMeasures(t *testing.T) {
+	src := "if (x) {\n  log(x);\n}\nfunction f(a) {\n  if (a) {\n    if (a > 1) {\n      return 1;\n    }\n  }\n}\nfunction g() {\n  if (y) {\n    return 2;\n  }\n}\n"
+	file := measure(t, src)
+	if len(file.Functions) != 2 {
+		t.Fatalf("got %d functions, want 2", len(file.Functions))
+	}
+	if file.Cyclomatic != 5 || file.Cognitive != 5 || file.Nesting != 2 || file.Lines != 15 {
+		t.Errorf("got cyclomatic %d, cognitive %d, nesting %d, lines %d, want 5, 5, 2, 15",
+			file.Cyclomatic, file.Cognitive, file.Nesting, file.Lines)
+	}
+	if g := file.Functions[1]; g.Name != "g" || g.Line != 11 {
+		t.Errorf("second function is %s on line %d, want g on line 11", g.Name, g.Line)
+	}
+}
+
+// TestHalstead checks the operator and operand counts and the measures derived from them
+func TestHalstead(t *testing.T) {
+	tests := []struct {
+		src  string
+		want Halstead // Counts only, the derived measures are checked from them
+	}{
+		// Operators let, =, + and ;, operands x, a and 1
+		{"let x = a + 1;", Halstead{DistinctOperators: 4, DistinctOperands: 3, Operators: 4, Operands: 3}},
+		// Operators let twice, = twice, + twice and ; twice; operands x, a twice, 1 twice, y
+		{"let x = a + 1;\nlet y = a + 1;", Halstead{DistinctOperators: 4, DistinctOperands: 4, Operators: 8, Operands: 6}},
+		// The brackets count once per pair: log, ( and ;, operands log, "hi" and true
+		{`log("hi", true);`, Halstead{DistinctOperators: 3, DistinctOperands: 3, Operators: 3, Operands: 3}},
+		{"// only a comment\n", Halstead{}},
+	}
+	for _, test := range tests {
+		h := measure(t, test.src).Halstead
+		w := test.want
+		if h.DistinctOperators != w.DistinctOperators || h.DistinctOperands != w.DistinctOperands ||
+			h.Operators != w.Operators || h.Operands != w.Operands {
+			t.Errorf("%q: got n1=%d n2=%d N1=%d N2=%d, want n1=%d n2=%d N1=%d N2=%d", test.src,
+				h.DistinctOperators, h.DistinctOperands, h.Operators, h.Operands,
+				w.DistinctOperators, w.DistinctOperands, w.Operators, w.Operands)
+			continue
+		}
+		vocabulary, length := w.DistinctOperators+w.DistinctOperands, w.Operators+w.Operands
+		var volume, difficulty float64
+		if vocabulary > 0 {
+			volume = float64(length) * math.Log2(float64(vocabulary))
+			difficulty = float64(w.DistinctOperators) / 2 * float64(w.Operands) / float64(w.DistinctOperands)
+		}
+		if h.Vocabulary != vocabulary || h.Length != length || !near(h.Volume, volume) ||
+			!near(h.Difficulty, difficulty) || !near(h.Effort, difficulty*volume) {
+			t.Errorf("%q: got %+v", test.src, h)
+		}
+	}
+}
+
+// near reports whether two measures are equal up to rounding
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestCheck checks which measures are reported over their limits, and how
+func TestCheck(t *testing.T) {
+	src := "function small(a) {\n  return a;\n}\nfunction big(a, b, c) {\n  if (a) {\n    if (b) {\n      return c;\n    }\n  }\n  return 0;\n}\n"
+	file := measure(t, src)
+	tests := []struct {
+		limits Limits
+		want   []string
+	}{
+		{Limits{}, nil}, // No limits
+		{Limits{Cyclomatic: 3, Cognitive: 3, Nesting: 2, Params: 3, Lines: 8}, nil},
+		{Limits{Cyclomatic: 2}, []string{"function 'big' has a cyclomatic complexity of 3 (max 2)"}},
+		{Limits{Cognitive: 2}, []string{"function 'big' has a cognitive complexity of 3 (max 2)"}},
+		{Limits{Nesting: 1}, []string{"function 'big' has a nesting depth of 2 (max 1)"}},
+		{Limits{Params: 2}, []string{"function 'big' has 3 parameters (max 2)"}},
+		{Limits{Lines: 3}, []string{"function 'big' has 8 lines of code (max 3)"}},
+		{Limits{Params: 0, Lines: 1, Nesting: 1}, []string{
+			"function 'small' has 3 lines of code (max 1)",
+			"function 'big' has a nesting depth of 2 (max 1)",
+			"function 'big' has 8 lines of code (max 1)",
+		}},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, v := range file.Check(test.limits) {
+			got = append(got, v.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%+v: got %q, want %q", test.limits, got, test.want)
+		}
+	}
+}
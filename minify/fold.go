@@ -0,0 +1,114 @@
+package minify
+
+import (
+	"regexp"
+
+	"goast/ast"
+	"goast/astutil"
+	"goast/codegen"
+	"goast/constant"
+)
+
+// fold replaces constant expressions by their value and removes if statements with a constant test
+// Operands are folded before the expression holding them, so nested constants fold completely
+func fold(program *ast.Program) {
+	astutil.Apply(program, nil, func(c *astutil.Cursor) bool {
+		switch n := c.Node().(type) {
+		case *ast.BinaryExpression:
+			if literal := foldBinary(n); literal != nil {
+				c.Replace(literal)
+			}
+		case *ast.IfStatement:
+			if test, ok := constant.Evaluate(n.Test); ok && c.Index() >= 0 {
+				removeBranch(c, n, constant.Truthy(test))
+			}
+		}
+		return true
+	})
+}
+
+// foldBinary returns the literal equivalent to a constant binary expression, or nil
+// Results our lexer cannot read back (negative numbers, exponents, NaN, Infinity) and
+// literals longer than the expression they replace are left alone
+func foldBinary(n *ast.BinaryExpression) ast.Node {
+	v, ok := constant.Evaluate(n)
+	if !ok {
+		return nil
+	}
+	var literal ast.Node
+	switch v := v.(type) {
+	case constant.String:
+		return &ast.StringLiteral{Span: n.Span, Value: string(v)}
+	case constant.Number:
+		text := constant.NumberToString(float64(v))
+		if !plainNumber.MatchString(text) {
+			return nil
+		}
+		literal = &ast.NumericLiteral{Span: n.Span, Value: text}
+	case constant.Boolean:
+		literal = &ast.BooleanLiteral{Span: n.Span, Value: bool(v)}
+	default:
+		return nil
+	}
+	compact := &codegen.Options{Compact: true}
+	if len(codegen.Generate(literal, compact)) > len(codegen.Generate(n, compact)) {
+		return nil // 1 / 3 is shorter than 0.3333333333333333, 1<2 than true
+	}
+	return literal
+}
+
+// plainNumber matches the numeric literals the lexer understands
+var plainNumber = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// removeBranch replaces an if statement whose test is always truthy or always falsy
+// A truthy if is replaced by its statements unless they declare block-scoped names:
+// function declarations are block-scoped too, as in strict mode, and moving any of them
+// out of the block could clash with or shadow names of the enclosing scope, so such an if
+// is left as it is. A falsy one disappears, function declarations included, since nothing
+// outside the block can see them; its "var" declarations are hoisted out of the block, so
+// each one leaves a declaration without initializer behind
+func removeBranch(c *astutil.Cursor, n *ast.IfStatement, taken bool) {
+	var keep []ast.Node
+	if taken {
+		for _, stmt := range n.Consequent {
+			switch s := stmt.(type) {
+			case *ast.FunctionDeclaration:
+				return
+			case *ast.VariableDeclaration:
+				if s.Kind != "var" {
+					return
+				}
+			}
+		}
+		keep = n.Consequent
+	} else {
+		keep = hoistedVars(n.Consequent)
+	}
+
+	if len(keep) == 0 {
+		c.Delete()
+		return
+	}
+	c.Replace(keep[0])
+	// InsertAfter inserts right after the current node, so insert the rest backwards
+	for i := len(keep) - 1; i > 0; i-- {
+		c.InsertAfter(keep[i])
+	}
+}
+
+// hoistedVars returns a declaration without initializer for each "var" in a removed block
+// Nested functions have their own scope and are not searched
+func hoistedVars(body []ast.Node) []ast.Node {
+	var decls []ast.Node
+	for _, stmt := range body {
+		switch s := stmt.(type) {
+		case *ast.VariableDeclaration:
+			if s.Kind == "var" {
+				decls = append(decls, &ast.VariableDeclaration{Span: s.Span, NameSpan: s.NameSpan, Kind: "var", Name: s.Name})
+			}
+		case *ast.IfStatement:
+			decls = append(decls, hoistedVars(s.Consequent)...)
+		}
+	}
+	return decls
+}
@@ -0,0 +1,190 @@
+package minify
+
+import (
+	"goast/ast"
+)
+
+// mangler shortens the names of function-local bindings
+// Top-level names are globals other scripts may use and are never renamed
+type mangler struct {
+	reserved map[string]bool // Every name in the program, new names must not collide with them
+}
+
+// scope maps the original names visible in a function to their new names
+type scope map[string]string
+
+// mangle renames the parameters, variables and nested functions of every function
+// A function mentioning eval keeps its names, as do the functions around it, since the
+// evaluated code may refer to them; the parser does not accept with statements at all
+func mangle(program *ast.Program) {
+	m := &mangler{reserved: map[string]bool{}}
+	ast.Inspect(program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Identifier:
+			m.reserved[n.Name] = true
+		case *ast.FunctionDeclaration:
+			m.reserved[n.Name] = true
+		case *ast.Parameter:
+			m.reserved[n.Name] = true
+		case *ast.VariableDeclaration:
+			m.reserved[n.Name] = true
+		}
+		return true
+	})
+	m.statements(program.Body, scope{})
+}
+
+// function renames the bindings of fn, which sees the bindings of outer
+func (m *mangler) function(fn *ast.FunctionDeclaration, outer scope) {
+	inner := scope{}
+	used := map[string]bool{}
+	for name, renamed := range outer {
+		inner[name] = renamed
+		// Reusing a name of the enclosing scope could capture references to it
+		used[renamed] = true
+	}
+
+	locals := localNames(fn)
+	if mentionsEval(fn) {
+		for _, name := range locals {
+			inner[name] = name
+		}
+	} else {
+		next := 0
+		for _, name := range locals {
+			var renamed string
+			for {
+				renamed = shortName(next)
+				next++
+				if !m.reserved[renamed] && !used[renamed] && !keywords[renamed] {
+					break
+				}
+			}
+			inner[name] = renamed
+		}
+	}
+
+	for i := range fn.Params {
+		param := &fn.Params[i]
+		param.Name = inner.rename(param.Name)
+		m.expression(param.DefaultValue, inner)
+	}
+	m.statements(fn.Body, inner)
+}
+
+// statements renames the names used in a statement list
+// Nested functions are declared in s, their own bindings are handled by function
+func (m *mangler) statements(list []ast.Node, s scope) {
+	for _, stmt := range list {
+		switch n := stmt.(type) {
+		case *ast.FunctionDeclaration:
+			n.Name = s.rename(n.Name)
+			m.function(n, s)
+		case *ast.VariableDeclaration:
+			n.Name = s.rename(n.Name)
+			m.expression(n.Value, s)
+		case *ast.IfStatement:
+			m.expression(n.Test, s)
+			m.statements(n.Consequent, s)
+		case *ast.ReturnStatement:
+			m.expression(n.Argument, s)
+		default:
+			m.expression(n, s)
+		}
+	}
+}
+
+// expression renames the identifiers of an expression
+func (m *mangler) expression(node ast.Node, s scope) {
+	if node == nil {
+		return
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Identifier); ok {
+			id.Name = s.rename(id.Name)
+		}
+		return true
+	})
+}
+
+// rename returns the new name of name, which is unchanged if it is not bound in s
+func (s scope) rename(name string) string {
+	if renamed, ok := s[name]; ok {
+		return renamed
+	}
+	return name
+}
+
+// localNames lists the names bound inside fn in declaration order: parameters, then variables
+// and nested function names, including those in if blocks but not those of nested functions
+func localNames(fn *ast.FunctionDeclaration) []string {
+	var names []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, param := range fn.Params {
+		add(param.Name)
+	}
+	var collect func(list []ast.Node)
+	collect = func(list []ast.Node) {
+		for _, stmt := range list {
+			switch n := stmt.(type) {
+			case *ast.VariableDeclaration:
+				add(n.Name)
+			case *ast.FunctionDeclaration:
+				add(n.Name)
+			case *ast.IfStatement:
+				collect(n.Consequent)
+			}
+		}
+	}
+	collect(fn.Body)
+	return names
+}
+
+// mentionsEval reports whether the identifier eval appears anywhere inside node
+func mentionsEval(node ast.Node) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Identifier); ok && id.Name == "eval" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// Characters for generated names; later characters may also be digits
+const (
+	nameStart = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_$"
+	namePart  = nameStart + "0123456789"
+)
+
+// shortName returns the i-th name of the sequence a, b, ..., $, aa, ba, ...
+func shortName(i int) string {
+	name := []byte{nameStart[i%len(nameStart)]}
+	i /= len(nameStart)
+	for i > 0 {
+		i--
+		name = append(name, namePart[i%len(namePart)])
+		i /= len(namePart)
+	}
+	return string(name)
+}
+
+// keywords are the reserved words that cannot be used as names
+var keywords = map[string]bool{
+	"arguments": true, "await": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "debugger": true, "default": true, "delete": true, "do": true,
+	"else": true, "enum": true, "eval": true, "export": true, "extends": true, "false": true,
+	"finally": true, "for": true, "function": true, "if": true, "implements": true, "import": true,
+	"in": true, "instanceof": true, "interface": true, "let": true, "new": true, "null": true,
+	"package": true, "private": true, "protected": true, "public": true, "return": true,
+	"static": true, "super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+	"yield": true,
+}
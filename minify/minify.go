@@ -0,0 +1,56 @@
+// Package minify shrinks JavaScript programs while keeping their behaviour
+// It folds constant expressions, drops if statements whose test is constant, shortens the
+// names of local variables and parameters, and prints the result without whitespace or comments
+package minify
+
+import (
+	"goast/ast"
+	"goast/codegen"
+	"goast/parser"
+	"goast/sourcemap"
+)
+
+// Options configures the minifier
+// A nil *Options is valid and enables every transformation
+type Options struct {
+	KeepNames     bool // Keep the names of local variables, parameters and functions
+	KeepConstants bool // Neither fold constant expressions nor remove constant if statements
+	Quote         byte // Preferred string quote, '"' (the default) or '\''
+
+	// SourceMap, when set, receives mappings from the minified code to the original source
+	SourceMap *sourcemap.Generator
+}
+
+// Source minifies JavaScript source code and returns the compact code
+// The whole program is parsed first, so nothing is printed for code with syntax errors:
+// the error is the parser's ErrorList; opts also controls the quotes and the source map
+func Source(filename, src string, opts *Options) (string, error) {
+	program, err := parser.ParseFile(filename, src, nil)
+	if err != nil {
+		return "", err
+	}
+	Program(program, opts)
+
+	genOpts := &codegen.Options{Compact: true}
+	if opts != nil {
+		genOpts.Quote = opts.Quote
+		genOpts.SourceMap = opts.SourceMap
+		genOpts.SourceFile = filename
+		genOpts.Source = src
+	}
+	return codegen.Generate(program, genOpts), nil
+}
+
+// Program applies the minifying transformations to program in place
+// Printing the result with codegen.Options.Compact gives the minified code
+func Program(program *ast.Program, opts *Options) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if !opts.KeepConstants {
+		fold(program)
+	}
+	if !opts.KeepNames {
+		mangle(program)
+	}
+}
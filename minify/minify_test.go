@@ -0,0 +1,74 @@
+package minify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"goast/interp"
+	"goast/parser"
+)
+
+// run runs a program on the interpreter and returns what it printed, and whether it failed
+func run(t *testing.T, filename, src string) (string, bool) {
+	t.Helper()
+	var out strings.Builder
+	in := interp.New(&interp.Options{
+		Stdout: &out,
+		Stderr: &out,
+		Now:    func() time.Time { return time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC) },
+	})
+	_, err := in.RunSource(filename, src)
+	return out.String(), err != nil
+}
+
+// TestRoundTrip minifies programs, parses the result back and checks that it prints the same
+func TestRoundTrip(t *testing.T) {
+	programs := map[string]string{
+		"declarations":   "const a = 1;\nconst b = 2;\nlet c = a + b;\nvar d = c * 2;\nvar e = d;\nconsole.log(a, b, c, d, e);\n",
+		"dead branch":    "if (false) {\n  var k = 1;\n  var m = 2;\n}\nconsole.log(k, m);\n",
+		"taken branch":   "if (1 + 1 === 2) {\n  var k = 'yes';\n}\nconsole.log(k);\n",
+		"block function": "if (true) {\n  function inner() {\n    return 1;\n  }\n  console.log(inner());\n}\n",
+		"locals":         "function outer(first, second = 10) {\n  let total = first + second;\n  const half = total / 2;\n  function twice(n) {\n    return n * 2;\n  }\n  return twice(half);\n}\nconsole.log(outer(4));\n",
+	}
+	// Top-level names are kept, so once they take every letter and _ the mangled names of
+	// the locals go on to $ and to two characters
+	var many strings.Builder
+	for i, c := range "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ_" {
+		fmt.Fprintf(&many, "const %c = %d;\n", c, i)
+	}
+	many.WriteString("function f(longname, other) {\n  let third = longname + other;\n  return third * Z;\n}\nconsole.log(f(a, b), f(_, 1));\n")
+	programs["many names"] = many.String()
+
+	files, err := filepath.Glob("../examples/stdlib/*.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range append(files, "../script.js") {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		programs[filepath.Base(file)] = string(src)
+	}
+
+	for name, src := range programs {
+		minified, err := Source(name, src, nil)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if _, err := parser.ParseFile(name, minified, nil); err != nil {
+			t.Errorf("%s: minified code does not parse: %v\n%s", name, err, minified)
+			continue
+		}
+		want, wantFailed := run(t, name, src)
+		got, failed := run(t, name, minified)
+		if got != want || failed != wantFailed {
+			t.Errorf("%s: minified code prints\n%s\nwant\n%s\ncode: %s", name, got, want, minified)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"goast/lexer"
+)
+
+// SyntaxError describes a problem found while parsing the token stream
+// Pos is the index of the offending token so callers can point back at it
+type SyntaxError struct {
+	Filename string      // Name of the parsed file, empty when parsing a bare token stream
+	Message  string      // Human-readable description of the problem
+	Token    lexer.Token // Token the parser was looking at when the error occurred
+	Pos      int         // Index of that token in the token stream
+	fatal    bool        // Fatal errors abort parsing instead of being recovered from
+}
+
+func (e *SyntaxError) Error() string {
+	position := fmt.Sprintf("%d:%d", e.Token.Line, e.Token.Column)
+	if e.Filename != "" {
+		position = e.Filename + ":" + position
+	}
+	if e.Token.Type == "EOF" {
+		return fmt.Sprintf("%s: syntax error at end of input: %s", position, e.Message)
+	}
+	return fmt.Sprintf("%s: syntax error: %s", position, e.Message)
+}
+
+// ErrorList is the error returned by ParseFile and ParseExpression
+// It holds every syntax error found, in source order
+type ErrorList []*SyntaxError
+
+func (list ErrorList) Error() string {
+	switch len(list) {
+	case 0:
+		return "no errors"
+	case 1:
+		return list[0].Error()
+	}
+	messages := make([]string, len(list))
+	for i, err := range list {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// errorf builds a SyntaxError pointing at the current token
+func (p *Parser) errorf(format string, args ...any) *SyntaxError {
+	return p.errorAt(p.pos, format, args...)
+}
+
+// errorAt builds a SyntaxError pointing at the token at pos
+func (p *Parser) errorAt(pos int, format string, args ...any) *SyntaxError {
+	return &SyntaxError{
+		Message: fmt.Sprintf(format, args...),
+		Token:   p.tokenAt(pos),
+		Pos:     pos,
+	}
+}
+
+// recoverSyntaxError turns a recovered panic value into a SyntaxError
+// Parser bails out with a *SyntaxError on purpose, anything else is an internal bug
+// that we still report as a syntax error rather than crashing the caller
+func (p *Parser) recoverSyntaxError(r any) *SyntaxError {
+	if err, ok := r.(*SyntaxError); ok {
+		return err
+	}
+	return p.errorf("internal parser error: %v", r)
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"slices"
+
+	"goast/ast"
+	"goast/lexer"
+)
+
+// Options configures ParseFile and ParseExpression
+// A nil *Options is valid and selects the defaults
+type Options struct {
+	MaxDepth int // Maximum nesting depth, DefaultMaxDepth when zero
+}
+
+// ParseFile tokenizes and parses the JavaScript source src
+// name is only used to prefix error messages and may be empty
+// The returned Program is never nil: on syntax errors it holds ErrorNode placeholders
+// for the broken statements and the error is an ErrorList describing them
+func ParseFile(name, src string, opts *Options) (*ast.Program, error) {
+	p := newParser(src, opts)
+	program := p.Parse()
+	return program, p.errorList(name)
+}
+
+// ParseExpression parses src as a single JavaScript expression
+// Anything left over after the expression is reported as a syntax error
+func ParseExpression(src string, opts *Options) (ast.Node, error) {
+	p := newParser(src, opts)
+	expr := p.parseExpressionOnly()
+	return expr, p.errorList("")
+}
+
+// Incomplete reports whether src stops in the middle of a statement, like a line typed into a
+// console with a brace or parenthesis still open: parsing fails only because the input ran out
+// Input with any other syntax error is complete, and wrong
+func Incomplete(src string) bool {
+	p := newParser(src, nil)
+	p.Parse()
+	for _, err := range p.errors {
+		if err.Token.Type != "EOF" {
+			return false
+		}
+	}
+	return len(p.errors) > 0
+}
+
+// newParser tokenizes src and applies opts to a fresh Parser
+// The problems found by the lexer become syntax errors of their own
+func newParser(src string, opts *Options) *Parser {
+	lx := lexer.NewLexer(src)
+	p := NewParser(lx.Tokenize())
+	for _, err := range lx.Errors() {
+		// Characters no token starts with are left out of the stream, point at what follows
+		pos := slices.IndexFunc(p.tokens, func(tok lexer.Token) bool { return tok.Start >= err.Token.Start })
+		p.errors = append(p.errors, &SyntaxError{Message: err.Message, Token: err.Token, Pos: pos})
+	}
+	if opts != nil && opts.MaxDepth > 0 {
+		p.MaxDepth = opts.MaxDepth
+	}
+	return p
+}
+
+// parseExpressionOnly parses one expression followed by end of input
+// It has the same recovery boundary as Parse so it never panics
+func (p *Parser) parseExpressionOnly() (expr ast.Node) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.errors = append(p.errors, p.recoverSyntaxError(r))
+		}
+	}()
+
+	expr = p.parseExpression()
+	if p.current().Type != "EOF" {
+		p.errors = append(p.errors, p.errorf("unexpected %s after expression", describe(p.current())))
+	}
+	return expr
+}
+
+// errorList returns the collected errors as an ErrorList, or nil when there are none
+func (p *Parser) errorList(name string) error {
+	if len(p.errors) == 0 {
+		return nil
+	}
+	list := make(ErrorList, len(p.errors))
+	for i, err := range p.errors {
+		err.Filename = name
+		list[i] = err
+	}
+	// Errors from the lexer come first, put them in source order with the parser's
+	slices.SortStableFunc(list, func(a, b *SyntaxError) int { return a.Token.Start - b.Token.Start })
+	return list
+}
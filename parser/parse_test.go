@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"goast/ast"
+)
+
+// FuzzParse checks that parsing any input terminates without panicking and returns a
+// program covering the whole input, whose nodes lie inside it
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"function checkAge(age) {\n  if (age >= 18) {\n    return \"Adult\";\n  }\n  return \"Minor\";\n}",
+		"const o = { a: [1, 2], 'b c': new Map() };\no.a[0] = o['b c'];",
+		// Unterminated bodies, lists and strings
+		"function f() {",
+		"function f() { if (x) {",
+		"function f(a, b",
+		"if (x) {\n  const a = 1;",
+		"f(1, 2",
+		"[1, 2",
+		"{ a: 1",
+		"const s = \"abc;",
+		"const",
+		"return",
+		// Deep nesting
+		strings.Repeat("(", 2000),
+		strings.Repeat("[", 2000),
+		strings.Repeat("if (x) {", 1000),
+		strings.Repeat("function f() {", 1000),
+		strings.Repeat("a.b(", 1000),
+		"x = " + strings.Repeat("1 + ", 2000) + "1;",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		done := make(chan *ast.Program)
+		go func() {
+			program, _ := ParseFile("fuzz.js", src, nil)
+			done <- program
+		}()
+		var program *ast.Program
+		select {
+		case program = <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("parsing did not terminate")
+		}
+
+		if program == nil {
+			t.Fatal("ParseFile returned a nil program")
+		}
+		ast.Inspect(program, func(node ast.Node) bool {
+			if node == nil {
+				return false
+			}
+			if span := node.Range(); span.IsValid() && (span.Start < 0 || span.End > len(src) || span.End < span.Start) {
+				t.Fatalf("%s node spans %d-%d outside the input of length %d", node.Type(), span.Start, span.End, len(src))
+			}
+			return true
+		})
+	})
+}
+
+func TestMaxDepth(t *testing.T) {
+	_, err := ParseFile("deep.js", strings.Repeat("(", 100)+"1"+strings.Repeat(")", 100), &Options{MaxDepth: 50})
+	if err == nil || !strings.Contains(err.Error(), "maximum nesting depth of 50 exceeded") {
+		t.Errorf("error = %v, want the nesting limit", err)
+	}
+	if _, err := ParseFile("deep.js", strings.Repeat("(", 40)+"1"+strings.Repeat(")", 40), &Options{MaxDepth: 50}); err != nil {
+		t.Errorf("error = %v under the limit", err)
+	}
+}
+
+func TestLexicalErrors(t *testing.T) {
+	_, err := ParseFile("str.js", "const a = 1 +;\nconst b = \"abc;\n", nil)
+	want := "str.js:1:14: syntax error: unexpected \";\" in expression\nstr.js:2:11: syntax error: unterminated string literal"
+	if err == nil || err.Error() != want {
+		t.Errorf("error = %v, want\n%s", err, want)
+	}
+}
+
+func TestInvalidStatements(t *testing.T) {
+	tests := []struct {
+		src string
+		err string // "" if the source is valid
+	}{
+		{"return 1;", "test.js:1:1: syntax error: return outside of a function"},
+		{"if (x) {\n  return;\n}", "test.js:2:3: syntax error: return outside of a function"},
+		{"function f() {\n  if (x) {\n    return 1;\n  }\n}", ""},
+		{"function f() {}\nreturn;", "test.js:2:1: syntax error: return outside of a function"},
+		{"0 = 0;", "test.js:1:1: syntax error: invalid left-hand side in assignment"},
+		{"x = (a + b) = 1;", "test.js:1:5: syntax error: invalid left-hand side in assignment"},
+		{"f() = 1;", "test.js:1:1: syntax error: invalid left-hand side in assignment"},
+		{"a = b.c = d[0] = 1;", ""},
+	}
+	for _, tt := range tests {
+		_, err := ParseFile("test.js", tt.src, nil)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.err {
+			t.Errorf("%q: error = %q, want %q", tt.src, got, tt.err)
+		}
+	}
+}
+
+// shape lists the types of statements, with the bodies of functions and if statements
+// in brackets
+func shape(list []ast.Node) string {
+	types := make([]string, len(list))
+	for i, node := range list {
+		types[i] = node.Type()
+		switch n := node.(type) {
+		case *ast.FunctionDeclaration:
+			types[i] += "[" + shape(n.Body) + "]"
+		case *ast.IfStatement:
+			types[i] += "[" + shape(n.Consequent) + "]"
+		}
+	}
+	return strings.Join(types, " ")
+}
+
+// TestRecovery checks the partial program and the errors of broken input: expressions that
+// cannot be parsed become InvalidExpressions, statements ErrorNodes, and parsing goes on
+// with the statements after them
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		src     string
+		shape   string   // Statements of the partial program
+		invalid [][2]int // Spans of the InvalidExpression placeholders
+		skipped []string // Source covered by the ErrorNodes, in order
+		errors  []string // Error positions and messages
+	}{
+		{
+			"const a = ;\nlet b = 2;\n",
+			"VariableDeclaration VariableDeclaration",
+			[][2]int{{10, 10}}, nil,
+			[]string{`1:11: unexpected ";" in expression`},
+		},
+		{
+			"f(1, , 2);\nlet y = 3;\n",
+			"ExpressionStatement VariableDeclaration",
+			[][2]int{{5, 5}}, nil,
+			[]string{`1:6: unexpected "," in expression`},
+		},
+		{
+			"let a = 1 + );\nlog(a);\n",
+			"VariableDeclaration ErrorNode ExpressionStatement",
+			[][2]int{{12, 12}}, []string{");"},
+			[]string{`1:13: unexpected ")" in expression`, `1:13: unexpected ")" at start of statement`},
+		},
+		{
+			"if (x {\n  log(1);\n}\nlog(2);\n",
+			"ErrorNode ExpressionStatement",
+			nil, []string{"if (x {\n  log(1);\n}"},
+			[]string{`1:7: expected ) after if condition, found "{"`},
+		},
+		{
+			"function f( {\n  return 1;\n}\nlog(2);\n",
+			"ErrorNode ExpressionStatement",
+			nil, []string{"function f( {\n  return 1;\n}"},
+			[]string{`1:13: unexpected "{" in parameter list`},
+		},
+		{
+			"function f() {\n  let = 1;\n  return 2;\n}\nlog(3);\n",
+			"FunctionDeclaration[ErrorNode ReturnStatement] ExpressionStatement",
+			nil, []string{"let = 1;"},
+			[]string{`2:7: expected variable name, found "="`},
+		},
+		{
+			"let x = [1, ;\nlog(x);\n",
+			"ErrorNode ExpressionStatement",
+			nil, []string{"let x = [1, ;"}, // The statement is replaced as a whole
+			[]string{`1:13: unexpected ";" in expression`, `1:13: expected ] after array elements, found ";"`},
+		},
+		{
+			"const = 1;\nif (x) {\n  log(;\n}\nlet y = 2;\nlog(y);\n",
+			"ErrorNode IfStatement[ErrorNode] VariableDeclaration ExpressionStatement",
+			nil, []string{"const = 1;", "log(;"},
+			[]string{`1:7: expected variable name, found "="`, `3:7: unexpected ";" in expression`, `3:7: expected ) after arguments, found ";"`},
+		},
+	}
+	for _, tt := range tests {
+		program, err := ParseFile("test.js", tt.src, nil)
+		if got := shape(program.Body); got != tt.shape {
+			t.Errorf("%q: statements %s, want %s", tt.src, got, tt.shape)
+		}
+
+		var invalid [][2]int
+		var skipped []string
+		ast.Inspect(program, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.InvalidExpression:
+				invalid = append(invalid, [2]int{n.Start, n.End})
+			case *ast.ErrorNode:
+				skipped = append(skipped, tt.src[n.Start:n.End])
+			}
+			return true
+		})
+		if fmt.Sprint(invalid) != fmt.Sprint(tt.invalid) {
+			t.Errorf("%q: InvalidExpressions at %v, want %v", tt.src, invalid, tt.invalid)
+		}
+		if fmt.Sprintf("%q", skipped) != fmt.Sprintf("%q", tt.skipped) {
+			t.Errorf("%q: ErrorNodes cover %q, want %q", tt.src, skipped, tt.skipped)
+		}
+
+		list, ok := err.(ErrorList)
+		if !ok {
+			t.Errorf("%q: error %v is not an ErrorList", tt.src, err)
+			continue
+		}
+		var errors []string
+		for _, e := range list {
+			errors = append(errors, fmt.Sprintf("%d:%d: %s", e.Token.Line, e.Token.Column, e.Message))
+			if e.Filename != "test.js" {
+				t.Errorf("%q: error %q has file name %q", tt.src, e.Message, e.Filename)
+			}
+		}
+		if strings.Join(errors, "\n") != strings.Join(tt.errors, "\n") {
+			t.Errorf("%q: errors\n%s\nwant\n%s", tt.src, strings.Join(errors, "\n"), strings.Join(tt.errors, "\n"))
+		}
+	}
+}
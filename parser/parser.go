@@ -0,0 +1,656 @@
+// Package parser builds an AST from JavaScript source using recursive descent
+package parser
+
+import (
+	"goast/ast"
+	"goast/lexer"
+)
+
+// DefaultMaxDepth is the nesting depth allowed when no other limit is configured
+// Input nested deeper than this produces a syntax error instead of exhausting the Go stack
+const DefaultMaxDepth = 500
+
+// Parser generates an AST from tokens
+// It implements a recursive descent parser pattern
+type Parser struct {
+	tokens   []lexer.Token  // Token stream from the lexer
+	pos      int            // Current position in the token stream
+	depth    int            // Current nesting depth of statements and expressions
+	bodies   int            // Number of function bodies around the current token
+	errors   []*SyntaxError // Syntax errors collected while parsing
+	MaxDepth int            // Maximum nesting depth before parsing gives up
+}
+
+// NewParser creates a new parser with the given token stream
+func NewParser(tokens []lexer.Token) *Parser {
+	return &Parser{
+		tokens:   tokens,
+		pos:      0,
+		MaxDepth: DefaultMaxDepth,
+	}
+}
+
+// Errors returns the syntax errors found by the last call to Parse
+func (p *Parser) Errors() []*SyntaxError {
+	return p.errors
+}
+
+// current returns the current token without advancing
+func (p *Parser) current() lexer.Token {
+	return p.tokenAt(p.pos)
+}
+
+// next moves to the next token and returns it
+func (p *Parser) next() lexer.Token {
+	p.pos++
+	return p.current()
+}
+
+// Parse builds a complete AST from the token stream
+// This is the entry point to the parsing process
+// Problems are reported through Errors, statements that fail to parse become ErrorNode placeholders
+func (p *Parser) Parse() (program *ast.Program) {
+	program = &ast.Program{Body: []ast.Node{}}
+
+	// Any panic below this point becomes a syntax error
+	// so malformed input can never crash the caller
+	defer func() {
+		if r := recover(); r != nil {
+			p.errors = append(p.errors, p.recoverSyntaxError(r))
+		}
+	}()
+
+	// The program always covers the whole source, up to the EOF token
+	defer func() {
+		program.Span = ast.Span{Start: 0, End: p.current().End}
+	}()
+
+	// Process tokens until EOF, appending as we go so a bail-out keeps earlier statements
+	for p.current().Type != "EOF" {
+		start := p.pos
+		node := p.parseStatementSafely("EOF")
+		if node != nil {
+			program.Body = append(program.Body, node)
+		}
+		// Never get stuck on a token no rule wants to consume
+		if p.pos == start {
+			p.next()
+		}
+	}
+
+	return program
+}
+
+// parseStatementList parses statements until the terminator token type or EOF
+// Every iteration is guaranteed to consume at least one token, so the loop always terminates
+func (p *Parser) parseStatementList(terminator string) []ast.Node {
+	statements := []ast.Node{}
+	for p.current().Type != terminator && p.current().Type != "EOF" {
+		start := p.pos
+		stmt := p.parseStatementSafely(terminator)
+		if stmt != nil {
+			statements = append(statements, stmt)
+		}
+		// Never get stuck on a token no rule wants to consume
+		if p.pos == start {
+			p.next()
+		}
+	}
+	return statements
+}
+
+// enter records one more level of nesting and bails out past MaxDepth
+// Every call must be paired with a deferred leave
+func (p *Parser) enter() {
+	p.depth++
+	maxDepth := p.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if p.depth > maxDepth {
+		// Recovering inside the runaway nesting would only fail again, so give up entirely
+		err := p.errorf("maximum nesting depth of %d exceeded", maxDepth)
+		err.fatal = true
+		panic(err)
+	}
+}
+
+// leave undoes the matching enter call
+func (p *Parser) leave() {
+	p.depth--
+}
+
+// parseStatement parses a single statement based on the current token
+// Different token types lead to different statement types
+func (p *Parser) parseStatement() ast.Node {
+	p.enter()
+	defer p.leave()
+
+	token := p.current()
+
+	switch token.Type {
+	case "COMMENT":
+		return p.parseComment() // Handle comments
+	case "FUNCTION":
+		return p.parseFunctionDeclaration() // Handle function declarations
+	case "RETURN":
+		return p.parseReturnStatement() // Handle return statements
+	case "CONST", "LET", "VAR":
+		return p.parseVariableDeclaration() // Handle variable declarations
+	case "IF":
+		return p.parseIfStatement() // Handle if statements
+	case "SEMICOLON":
+		p.next() // Skip standalone semicolons
+		return nil
+	case "IDENTIFIER", "NUMBER", "STRING", "BOOLEAN", "NULL", "LEFT_PAREN", "LEFT_BRACKET", "NEW":
+		// A brace at the start of a statement would open a block, not an object literal
+		return p.parseExpressionStatement() // Handle calls and assignments
+	default:
+		// Nothing else can start a statement, let the recovery skip ahead
+		panic(p.errorf("unexpected %s at start of statement", describe(token)))
+	}
+}
+
+// parseExpressionStatement parses an expression used as a statement
+// Format: expression;
+func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+	start := p.pos
+	expression := p.parseExpression()
+
+	// Skip semicolon if present
+	if p.current().Type == "SEMICOLON" {
+		p.next()
+	}
+
+	return &ast.ExpressionStatement{Span: p.spanFrom(start), Expression: expression}
+}
+
+// parseComment creates a Comment node from a comment token
+func (p *Parser) parseComment() *ast.Comment {
+	start := p.pos
+	comment := &ast.Comment{Text: p.current().Value}
+	p.next() // Skip comment token
+	comment.Span = p.spanFrom(start)
+	return comment
+}
+
+// parseFunctionDeclaration parses a function declaration statement
+// Format: function name(param1, param2 = defaultValue) { body }
+func (p *Parser) parseFunctionDeclaration() *ast.FunctionDeclaration {
+	start := p.pos
+	p.next() // Skip function keyword
+
+	nameToken := p.expect("IDENTIFIER", "function name")
+	name := nameToken.Value
+
+	// Parse parameters inside parentheses
+	params := []ast.Parameter{}
+	p.expect("LEFT_PAREN", "( after function name")
+	for p.current().Type != "RIGHT_PAREN" && p.current().Type != "EOF" {
+		if p.current().Type == "IDENTIFIER" {
+			paramStart := p.pos
+			paramName := p.current().Value
+			p.next() // Skip parameter name
+
+			var defaultValue ast.Node
+			// Check for default value assignment
+			if p.current().Type == "EQUALS" {
+				p.next() // Skip the equals sign
+				defaultValue = p.parseExpression()
+			}
+
+			params = append(params, ast.Parameter{
+				Span:         p.spanFrom(paramStart),
+				NameSpan:     tokenSpan(p.tokenAt(paramStart)),
+				Name:         paramName,
+				DefaultValue: defaultValue,
+			})
+
+			// Skip comma if present
+			if p.current().Type == "COMMA" {
+				p.next()
+			}
+		} else {
+			panic(p.errorf("unexpected %s in parameter list", describe(p.current())))
+		}
+	}
+	p.expect("RIGHT_PAREN", ") after parameters")
+
+	// Parse function body inside braces
+	bodyStart := p.pos
+	p.expect("LEFT_BRACE", "{ before function body")
+	p.bodies++
+	defer func() { p.bodies-- }()
+	body := p.parseStatementList("RIGHT_BRACE")
+	if p.current().Type != "RIGHT_BRACE" {
+		panic(p.errorf("unterminated body of function %s", name))
+	}
+	p.next() // Skip }
+
+	return &ast.FunctionDeclaration{
+		Span:     p.spanFrom(start),
+		NameSpan: tokenSpan(nameToken),
+		Name:     name,
+		Params:   params,
+		Body:     body,
+		BodySpan: p.spanFrom(bodyStart),
+	}
+}
+
+// parseIfStatement parses an if statement
+// Format: if (condition) { body }
+func (p *Parser) parseIfStatement() *ast.IfStatement {
+	start := p.pos
+	p.next() // Skip the 'if' keyword
+
+	// Parse condition in parentheses
+	p.expect("LEFT_PAREN", "( after if")
+	test := p.parseExpression()
+	p.expect("RIGHT_PAREN", ") after if condition")
+
+	// Parse consequent (the "then" block)
+	consequentStart := p.pos
+	p.expect("LEFT_BRACE", "{ before if body")
+	// Parse statements until we reach the closing brace
+	consequent := p.parseStatementList("RIGHT_BRACE")
+	if p.current().Type != "RIGHT_BRACE" {
+		panic(p.errorf("unterminated body of if statement"))
+	}
+	p.next() // Skip the closing brace
+
+	return &ast.IfStatement{
+		Span:           p.spanFrom(start),
+		Test:           test,
+		Consequent:     consequent,
+		ConsequentSpan: p.spanFrom(consequentStart),
+	}
+}
+
+// parseExpression parses expressions like comparisons and math operations
+func (p *Parser) parseExpression() ast.Node {
+	return p.parseBinary(1)
+}
+
+// parseBinary parses a chain of binary operators binding at least as tightly as minPrecedence
+// This is precedence climbing: a + b * c groups as a + (b * c) and a - b - c as (a - b) - c
+func (p *Parser) parseBinary(minPrecedence int) ast.Node {
+	p.enter()
+	defer p.leave()
+
+	// Parse the left side of the expression
+	start := p.pos
+	left := p.parseCall()
+
+	// Every operator binding tightly enough extends the expression to the right
+	for isBinaryOperator(p.current().Type) {
+		operator := p.current().Value
+		precedence := ast.Precedence(operator)
+		if precedence < minPrecedence {
+			break
+		}
+		p.next() // Skip the operator
+
+		// The right side only takes operators that bind tighter, or as tight for right-associative ones
+		nextPrecedence := precedence + 1
+		if ast.IsRightAssociative(operator) {
+			nextPrecedence = precedence
+		}
+		right := p.parseBinary(nextPrecedence)
+
+		// Only variables and properties can be assigned to
+		if operator == "=" && !isAssignable(left) {
+			p.errors = append(p.errors, p.errorAt(start, "invalid left-hand side in assignment"))
+		}
+
+		left = &ast.BinaryExpression{
+			Span:     p.spanFrom(start),
+			Left:     left,
+			Operator: operator,
+			Right:    right,
+		}
+	}
+
+	return left
+}
+
+// parseCall parses a primary expression followed by any number of argument lists and property accesses
+// Format: callee(arg1, arg2)(arg3), object.property.method(arg) or items[i]
+func (p *Parser) parseCall() ast.Node {
+	start := p.pos
+	var expr ast.Node
+	if p.current().Type == "NEW" {
+		expr = p.parseNew()
+	} else {
+		expr = p.parsePrimary()
+	}
+
+	for {
+		if p.current().Type == "LEFT_PAREN" {
+			args := p.parseArguments()
+			expr = &ast.CallExpression{Span: p.spanFrom(start), Callee: expr, Arguments: args}
+			continue
+		}
+		member, ok := p.parseMember(start, expr)
+		if !ok {
+			return expr
+		}
+		expr = member
+	}
+}
+
+// parseNew parses a constructor call, whose callee may access properties but not call
+// Format: new Callee(arg1, arg2) or new namespace.Callee, the argument list being optional
+func (p *Parser) parseNew() ast.Node {
+	p.enter()
+	defer p.leave()
+
+	start := p.pos
+	p.next() // Skip new
+
+	calleeStart := p.pos
+	var callee ast.Node
+	if p.current().Type == "NEW" {
+		callee = p.parseNew() // new new Factory()() constructs with what the inner one constructed
+	} else {
+		callee = p.parsePrimary()
+	}
+	for {
+		member, ok := p.parseMember(calleeStart, callee)
+		if !ok {
+			break
+		}
+		callee = member
+	}
+
+	args := []ast.Node{}
+	if p.current().Type == "LEFT_PAREN" {
+		args = p.parseArguments()
+	}
+	return &ast.NewExpression{Span: p.spanFrom(start), Callee: callee, Arguments: args}
+}
+
+// parseArguments parses a parenthesized argument list
+// Format: (arg1, arg2)
+func (p *Parser) parseArguments() []ast.Node {
+	p.next() // Skip (
+	args := []ast.Node{}
+	for p.current().Type != "RIGHT_PAREN" && p.current().Type != "EOF" {
+		// Arguments bind tighter than the comma separating them, but may be assignments
+		args = append(args, p.parseBinary(ast.Precedence("=")))
+		if p.current().Type != "COMMA" {
+			break
+		}
+		p.next() // Skip ,
+	}
+	p.expect("RIGHT_PAREN", ") after arguments")
+	return args
+}
+
+// parseMember parses one property access of expr, which started at the token index start
+// It reports false, consuming nothing, when the current token starts no property access
+// Format: .property or [expression]
+func (p *Parser) parseMember(start int, expr ast.Node) (ast.Node, bool) {
+	switch p.current().Type {
+	case "DOT":
+		p.next() // Skip .
+		// Keywords are valid property names, as in promise.catch or node.if
+		property := p.current()
+		if !isPropertyName(property.Type) {
+			property = p.expect("IDENTIFIER", "property name after .")
+		}
+		p.next()
+		return &ast.MemberExpression{
+			Span:         p.spanFrom(start),
+			Object:       expr,
+			Property:     property.Value,
+			PropertySpan: tokenSpan(property),
+		}, true
+	case "LEFT_BRACKET":
+		p.next() // Skip [
+		index := p.parseExpression()
+		p.expect("RIGHT_BRACKET", "] after property")
+		return &ast.MemberExpression{Span: p.spanFrom(start), Object: expr, Index: index}, true
+	}
+	return nil, false
+}
+
+// parsePrimary parses a primary expression (identifiers, literals, parenthesized expressions)
+func (p *Parser) parsePrimary() ast.Node {
+	token := p.current()
+
+	switch token.Type {
+	case "IDENTIFIER":
+		identifier := &ast.Identifier{Span: tokenSpan(token), Name: token.Value}
+		p.next()
+		return identifier
+	case "NUMBER":
+		number := &ast.NumericLiteral{Span: tokenSpan(token), Value: token.Value}
+		p.next()
+		return number
+	case "BOOLEAN":
+		boolean := &ast.BooleanLiteral{Span: tokenSpan(token), Value: token.Value == "true"}
+		p.next()
+		return boolean
+	case "NULL":
+		null := &ast.NullLiteral{Span: tokenSpan(token)}
+		p.next()
+		return null
+	case "LEFT_PAREN":
+		// Parentheses only group, they leave no trace in the AST
+		p.next() // Skip (
+		expr := p.parseExpression()
+		p.expect("RIGHT_PAREN", ") after expression")
+		return expr
+	case "STRING":
+		// Remove quotes and decode escape sequences
+		value := &ast.StringLiteral{Span: tokenSpan(token), Value: unquote(token.Value)}
+		p.next()
+		return value
+	case "LEFT_BRACKET":
+		return p.parseArray()
+	case "LEFT_BRACE":
+		return p.parseObject()
+	default:
+		// Keep a placeholder and carry on, the rest of the statement may still be fine
+		return p.parseInvalidExpression()
+	}
+}
+
+// parseArray parses an array literal, allowing a trailing comma
+// Format: [element1, element2]
+func (p *Parser) parseArray() *ast.ArrayExpression {
+	p.enter()
+	defer p.leave()
+
+	start := p.pos
+	p.next() // Skip [
+	elements := []ast.Node{}
+	for p.current().Type != "RIGHT_BRACKET" && p.current().Type != "EOF" {
+		elements = append(elements, p.parseBinary(ast.Precedence("=")))
+		if p.current().Type != "COMMA" {
+			break
+		}
+		p.next() // Skip ,
+	}
+	p.expect("RIGHT_BRACKET", "] after array elements")
+	return &ast.ArrayExpression{Span: p.spanFrom(start), Elements: elements}
+}
+
+// parseObject parses an object literal, allowing a trailing comma
+// Keys are identifiers, keywords, strings or numbers; an identifier alone is shorthand for key: key
+// Format: { key: value, "other key": value, key }
+func (p *Parser) parseObject() *ast.ObjectExpression {
+	p.enter()
+	defer p.leave()
+
+	start := p.pos
+	p.next() // Skip {
+	properties := []ast.Property{}
+	for p.current().Type != "RIGHT_BRACE" && p.current().Type != "EOF" {
+		propertyStart := p.pos
+		keyToken := p.current()
+		var key string
+		switch {
+		case keyToken.Type == "STRING":
+			key = unquote(keyToken.Value)
+		case keyToken.Type == "NUMBER":
+			key = numberKey(keyToken.Value)
+		case isPropertyName(keyToken.Type):
+			key = keyToken.Value
+		default:
+			panic(p.errorf("unexpected %s in object literal", describe(keyToken)))
+		}
+		p.next() // Skip the key
+
+		var value ast.Node
+		if keyToken.Type == "IDENTIFIER" && p.current().Type != "COLON" {
+			value = &ast.Identifier{Span: tokenSpan(keyToken), Name: key}
+		} else {
+			p.expect("COLON", ": after property name")
+			value = p.parseBinary(ast.Precedence("="))
+		}
+		properties = append(properties, ast.Property{
+			Span:    p.spanFrom(propertyStart),
+			KeySpan: tokenSpan(keyToken),
+			Key:     key,
+			Value:   value,
+		})
+
+		if p.current().Type != "COMMA" {
+			break
+		}
+		p.next() // Skip ,
+	}
+	if p.current().Type != "RIGHT_BRACE" {
+		panic(p.errorf("expected } after object properties, found %s", describe(p.current())))
+	}
+	p.next() // Skip }
+	return &ast.ObjectExpression{Span: p.spanFrom(start), Properties: properties}
+}
+
+// spanFrom returns the span from the token at index start to the last consumed token
+// The span is empty if nothing has been consumed since start
+func (p *Parser) spanFrom(start int) ast.Span {
+	first := p.tokenAt(start)
+	if p.pos <= start {
+		return ast.Span{Start: first.Start, End: first.Start}
+	}
+	return ast.Span{Start: first.Start, End: p.tokenAt(p.pos - 1).End}
+}
+
+// tokenAt returns the token at index i, or the final EOF token past the end
+func (p *Parser) tokenAt(i int) lexer.Token {
+	if i < len(p.tokens) {
+		return p.tokens[i]
+	}
+	if len(p.tokens) > 0 {
+		return p.tokens[len(p.tokens)-1] // Keep returning the lexer's EOF token past the end
+	}
+	return lexer.Token{Type: "EOF", Value: ""} // Return EOF if there are no tokens at all
+}
+
+// tokenSpan returns the span covered by a single token
+func tokenSpan(token lexer.Token) ast.Span {
+	return ast.Span{Start: token.Start, End: token.End}
+}
+
+// isPropertyName reports whether a token can name a property after a dot: identifiers and keywords
+func isPropertyName(tokenType string) bool {
+	switch tokenType {
+	case "IDENTIFIER", "FUNCTION", "RETURN", "CONST", "LET", "VAR", "IF", "BOOLEAN", "NULL", "NEW":
+		return true
+	}
+	return false
+}
+
+// isBinaryOperator checks if a token type represents a binary operator
+func isBinaryOperator(tokenType string) bool {
+	return tokenType == "EQUALITY" || tokenType == "EQUALS" ||
+		tokenType == "STRICT_EQUALITY" || tokenType == "INEQUALITY" ||
+		tokenType == "STRICT_INEQUALITY" ||
+		tokenType == "PLUS" || tokenType == "MINUS" ||
+		tokenType == "MULTIPLY" || tokenType == "DIVIDE" ||
+		tokenType == "MODULO" ||
+		tokenType == "GREATER_THAN" || tokenType == "LESS_THAN" ||
+		tokenType == "GREATER_EQUAL" || tokenType == "LESS_EQUAL"
+}
+
+// isAssignable reports whether an expression can be the target of an assignment
+func isAssignable(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Identifier, *ast.MemberExpression:
+		return true
+	}
+	return false
+}
+
+// parseReturnStatement parses a return statement
+// Format: return expression;
+func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
+	start := p.pos
+	// The statement is still parsed, so the rest of the program gets checked too
+	if p.bodies == 0 {
+		p.errors = append(p.errors, p.errorf("return outside of a function"))
+	}
+	p.next() // Skip return keyword
+
+	var argument ast.Node
+	// Parse any expression as the return value
+	// This handles: identifiers, literals, binary expressions, etc.
+	if t := p.current().Type; t != "SEMICOLON" && t != "RIGHT_BRACE" && t != "EOF" {
+		argument = p.parseExpression()
+	}
+
+	// Skip semicolon if present
+	if p.current().Type == "SEMICOLON" {
+		p.next()
+	}
+
+	return &ast.ReturnStatement{Span: p.spanFrom(start), Argument: argument}
+}
+
+// atStatementEnd reports whether the current token ends a statement
+func (p *Parser) atStatementEnd() bool {
+	switch p.current().Type {
+	case "SEMICOLON", "RIGHT_BRACE", "EOF":
+		return true
+	}
+	return false
+}
+
+// parseVariableDeclaration parses a variable declaration
+// Format: const/let/var name = value; let and var may leave out "= value"
+func (p *Parser) parseVariableDeclaration() *ast.VariableDeclaration {
+	start := p.pos
+	kind := p.current().Value
+	p.next() // Skip const/let/var
+
+	nameToken := p.expect("IDENTIFIER", "variable name")
+
+	// let and var may leave the variable undefined, const needs a value
+	var value ast.Node
+	if kind == "const" || !p.atStatementEnd() {
+		// Skip equals sign
+		if p.current().Type == "EQUALS" {
+			p.next()
+		}
+
+		// Always try to parse as an expression first, which handles all cases:
+		// - Simple literals (strings, numbers, identifiers)
+		// - Complex expressions (1 + 2, a * b, etc.)
+		value = p.parseExpression()
+	}
+
+	// Skip semicolon if present
+	if p.current().Type == "SEMICOLON" {
+		p.next()
+	}
+
+	return &ast.VariableDeclaration{
+		Span:     p.spanFrom(start),
+		NameSpan: tokenSpan(nameToken),
+		Kind:     kind,
+		Name:     nameToken.Value,
+		Value:    value,
+	}
+}
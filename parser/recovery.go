@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"fmt"
+
+	"goast/ast"
+	"goast/lexer"
+)
+
+// parseStatementSafely parses one statement and recovers from syntax errors
+// On error the parser skips ahead to the next statement boundary (panic-mode recovery)
+// and returns an ErrorNode covering everything that was skipped
+func (p *Parser) parseStatementSafely(terminator string) (node ast.Node) {
+	start := p.pos
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		err, ok := r.(*SyntaxError)
+		if !ok || err.fatal {
+			panic(r) // Internal bugs and fatal errors are handled by Parse
+		}
+		p.errors = append(p.errors, err)
+
+		p.synchronize(terminator)
+		if p.pos == start {
+			p.next() // Always skip at least the token that started the statement
+		}
+		node = &ast.ErrorNode{Span: p.spanFrom(start), Message: err.Message}
+	}()
+
+	return p.parseStatement()
+}
+
+// synchronize skips tokens until a statement boundary
+// Boundaries are a semicolon (consumed), a closing brace of the enclosing block (kept)
+// or a keyword that starts a new statement (kept); braces opened while skipping are skipped as a whole
+func (p *Parser) synchronize(terminator string) {
+	braces := 0
+	for {
+		switch p.current().Type {
+		case "EOF":
+			return
+		case "LEFT_BRACE":
+			braces++
+		case "RIGHT_BRACE":
+			if braces == 0 {
+				if terminator == "RIGHT_BRACE" {
+					return // Leave it for the enclosing block to close
+				}
+				break // A stray brace at top level is skipped like any other token
+			}
+			braces--
+			if braces == 0 {
+				p.next() // The skipped block ends the broken statement
+				return
+			}
+		case "SEMICOLON":
+			if braces == 0 {
+				p.next()
+				return
+			}
+		case "FUNCTION", "IF", "RETURN", "CONST", "LET", "VAR":
+			if braces == 0 {
+				return
+			}
+		}
+		p.next()
+	}
+}
+
+// expect consumes a token of the given type or bails out with a syntax error
+// what describes the expected token in the error message
+func (p *Parser) expect(tokenType, what string) lexer.Token {
+	token := p.current()
+	if token.Type != tokenType {
+		panic(p.errorf("expected %s, found %s", what, describe(token)))
+	}
+	p.next()
+	return token
+}
+
+// parseInvalidExpression records an error for a token that cannot start an expression
+// The token is consumed unless it is likely to close or separate the surrounding construct
+func (p *Parser) parseInvalidExpression() *ast.InvalidExpression {
+	token := p.current()
+	p.errors = append(p.errors, p.errorf("unexpected %s in expression", describe(token)))
+
+	switch token.Type {
+	case "SEMICOLON", "COMMA", "COLON", "RIGHT_PAREN", "RIGHT_BRACKET", "RIGHT_BRACE", "EOF":
+		// Zero-width placeholder, the token belongs to the enclosing construct
+		return &ast.InvalidExpression{Span: ast.Span{Start: token.Start, End: token.Start}}
+	}
+	p.next()
+	return &ast.InvalidExpression{Span: tokenSpan(token)}
+}
+
+// describe renders a token for use in error messages
+func describe(token lexer.Token) string {
+	if token.Type == "EOF" {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", token.Value)
+}
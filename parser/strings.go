@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// unquote returns the value of a string literal token
+// The surrounding quotes are removed and JavaScript escape sequences are decoded;
+// malformed escapes keep the escaped character, as non-strict JavaScript does
+func unquote(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	quote := raw[0]
+	body := raw[1:]
+
+	var sb strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c == quote {
+			break // The closing quote, absent when the string is unterminated
+		}
+		if c != '\\' || i+1 == len(body) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = body[i]; c {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'v':
+			sb.WriteByte('\v')
+		case '0':
+			sb.WriteByte(0)
+		case '\n':
+			// Line continuation, the newline is not part of the value
+		case 'x':
+			if r, ok := hexRune(body, i+1, 2); ok {
+				sb.WriteRune(r)
+				i += 2
+			} else {
+				sb.WriteByte(c)
+			}
+		case 'u':
+			if r, ok := hexRune(body, i+1, 4); ok {
+				sb.WriteRune(r)
+				i += 4
+			} else {
+				sb.WriteByte(c)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// hexRune decodes n hexadecimal digits of s starting at i
+func hexRune(s string, i, n int) (rune, bool) {
+	if i+n > len(s) {
+		return utf8.RuneError, false
+	}
+	value, err := strconv.ParseUint(s[i:i+n], 16, 32)
+	if err != nil {
+		return utf8.RuneError, false
+	}
+	return rune(value), true
+}
+
+// numberKey returns the property name a numeric literal stands for as an object key: the
+// number converted to a string, so that { 1.50: x } and { "1.5": x } define the same property
+func numberKey(raw string) string {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return raw
+	}
+	if value < 1e21 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64) // 1e+21, as JavaScript writes it too
+}
@@ -0,0 +1,127 @@
+// Package printer dumps an AST in a human-readable indented format
+package printer
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"goast/ast"
+)
+
+// PrintAST recursively prints the AST in a human-readable format to standard output
+// It uses indentation to show the tree structure
+func PrintAST(node ast.Node, indent string) {
+	Fprint(os.Stdout, node, indent)
+}
+
+// Fprint writes the same human-readable dump as PrintAST to w
+func Fprint(w io.Writer, node ast.Node, indent string) {
+	switch n := node.(type) {
+	case *ast.Program:
+		fmt.Fprintln(w, indent+"Program:")
+		for _, stmt := range n.Body {
+			Fprint(w, stmt, indent+"  ")
+		}
+	case *ast.FunctionDeclaration:
+		fmt.Fprintf(w, "%sFunctionDeclaration: %s\n", indent, n.Name)
+		fmt.Fprintf(w, "%s  Parameters:\n", indent)
+		for _, param := range n.Params {
+			if param.DefaultValue != nil {
+				fmt.Fprintf(w, "%s    %s (default):\n", indent, param.Name)
+				Fprint(w, param.DefaultValue, indent+"      ")
+			} else {
+				fmt.Fprintf(w, "%s    %s\n", indent, param.Name)
+			}
+		}
+		fmt.Fprintf(w, "%s  Body:\n", indent)
+		for _, stmt := range n.Body {
+			Fprint(w, stmt, indent+"    ")
+		}
+	case *ast.IfStatement:
+		fmt.Fprintf(w, "%sIfStatement:\n", indent)
+		fmt.Fprintf(w, "%s  Condition:\n", indent)
+		Fprint(w, n.Test, indent+"    ")
+		fmt.Fprintf(w, "%s  Body:\n", indent)
+		for _, stmt := range n.Consequent {
+			Fprint(w, stmt, indent+"    ")
+		}
+	case *ast.BinaryExpression:
+		fmt.Fprintf(w, "%sBinaryExpression: %s\n", indent, n.Operator)
+		fmt.Fprintf(w, "%s  Left:\n", indent)
+		Fprint(w, n.Left, indent+"    ")
+		fmt.Fprintf(w, "%s  Right:\n", indent)
+		Fprint(w, n.Right, indent+"    ")
+	case *ast.CallExpression:
+		fmt.Fprintf(w, "%sCallExpression:\n", indent)
+		fmt.Fprintf(w, "%s  Callee:\n", indent)
+		Fprint(w, n.Callee, indent+"    ")
+		fmt.Fprintf(w, "%s  Arguments:\n", indent)
+		for _, arg := range n.Arguments {
+			Fprint(w, arg, indent+"    ")
+		}
+	case *ast.MemberExpression:
+		if n.Index != nil {
+			fmt.Fprintf(w, "%sMemberExpression: [computed]\n", indent)
+			fmt.Fprintf(w, "%s  Object:\n", indent)
+			Fprint(w, n.Object, indent+"    ")
+			fmt.Fprintf(w, "%s  Index:\n", indent)
+			Fprint(w, n.Index, indent+"    ")
+			break
+		}
+		fmt.Fprintf(w, "%sMemberExpression: .%s\n", indent, n.Property)
+		Fprint(w, n.Object, indent+"  ")
+	case *ast.NewExpression:
+		fmt.Fprintf(w, "%sNewExpression:\n", indent)
+		fmt.Fprintf(w, "%s  Callee:\n", indent)
+		Fprint(w, n.Callee, indent+"    ")
+		fmt.Fprintf(w, "%s  Arguments:\n", indent)
+		for _, arg := range n.Arguments {
+			Fprint(w, arg, indent+"    ")
+		}
+	case *ast.ArrayExpression:
+		fmt.Fprintf(w, "%sArrayExpression:\n", indent)
+		for _, element := range n.Elements {
+			Fprint(w, element, indent+"  ")
+		}
+	case *ast.ObjectExpression:
+		fmt.Fprintf(w, "%sObjectExpression:\n", indent)
+		for i := range n.Properties {
+			Fprint(w, &n.Properties[i], indent+"  ")
+		}
+	case *ast.Property:
+		fmt.Fprintf(w, "%sProperty: %s\n", indent, n.Key)
+		Fprint(w, n.Value, indent+"  ")
+	case *ast.ExpressionStatement:
+		fmt.Fprintf(w, "%sExpressionStatement:\n", indent)
+		Fprint(w, n.Expression, indent+"  ")
+	case *ast.ReturnStatement:
+		fmt.Fprintf(w, "%sReturnStatement:\n", indent)
+		if n.Argument != nil {
+			Fprint(w, n.Argument, indent+"  ")
+		}
+	case *ast.Identifier:
+		fmt.Fprintf(w, "%sIdentifier: %s\n", indent, n.Name)
+	case *ast.StringLiteral:
+		fmt.Fprintf(w, "%sStringLiteral: %s\n", indent, n.Value)
+	case *ast.NumericLiteral:
+		fmt.Fprintf(w, "%sNumericLiteral: %s\n", indent, n.Value)
+	case *ast.BooleanLiteral:
+		fmt.Fprintf(w, "%sBooleanLiteral: %t\n", indent, n.Value)
+	case *ast.NullLiteral:
+		fmt.Fprintf(w, "%sNullLiteral\n", indent)
+	case *ast.VariableDeclaration:
+		fmt.Fprintf(w, "%sVariableDeclaration: %s %s\n", indent, n.Kind, n.Name)
+		if n.Value != nil {
+			Fprint(w, n.Value, indent+"  ")
+		}
+	case *ast.Comment:
+		fmt.Fprintf(w, "%sComment: %s\n", indent, n.Text)
+	case *ast.ErrorNode:
+		fmt.Fprintf(w, "%sErrorNode: %s [%d:%d]\n", indent, n.Message, n.Start, n.End)
+	case *ast.InvalidExpression:
+		fmt.Fprintf(w, "%sInvalidExpression: [%d:%d]\n", indent, n.Start, n.End)
+	default:
+		fmt.Fprintf(w, "%sUnknown node type\n", indent)
+	}
+}
@@ -0,0 +1,152 @@
+package scope
+
+import (
+	"goast/ast"
+)
+
+// Options configures Analyze
+// A nil *Options is valid and analyzes the program as a classic script
+type Options struct {
+	// Module analyzes the program as an ES module: top-level declarations go into a module
+	// scope below the global scope instead of becoming globals
+	Module bool
+}
+
+// Analysis is the result of Analyze
+type Analysis struct {
+	Global     *Scope       // Root of the scope tree
+	Scopes     []*Scope     // Every scope, parents before their children
+	References []*Reference // Every reference, in source order
+	Unresolved []*Reference // References to undeclared names
+
+	scopes     map[ast.Node]*Scope
+	references map[*ast.Identifier]*Reference
+}
+
+// Scope returns the scope created by node: the global (or module) scope for the Program,
+// the function scope for a FunctionDeclaration and the block scope for an IfStatement
+func (a *Analysis) Scope(node ast.Node) *Scope {
+	return a.scopes[node]
+}
+
+// Reference returns the reference made by an identifier
+func (a *Analysis) Reference(id *ast.Identifier) *Reference {
+	return a.references[id]
+}
+
+// Analyze builds the scopes of program and resolves its references
+//
+// Declarations follow JavaScript's rules: var declarations are hoisted to the enclosing function,
+// function declarations to the top of the function or block containing them, and let and const
+// are scoped to their block. All declarations are collected before references are resolved,
+// so a reference may be resolved to a declaration that comes later in the source
+func Analyze(program *ast.Program, opts *Options) *Analysis {
+	a := &Analysis{scopes: map[ast.Node]*Scope{}, references: map[*ast.Identifier]*Reference{}}
+	a.Global = a.newScope(Global, program, nil)
+	top := a.Global
+	if opts != nil && opts.Module {
+		top = a.newScope(Module, program, a.Global)
+	}
+	a.scopes[program] = top
+
+	a.statements(program.Body, top)
+	for _, ref := range a.References {
+		a.resolve(ref)
+	}
+	return a
+}
+
+// newScope creates a scope and links it into the tree
+func (a *Analysis) newScope(kind Kind, node ast.Node, parent *Scope) *Scope {
+	s := &Scope{Kind: kind, Node: node, Parent: parent, variables: map[string]*Variable{}}
+	if parent != nil {
+		parent.Children = append(parent.Children, s)
+	}
+	a.Scopes = append(a.Scopes, s)
+	a.scopes[node] = s
+	return s
+}
+
+// statements declares and visits a statement list belonging to scope s
+func (a *Analysis) statements(list []ast.Node, s *Scope) {
+	for _, stmt := range list {
+		switch n := stmt.(type) {
+		case *ast.FunctionDeclaration:
+			s.declare(n.Name, "function", n)
+			a.function(n, s)
+		case *ast.VariableDeclaration:
+			if n.Kind == "var" {
+				s.FunctionScope().declare(n.Name, n.Kind, n)
+			} else {
+				s.declare(n.Name, n.Kind, n)
+			}
+			a.expression(n.Value, s)
+		case *ast.IfStatement:
+			a.expression(n.Test, s)
+			a.statements(n.Consequent, a.newScope(Block, n, s))
+		case *ast.ReturnStatement:
+			a.expression(n.Argument, s)
+		case *ast.Comment, *ast.ErrorNode:
+		default:
+			a.expression(n, s)
+		}
+	}
+}
+
+// function creates the scope of a function declared in outer and visits its parameters and body
+func (a *Analysis) function(fn *ast.FunctionDeclaration, outer *Scope) {
+	s := a.newScope(Function, fn, outer)
+	for i := range fn.Params {
+		s.declare(fn.Params[i].Name, "parameter", &fn.Params[i])
+	}
+	for i := range fn.Params {
+		a.expression(fn.Params[i].DefaultValue, s)
+	}
+	a.statements(fn.Body, s)
+}
+
+// expression records the references made by an expression in scope s
+func (a *Analysis) expression(node ast.Node, s *Scope) {
+	if node == nil {
+		return
+	}
+	var write *ast.Identifier
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BinaryExpression:
+			if id, ok := n.Left.(*ast.Identifier); ok && n.Operator == "=" {
+				write = id
+			}
+		case *ast.Identifier:
+			ref := &Reference{Identifier: n, From: s, Write: n == write}
+			s.References = append(s.References, ref)
+			a.References = append(a.References, ref)
+			a.references[n] = ref
+		}
+		return true
+	})
+}
+
+// resolve connects a reference to its variable and detects uses in the temporal dead zone
+func (a *Analysis) resolve(ref *Reference) {
+	v := ref.From.Lookup(ref.Identifier.Name)
+	if v == nil {
+		a.Unresolved = append(a.Unresolved, ref)
+		return
+	}
+	ref.Resolved = v
+	v.References = append(v.References, ref)
+
+	// A let or const is unusable until its declaration ran; inside a nested function the
+	// use may run later, so only uses in the same function are known to be too early
+	if v.Kind != "let" && v.Kind != "const" {
+		return
+	}
+	if ref.From.FunctionScope() != v.Scope.FunctionScope() {
+		return
+	}
+	decl := v.Declarations[0].Range()
+	if decl.IsValid() && ref.Identifier.Span.IsValid() && ref.Identifier.Span.Start < decl.End {
+		ref.TDZ = true
+	}
+}
@@ -0,0 +1,94 @@
+// Package scope connects identifiers to the declarations they refer to
+// Analyze builds the tree of scopes of a program, declares every variable, parameter and
+// function in the scope JavaScript puts it in, and resolves each Identifier to its Variable
+package scope
+
+import (
+	"goast/ast"
+)
+
+// Kind is the kind of a scope
+type Kind string
+
+// Scope kinds, named as in eslint-scope
+// eslint-scope also has catch and class scopes; the parser has neither try statements
+// nor classes, so no such scope can exist until it does
+const (
+	Global   Kind = "global"
+	Module   Kind = "module"
+	Function Kind = "function"
+	Block    Kind = "block"
+)
+
+// Scope is a region of the program in which declared names are visible
+type Scope struct {
+	Kind       Kind
+	Node       ast.Node // Program, FunctionDeclaration, or IfStatement for the block of its consequent
+	Parent     *Scope   // Enclosing scope, nil for the global scope
+	Children   []*Scope
+	Variables  []*Variable  // Variables declared in this scope, in declaration order
+	References []*Reference // Identifiers appearing directly in this scope
+
+	variables map[string]*Variable
+}
+
+// Variable is a name declared in a scope
+type Variable struct {
+	Name         string
+	Kind         string     // "var", "let", "const", "function" or "parameter"
+	Scope        *Scope     // Scope the variable belongs to, after hoisting
+	Declarations []ast.Node // VariableDeclaration, *Parameter or FunctionDeclaration nodes, more than one if redeclared
+	References   []*Reference
+}
+
+// Reference is an Identifier that reads or assigns a name
+type Reference struct {
+	Identifier *ast.Identifier
+	From       *Scope    // Scope the identifier appears in
+	Resolved   *Variable // Declaration the name refers to, nil for an undeclared global
+	Write      bool      // The identifier is the target of an assignment
+	TDZ        bool      // A let or const is used before its declaration ran (a ReferenceError at run time)
+}
+
+// IsGlobal reports whether the reference could not be resolved and refers to a global
+// property such as console, or to nothing at all
+func (r *Reference) IsGlobal() bool {
+	return r.Resolved == nil
+}
+
+// Variable returns the variable named name declared in s itself
+func (s *Scope) Variable(name string) *Variable {
+	return s.variables[name]
+}
+
+// Lookup returns the variable named name visible in s, searching the enclosing scopes
+func (s *Scope) Lookup(name string) *Variable {
+	for scope := s; scope != nil; scope = scope.Parent {
+		if v := scope.variables[name]; v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// FunctionScope returns the nearest function, module or global scope enclosing s,
+// which is where var declarations end up
+func (s *Scope) FunctionScope() *Scope {
+	scope := s
+	for scope.Kind != Function && scope.Kind != Module && scope.Kind != Global {
+		scope = scope.Parent
+	}
+	return scope
+}
+
+// declare adds a declaration of name to s, merging redeclarations into one Variable
+func (s *Scope) declare(name, kind string, decl ast.Node) *Variable {
+	if v := s.variables[name]; v != nil {
+		v.Declarations = append(v.Declarations, decl)
+		return v
+	}
+	v := &Variable{Name: name, Kind: kind, Scope: s, Declarations: []ast.Node{decl}}
+	s.variables[name] = v
+	s.Variables = append(s.Variables, v)
+	return v
+}
@@ -0,0 +1,191 @@
+package scope
+
+import (
+	"testing"
+
+	"goast/ast"
+	"goast/parser"
+)
+
+// analyze parses and analyzes a program, failing the test on syntax errors
+func analyze(t *testing.T, src string, opts *Options) (*ast.Program, *Analysis) {
+	t.Helper()
+	program, err := parser.ParseFile("test.js", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program, Analyze(program, opts)
+}
+
+// refs returns the references to name, in source order
+func refs(a *Analysis, name string) []*Reference {
+	var list []*Reference
+	for _, ref := range a.References {
+		if ref.Identifier.Name == name {
+			list = append(list, ref)
+		}
+	}
+	return list
+}
+
+// TestResolution checks which scope each kind of declaration ends up in
+func TestResolution(t *testing.T) {
+	src := `function f(p) {
+  if (p) {
+    var v = 1;
+    let l = 2;
+    function g() {}
+  }
+  return v + p;
+}
+log(f(1));
+`
+	program, a := analyze(t, src, nil)
+	f := program.Body[0].(*ast.FunctionDeclaration)
+	fs := a.Scope(f)
+	block := a.Scope(f.Body[0])
+	if fs.Kind != Function || block.Kind != Block || block.Parent != fs || fs.Parent != a.Global {
+		t.Fatalf("wrong scope tree: %s under %v, %s under %v", fs.Kind, fs.Parent, block.Kind, block.Parent)
+	}
+
+	tests := []struct {
+		name, kind string
+		scope      *Scope
+	}{
+		{"f", "function", a.Global},
+		{"p", "parameter", fs},
+		{"v", "var", fs}, // Hoisted out of the block
+		{"l", "let", block},
+		{"g", "function", block},
+	}
+	for _, test := range tests {
+		v := test.scope.Variable(test.name)
+		if v == nil || v.Kind != test.kind || v.Scope != test.scope {
+			t.Errorf("%s: got %+v, want a %s in the %s scope", test.name, v, test.kind, test.scope.Kind)
+		}
+	}
+	if block.Lookup("f") != a.Global.Variable("f") || block.Lookup("l") != block.Variable("l") {
+		t.Error("Lookup does not search the enclosing scopes")
+	}
+	if fs.Lookup("l") != nil {
+		t.Error("let visible outside its block")
+	}
+
+	for _, name := range []string{"v", "p"} {
+		for _, ref := range refs(a, name) {
+			if ref.Resolved != fs.Variable(name) || ref.From != fs {
+				t.Errorf("%s: reference not resolved to the function scope", name)
+			}
+		}
+	}
+	if len(a.Unresolved) != 1 || a.Unresolved[0].Identifier.Name != "log" || !a.Unresolved[0].IsGlobal() {
+		t.Errorf("got %d unresolved references, want log only", len(a.Unresolved))
+	}
+}
+
+// TestModule checks that top-level declarations of a module are not globals
+func TestModule(t *testing.T) {
+	_, a := analyze(t, "const x = 1;\nvar y = x;\n", &Options{Module: true})
+	if len(a.Global.Children) != 1 {
+		t.Fatalf("got %d scopes under the global scope, want the module scope", len(a.Global.Children))
+	}
+	module := a.Global.Children[0]
+	if module.Kind != Module || module.Variable("x") == nil || module.Variable("y") == nil {
+		t.Errorf("declarations missing from the module scope")
+	}
+	if len(a.Global.Variables) != 0 {
+		t.Errorf("module declarations leaked into the global scope")
+	}
+}
+
+// TestShadowing checks that a name resolves to the nearest declaration
+func TestShadowing(t *testing.T) {
+	src := `let x = 1;
+function f(x) {
+  if (x) {
+    let x = 2;
+    log(x);
+  }
+  return x;
+}
+log(x);
+`
+	program, a := analyze(t, src, nil)
+	f := program.Body[1].(*ast.FunctionDeclaration)
+	want := []*Variable{
+		a.Scope(f).Variable("x"),         // if (x)
+		a.Scope(f.Body[0]).Variable("x"), // log(x) in the block
+		a.Scope(f).Variable("x"),         // return x
+		a.Global.Variable("x"),           // log(x) at the top level
+	}
+	r := refs(a, "x")
+	if len(r) != len(want) {
+		t.Fatalf("got %d references, want %d", len(r), len(want))
+	}
+	for i, ref := range r {
+		if ref.Resolved != want[i] {
+			t.Errorf("reference %d resolved to the %s %s", i, ref.Resolved.Kind, ref.Resolved.Scope.Kind)
+		}
+	}
+}
+
+// TestTDZ checks which uses of let and const happen before the declaration ran
+func TestTDZ(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool // The first reference to x is in the temporal dead zone
+	}{
+		{"log(x);\nlet x = 1;\n", true},
+		{"const x = x + 1;\n", true},
+		{"let x = 1;\nlog(x);\n", false},
+		{"if (a) {\n  log(x);\n}\nlet x = 1;\n", true},
+		{"log(x);\nvar x = 1;\n", false},                              // var is hoisted with undefined
+		{"function f() {\n  return x;\n}\nlet x = 1;\nf();\n", false}, // The call may run later
+	}
+	for _, test := range tests {
+		_, a := analyze(t, test.src, nil)
+		r := refs(a, "x")
+		if len(r) == 0 || r[0].Resolved == nil {
+			t.Fatalf("%q: x not resolved", test.src)
+		}
+		if r[0].TDZ != test.want {
+			t.Errorf("%q: got TDZ %v, want %v", test.src, r[0].TDZ, test.want)
+		}
+	}
+}
+
+// TestClosure checks that a nested function refers to the variables of the functions
+// around it
+func TestClosure(t *testing.T) {
+	src := `function counter() {
+  let count = 0;
+  function next() {
+    count = count + 1;
+    return count;
+  }
+  return next;
+}
+`
+	program, a := analyze(t, src, nil)
+	outer := program.Body[0].(*ast.FunctionDeclaration)
+	inner := outer.Body[1].(*ast.FunctionDeclaration)
+	count := a.Scope(outer).Variable("count")
+	r := refs(a, "count")
+	if len(r) != 3 {
+		t.Fatalf("got %d references, want 3", len(r))
+	}
+	for i, ref := range r {
+		if ref.Resolved != count || ref.From != a.Scope(inner) {
+			t.Errorf("reference %d not captured from the outer function", i)
+		}
+		if ref.Write != (i == 0) {
+			t.Errorf("reference %d: got Write %v", i, ref.Write)
+		}
+	}
+	if len(count.References) != 3 {
+		t.Errorf("count has %d references, want 3", len(count.References))
+	}
+	if next := refs(a, "next"); len(next) != 1 || next[0].Resolved != a.Scope(outer).Variable("next") {
+		t.Error("return next not resolved to the nested function")
+	}
+}
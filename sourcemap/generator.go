@@ -0,0 +1,114 @@
+package sourcemap
+
+import (
+	"sort"
+	"strings"
+)
+
+// Generator builds a source map one mapping at a time
+// Code generators call AddMapping whenever they write a token that came from the original source
+type Generator struct {
+	file     string
+	sources  []string
+	contents []string
+	names    []string
+	indexes  map[string]int // Source and name indexes, keys are prefixed to keep them apart
+	mappings []Mapping
+}
+
+// NewGenerator creates a generator for the generated file named file
+func NewGenerator(file string) *Generator {
+	return &Generator{file: file, indexes: map[string]int{}}
+}
+
+// AddSource registers an original source together with its content
+// Embedding the content in sourcesContent lets tools show the original code without the file
+func (g *Generator) AddSource(name, content string) {
+	i := g.sourceIndex(name)
+	g.contents[i] = content
+}
+
+// AddMapping records a mapping
+// Mappings may be added in any order, they are sorted when the map is built
+func (g *Generator) AddMapping(m Mapping) {
+	if m.Source != "" {
+		g.sourceIndex(m.Source)
+	}
+	if m.Name != "" {
+		g.nameIndex(m.Name)
+	}
+	g.mappings = append(g.mappings, m)
+}
+
+// Map encodes the recorded mappings into a source map
+func (g *Generator) Map() *Map {
+	mappings := append([]Mapping(nil), g.mappings...)
+	sort.SliceStable(mappings, func(i, j int) bool {
+		a, b := mappings[i], mappings[j]
+		return a.GeneratedLine < b.GeneratedLine || a.GeneratedLine == b.GeneratedLine && a.GeneratedColumn < b.GeneratedColumn
+	})
+
+	var sb strings.Builder
+	line := 0
+	column, source, originalLine, originalColumn, name := 0, 0, 0, 0, 0
+	for i, m := range mappings {
+		if i > 0 && m == mappings[i-1] {
+			continue // Duplicates only make the map larger
+		}
+		if m.GeneratedLine > line {
+			sb.WriteString(strings.Repeat(";", m.GeneratedLine-line))
+			line = m.GeneratedLine
+			column = 0 // Generated columns restart on every line
+		} else if i > 0 {
+			sb.WriteByte(',')
+		}
+
+		encodeVLQ(&sb, m.GeneratedColumn-column)
+		column = m.GeneratedColumn
+		if m.Source == "" {
+			continue
+		}
+		index := g.indexes["source:"+m.Source]
+		encodeVLQ(&sb, index-source)
+		source = index
+		encodeVLQ(&sb, m.OriginalLine-originalLine)
+		originalLine = m.OriginalLine
+		encodeVLQ(&sb, m.OriginalColumn-originalColumn)
+		originalColumn = m.OriginalColumn
+		if m.Name != "" {
+			index := g.indexes["name:"+m.Name]
+			encodeVLQ(&sb, index-name)
+			name = index
+		}
+	}
+
+	return &Map{
+		Version:        3,
+		File:           g.file,
+		Sources:        append([]string{}, g.sources...),
+		SourcesContent: append([]string{}, g.contents...),
+		Names:          append([]string{}, g.names...),
+		Mappings:       sb.String(),
+	}
+}
+
+// sourceIndex returns the index of a source, registering it on first use
+func (g *Generator) sourceIndex(name string) int {
+	if i, ok := g.indexes["source:"+name]; ok {
+		return i
+	}
+	g.indexes["source:"+name] = len(g.sources)
+	g.sources = append(g.sources, name)
+	g.contents = append(g.contents, "")
+	return len(g.sources) - 1
+}
+
+// nameIndex returns the index of a name, registering it on first use
+func (g *Generator) nameIndex(name string) int {
+	if i, ok := g.indexes["name:"+name]; ok {
+		return i
+	}
+	g.indexes["name:"+name] = len(g.names)
+	g.names = append(g.names, name)
+	return len(g.names) - 1
+}
@@ -0,0 +1,173 @@
+// Package sourcemap reads, writes and composes Source Map v3 files
+// A source map links positions in generated JavaScript back to the original sources,
+// so stack traces and debuggers can point at the code that was actually written
+package sourcemap
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Map is a Source Map v3 document as stored in a .map file
+type Map struct {
+	Version        int      `json:"version"`
+	File           string   `json:"file,omitempty"`
+	SourceRoot     string   `json:"sourceRoot,omitempty"`
+	Sources        []string `json:"sources"`
+	SourcesContent []string `json:"sourcesContent,omitempty"`
+	Names          []string `json:"names"`
+	Mappings       string   `json:"mappings"` // Base64 VLQ encoded segments, see Decode
+}
+
+// Mapping links a position in the generated file to a position in an original source
+// Lines and columns are 0-based as in the specification; Source is empty for segments
+// that map to no original code
+type Mapping struct {
+	GeneratedLine   int
+	GeneratedColumn int
+	Source          string // Name of the original source file
+	OriginalLine    int
+	OriginalColumn  int
+	Name            string // Original identifier name, empty if none
+}
+
+// Parse reads a source map from its JSON representation
+func Parse(data []byte) (*Map, error) {
+	var m Map
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("sourcemap: %w", err)
+	}
+	if m.Version != 3 {
+		return nil, fmt.Errorf("sourcemap: unsupported version %d", m.Version)
+	}
+	return &m, nil
+}
+
+// JSON returns the source map in its file format
+func (m *Map) JSON() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// Decode expands the mappings string into individual mappings
+// They are returned in the order they are stored, sorted by generated position
+func (m *Map) Decode() ([]Mapping, error) {
+	var mappings []Mapping
+	line := 0
+	var source, originalLine, originalColumn, name int
+
+	for _, lineSegments := range strings.Split(m.Mappings, ";") {
+		column := 0
+		for _, segment := range strings.Split(lineSegments, ",") {
+			if segment == "" {
+				continue
+			}
+			var fields []int
+			for i := 0; i < len(segment); {
+				value, next, err := decodeVLQ(segment, i)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, value)
+				i = next
+			}
+
+			// Every field is relative to the same field of the previous segment
+			column += fields[0]
+			mapping := Mapping{GeneratedLine: line, GeneratedColumn: column}
+			if len(fields) >= 4 {
+				source += fields[1]
+				originalLine += fields[2]
+				originalColumn += fields[3]
+				if source < 0 || source >= len(m.Sources) {
+					return nil, fmt.Errorf("sourcemap: source index %d out of range", source)
+				}
+				mapping.Source = m.Sources[source]
+				mapping.OriginalLine = originalLine
+				mapping.OriginalColumn = originalColumn
+			}
+			if len(fields) >= 5 {
+				name += fields[4]
+				if name < 0 || name >= len(m.Names) {
+					return nil, fmt.Errorf("sourcemap: name index %d out of range", name)
+				}
+				mapping.Name = m.Names[name]
+			}
+			mappings = append(mappings, mapping)
+		}
+		line++
+	}
+	return mappings, nil
+}
+
+// OriginalPosition finds the mapping covering a position in the generated file
+// It is the last mapping on that line starting at or before column
+func (m *Map) OriginalPosition(line, column int) (Mapping, bool, error) {
+	mappings, err := m.Decode()
+	if err != nil {
+		return Mapping{}, false, err
+	}
+	mapping, ok := lookup(mappings, line, column)
+	return mapping, ok, nil
+}
+
+// lookup finds the mapping covering a generated position in sorted mappings
+func lookup(mappings []Mapping, line, column int) (Mapping, bool) {
+	// First mapping past the position, the one before it covers the position
+	i := sort.Search(len(mappings), func(i int) bool {
+		m := mappings[i]
+		return m.GeneratedLine > line || m.GeneratedLine == line && m.GeneratedColumn > column
+	})
+	if i == 0 || mappings[i-1].GeneratedLine != line || mappings[i-1].Source == "" {
+		return Mapping{}, false
+	}
+	return mappings[i-1], true
+}
+
+// Compose chains two source maps
+// outer maps the final output to an intermediate file and inner maps that intermediate
+// file to the original sources, the result maps the final output straight to the originals
+// Only mappings of outer pointing at inner's file (or all of them when inner has no file name)
+// are translated; mappings that land where inner has no information are dropped
+func Compose(outer, inner *Map) (*Map, error) {
+	outerMappings, err := outer.Decode()
+	if err != nil {
+		return nil, err
+	}
+	innerMappings, err := inner.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	g := NewGenerator(outer.File)
+	for i, source := range inner.Sources {
+		if i < len(inner.SourcesContent) {
+			g.AddSource(source, inner.SourcesContent[i])
+		}
+	}
+
+	for _, mapping := range outerMappings {
+		if mapping.Source == "" || inner.File != "" && mapping.Source != inner.File {
+			g.AddMapping(mapping)
+			continue
+		}
+		original, ok := lookup(innerMappings, mapping.OriginalLine, mapping.OriginalColumn)
+		if !ok {
+			continue
+		}
+		name := original.Name
+		if name == "" {
+			name = mapping.Name
+		}
+		g.AddMapping(Mapping{
+			GeneratedLine:   mapping.GeneratedLine,
+			GeneratedColumn: mapping.GeneratedColumn,
+			Source:          original.Source,
+			OriginalLine:    original.OriginalLine,
+			OriginalColumn:  original.OriginalColumn,
+			Name:            name,
+		})
+	}
+	return g.Map(), nil
+}
@@ -0,0 +1,180 @@
+package sourcemap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVLQ(t *testing.T) {
+	tests := []struct {
+		value   int
+		encoded string
+	}{
+		{0, "A"},
+		{1, "C"},
+		{-1, "D"},
+		{15, "e"},
+		{-15, "f"},
+		{16, "gB"},
+		{-16, "hB"},
+		{123, "2H"},
+		{1000, "w+B"},
+		{-1000, "x+B"},
+		{1 << 20, "ggggC"},
+	}
+	for _, test := range tests {
+		var sb strings.Builder
+		encodeVLQ(&sb, test.value)
+		if got := sb.String(); got != test.encoded {
+			t.Errorf("encode %d = %s, want %s", test.value, got, test.encoded)
+		}
+		value, next, err := decodeVLQ(test.encoded+"A", 0)
+		if err != nil || value != test.value || next != len(test.encoded) {
+			t.Errorf("decode %s = %d, %d, %v, want %d, %d", test.encoded, value, next, err, test.value, len(test.encoded))
+		}
+	}
+
+	for _, bad := range []string{"g", "!", "gB="} {
+		s, i := bad, 0
+		var err error
+		for err == nil && i < len(s) {
+			_, i, err = decodeVLQ(s, i)
+		}
+		if err == nil {
+			t.Errorf("decode %q: no error", bad)
+		}
+	}
+}
+
+// known is a small map: foo at 0:0 and a name-less segment at 0:4, both on line 0 of a.js,
+// then line 1 of the output mapped to line 1 of a.js, and an unmapped segment on line 3
+var known = &Map{
+	Version:        3,
+	File:           "out.js",
+	Sources:        []string{"a.js"},
+	SourcesContent: []string{"let foo = 1;\nfoo;\n"},
+	Names:          []string{"foo"},
+	Mappings:       "AAAAA,IAAI;AACJ;;E",
+}
+
+var knownMappings = []Mapping{
+	{GeneratedLine: 0, GeneratedColumn: 0, Source: "a.js", OriginalLine: 0, OriginalColumn: 0, Name: "foo"},
+	{GeneratedLine: 0, GeneratedColumn: 4, Source: "a.js", OriginalLine: 0, OriginalColumn: 4},
+	{GeneratedLine: 1, GeneratedColumn: 0, Source: "a.js", OriginalLine: 1, OriginalColumn: 0},
+	{GeneratedLine: 3, GeneratedColumn: 2},
+}
+
+func TestDecode(t *testing.T) {
+	mappings, err := known.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mappings, knownMappings) {
+		t.Errorf("got %+v\nwant %+v", mappings, knownMappings)
+	}
+
+	for _, mappings := range []string{"AAAA,g", "ACAA", "AAAAC"} {
+		m := *known
+		m.Mappings = mappings
+		if _, err := m.Decode(); err == nil {
+			t.Errorf("%s: no error", mappings)
+		}
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	g := NewGenerator("out.js")
+	g.AddSource("a.js", "let foo = 1;\nfoo;\n")
+	// Out of order and duplicated, Map sorts and drops the copy
+	for _, i := range []int{3, 1, 0, 2, 1} {
+		g.AddMapping(knownMappings[i])
+	}
+	m := g.Map()
+	if !reflect.DeepEqual(m, known) {
+		t.Errorf("got %+v\nwant %+v", m, known)
+	}
+
+	data, err := m.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := Parse(data)
+	if err != nil || !reflect.DeepEqual(back, known) {
+		t.Errorf("parsed back as %+v, %v", back, err)
+	}
+	if _, err := Parse([]byte(`{"version":2,"sources":[],"names":[],"mappings":""}`)); err == nil {
+		t.Error("version 2 parsed without an error")
+	}
+}
+
+func TestOriginalPosition(t *testing.T) {
+	tests := []struct {
+		line, column int
+		want         int // Index in knownMappings, -1 for no mapping
+	}{
+		{0, 0, 0},
+		{0, 3, 0},
+		{0, 4, 1},
+		{0, 100, 1},
+		{1, 7, 2},
+		{2, 0, -1}, // No segment on the line
+		{3, 5, -1}, // A segment without a source
+		{9, 0, -1},
+	}
+	for _, test := range tests {
+		got, ok, err := known.OriginalPosition(test.line, test.column)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.want < 0 {
+			if ok {
+				t.Errorf("%d:%d: got %+v, want none", test.line, test.column, got)
+			}
+		} else if !ok || got != knownMappings[test.want] {
+			t.Errorf("%d:%d: got %+v, want %+v", test.line, test.column, got, knownMappings[test.want])
+		}
+	}
+}
+
+func TestCompose(t *testing.T) {
+	// inner maps mid.js, a reformatted a.js, back to a.js
+	innerGen := NewGenerator("mid.js")
+	innerGen.AddSource("a.js", "let foo = 1;\nfoo;\n")
+	innerGen.AddMapping(Mapping{GeneratedLine: 0, GeneratedColumn: 0, Source: "a.js", OriginalLine: 0, OriginalColumn: 0})
+	innerGen.AddMapping(Mapping{GeneratedLine: 0, GeneratedColumn: 4, Source: "a.js", OriginalLine: 0, OriginalColumn: 4, Name: "foo"})
+	innerGen.AddMapping(Mapping{GeneratedLine: 2, GeneratedColumn: 0, Source: "a.js", OriginalLine: 1, OriginalColumn: 0, Name: "foo"})
+	inner := innerGen.Map()
+
+	// outer maps out.js, a minified mid.js, to mid.js
+	outerGen := NewGenerator("out.js")
+	outerGen.AddMapping(Mapping{GeneratedLine: 0, GeneratedColumn: 0, Source: "mid.js", OriginalLine: 0, OriginalColumn: 0})
+	outerGen.AddMapping(Mapping{GeneratedLine: 0, GeneratedColumn: 4, Source: "mid.js", OriginalLine: 0, OriginalColumn: 4, Name: "a"})
+	outerGen.AddMapping(Mapping{GeneratedLine: 0, GeneratedColumn: 9, Source: "mid.js", OriginalLine: 1, OriginalColumn: 0}) // Nothing there in inner
+	outerGen.AddMapping(Mapping{GeneratedLine: 0, GeneratedColumn: 11, Source: "mid.js", OriginalLine: 2, OriginalColumn: 3, Name: "a"})
+	outerGen.AddMapping(Mapping{GeneratedLine: 0, GeneratedColumn: 13, Source: "other.js", OriginalLine: 5, OriginalColumn: 1})
+	outerGen.AddMapping(Mapping{GeneratedLine: 1, GeneratedColumn: 0})
+	outer := outerGen.Map()
+
+	composed, err := Compose(outer, inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := composed.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Mapping{
+		{GeneratedLine: 0, GeneratedColumn: 0, Source: "a.js", OriginalLine: 0, OriginalColumn: 0},
+		{GeneratedLine: 0, GeneratedColumn: 4, Source: "a.js", OriginalLine: 0, OriginalColumn: 4, Name: "foo"},
+		{GeneratedLine: 0, GeneratedColumn: 11, Source: "a.js", OriginalLine: 1, OriginalColumn: 0, Name: "foo"},
+		{GeneratedLine: 0, GeneratedColumn: 13, Source: "other.js", OriginalLine: 5, OriginalColumn: 1},
+		{GeneratedLine: 1, GeneratedColumn: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+	if composed.File != "out.js" || len(composed.SourcesContent) == 0 || composed.SourcesContent[0] != "let foo = 1;\nfoo;\n" {
+		t.Errorf("composed map has file %q and contents %q", composed.File, composed.SourcesContent)
+	}
+}
@@ -0,0 +1,61 @@
+package sourcemap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// base64Chars is the alphabet used by the Base64 VLQ encoding
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// VLQ digits carry 5 bits of the value, the 6th bit says whether more digits follow
+const (
+	vlqShift        = 5
+	vlqContinuation = 1 << vlqShift
+	vlqMask         = vlqContinuation - 1
+)
+
+// encodeVLQ appends the Base64 VLQ encoding of value to sb
+// The sign is stored in the lowest bit, so small negative deltas stay short
+func encodeVLQ(sb *strings.Builder, value int) {
+	v := value << 1
+	if value < 0 {
+		v = (-value << 1) | 1
+	}
+	for {
+		digit := v & vlqMask
+		v >>= vlqShift
+		if v > 0 {
+			digit |= vlqContinuation
+		}
+		sb.WriteByte(base64Chars[digit])
+		if v == 0 {
+			return
+		}
+	}
+}
+
+// decodeVLQ reads one Base64 VLQ value from s starting at i
+// It returns the value and the index just past it
+func decodeVLQ(s string, i int) (int, int, error) {
+	result, shift := 0, 0
+	for {
+		if i >= len(s) {
+			return 0, i, fmt.Errorf("sourcemap: unterminated VLQ value")
+		}
+		digit := strings.IndexByte(base64Chars, s[i])
+		if digit < 0 {
+			return 0, i, fmt.Errorf("sourcemap: invalid VLQ character %q", s[i])
+		}
+		i++
+		result += (digit & vlqMask) << shift
+		shift += vlqShift
+		if digit&vlqContinuation == 0 {
+			break
+		}
+	}
+	if result&1 == 1 {
+		return -(result >> 1), i, nil
+	}
+	return result >> 1, i, nil
+}
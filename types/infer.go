@@ -0,0 +1,334 @@
+package types
+
+import (
+	"fmt"
+
+	"goast/ast"
+	"goast/cfg"
+	"goast/scope"
+)
+
+// Info is the result of Infer
+type Info struct {
+	Types     map[ast.Node]Type                 // Type of every expression, and of the variable of every declaration
+	Variables map[*scope.Variable]Type          // Type of every declared variable, parameters and functions included
+	Returns   map[*ast.FunctionDeclaration]Type // Type of the values each function returns
+	Problems  []Problem                         // Suspicious operations, in source order
+}
+
+// Problem is an operation whose implicit conversions are likely a mistake
+type Problem struct {
+	Span    ast.Span
+	Message string
+}
+
+// globals are the types of the globals every environment provides; other undeclared names
+// are unknown
+var globals = map[string]Type{
+	"undefined": Undefined, "NaN": Number, "Infinity": Number,
+	"Math": Object, "JSON": Object, "console": Object, "globalThis": Object,
+	"Array": Function, "Boolean": Function, "Date": Function, "Error": Function, "Map": Function,
+	"Number": Function, "Object": Function, "Promise": Function, "Set": Function, "String": Function,
+	"isFinite": Function, "isNaN": Function, "parseFloat": Function, "parseInt": Function,
+}
+
+// calls are the types of the results of calling global functions
+var calls = map[string]Type{
+	"String": String, "Number": Number, "Boolean": Boolean, "Date": String,
+	"parseInt": Number, "parseFloat": Number, "isNaN": Boolean, "isFinite": Boolean,
+	"Array": Array, "Object": Object, "Error": Object,
+}
+
+// Infer computes the types of a program whose scopes have been analyzed
+//
+// Inference is flow-insensitive: a variable has the union of the types of every value
+// assigned to it, a let or var declared without a value including undefined. A parameter is
+// unknown unless it has a default value, whose type is taken as a hint of what callers pass.
+// A function returns the union of its return values, with undefined when control can reach
+// the end of its body. Types depend on each other through variables and calls, so they are
+// recomputed until none changes
+func Infer(program *ast.Program, scopes *scope.Analysis) *Info {
+	info := &Info{
+		Types:     map[ast.Node]Type{},
+		Variables: map[*scope.Variable]Type{},
+		Returns:   map[*ast.FunctionDeclaration]Type{},
+	}
+	c := &checker{info: info, scopes: scopes, falls: map[*ast.FunctionDeclaration]bool{}, declared: map[ast.Node]*scope.Variable{}}
+	for _, s := range scopes.Scopes {
+		for _, v := range s.Variables {
+			for _, decl := range v.Declarations {
+				c.declared[decl] = v
+			}
+		}
+	}
+	ast.Inspect(program, func(node ast.Node) bool {
+		if fn, ok := node.(*ast.FunctionDeclaration); ok {
+			c.falls[fn] = fallsOffEnd(cfg.New(fn))
+		}
+		return true
+	})
+	for c.changed = true; c.changed; {
+		c.changed = false
+		ast.Inspect(program, c.visit)
+	}
+	for decl, v := range c.declared {
+		info.Types[decl] = info.Variables[v]
+	}
+	c.report(program)
+	return info
+}
+
+// fallsOffEnd reports whether control can reach the end of a function's body
+func fallsOffEnd(g *cfg.Graph) bool {
+	reachable := g.Reachable()
+	for _, e := range g.Exit.Preds {
+		if e.Kind == cfg.Normal && reachable[e.From.Index] {
+			return true
+		}
+	}
+	return false
+}
+
+// Signature formats the type of a function, like "checkAge(age: unknown): string"
+func (info *Info) Signature(fn *ast.FunctionDeclaration) string {
+	s := fn.Name + "("
+	for i := range fn.Params {
+		if i > 0 {
+			s += ", "
+		}
+		param := &fn.Params[i]
+		s += param.Name + ": " + info.Types[param].String()
+	}
+	return s + "): " + info.Returns[fn].String()
+}
+
+// checker holds the state of Infer
+type checker struct {
+	info     *Info
+	scopes   *scope.Analysis
+	falls    map[*ast.FunctionDeclaration]bool // Functions whose end is reachable
+	declared map[ast.Node]*scope.Variable      // Variable each declaration declares
+	changed  bool                              // A type grew during the current round
+}
+
+// widen adds t to the type of a variable
+func (c *checker) widen(v *scope.Variable, t Type) {
+	if v == nil {
+		return
+	}
+	if old := c.info.Variables[v]; old|t != old {
+		c.info.Variables[v] = old | t
+		c.changed = true
+	}
+}
+
+// visit is the ast.Inspect callback of a round: it types the expressions of every statement
+// and widens the variables written and the function returns
+func (c *checker) visit(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.FunctionDeclaration:
+		c.widen(c.declared[n], Function)
+		var returns Type
+		if c.falls[n] {
+			returns = Undefined
+		}
+		ast.Inspect(n, func(inner ast.Node) bool {
+			switch r := inner.(type) {
+			case *ast.FunctionDeclaration:
+				return r == n // Nested functions return for themselves
+			case *ast.ReturnStatement:
+				if r.Argument == nil {
+					returns |= Undefined
+				} else {
+					returns |= c.expression(r.Argument)
+				}
+			}
+			return true
+		})
+		if c.info.Returns[n]|returns != c.info.Returns[n] {
+			c.info.Returns[n] |= returns
+			c.changed = true
+		}
+		for i := range n.Params {
+			param := &n.Params[i]
+			t := Unknown
+			if param.DefaultValue != nil {
+				t = c.expression(param.DefaultValue)
+			}
+			c.widen(c.declared[param], t)
+		}
+	case *ast.VariableDeclaration:
+		t := Undefined
+		if n.Value != nil {
+			t = c.expression(n.Value)
+		}
+		c.widen(c.declared[n], t)
+		return false
+	case *ast.IfStatement:
+		c.expression(n.Test)
+		for _, stmt := range n.Consequent {
+			ast.Inspect(stmt, c.visit)
+		}
+		return false
+	case *ast.ReturnStatement:
+		return false // Typed with the function
+	case *ast.ExpressionStatement:
+		c.expression(n.Expression)
+		return false
+	}
+	return true
+}
+
+// expression computes and records the type of an expression, widening the variables its
+// assignments write
+func (c *checker) expression(node ast.Node) Type {
+	if node == nil {
+		return Undefined
+	}
+	t := c.typeOf(node)
+	c.info.Types[node] = t
+	return t
+}
+
+// typeOf computes the type of an expression from the types of its parts
+func (c *checker) typeOf(node ast.Node) Type {
+	switch n := node.(type) {
+	case *ast.NumericLiteral:
+		return Number
+	case *ast.StringLiteral:
+		return String
+	case *ast.BooleanLiteral:
+		return Boolean
+	case *ast.NullLiteral:
+		return Null
+	case *ast.ArrayExpression:
+		for _, e := range n.Elements {
+			c.expression(e)
+		}
+		return Array
+	case *ast.ObjectExpression:
+		for i := range n.Properties {
+			c.expression(n.Properties[i].Value)
+		}
+		return Object
+	case *ast.Identifier:
+		ref := c.scopes.Reference(n)
+		if ref == nil || ref.Resolved == nil {
+			if t, ok := globals[n.Name]; ok {
+				return t
+			}
+			return Unknown
+		}
+		return c.info.Variables[ref.Resolved]
+	case *ast.BinaryExpression:
+		if n.Operator == "=" {
+			right := c.expression(n.Right)
+			if id, ok := n.Left.(*ast.Identifier); ok {
+				if ref := c.scopes.Reference(id); ref != nil {
+					c.widen(ref.Resolved, right)
+				}
+			}
+			c.expression(n.Left)
+			return right
+		}
+		return Binary(n.Operator, c.expression(n.Left), c.expression(n.Right))
+	case *ast.MemberExpression:
+		object := c.expression(n.Object)
+		c.expression(n.Index)
+		switch {
+		case n.Index == nil && n.Property == "length" && object.Is(String|Array):
+			return Number
+		case c.isGlobal(n.Object, "Math"):
+			return Number // Math has only numbers and functions returning numbers
+		}
+		return Unknown
+	case *ast.CallExpression:
+		callee := c.expression(n.Callee)
+		for _, arg := range n.Arguments {
+			c.expression(arg)
+		}
+		if callee == 0 {
+			return 0
+		}
+		switch f := n.Callee.(type) {
+		case *ast.Identifier:
+			if ref := c.scopes.Reference(f); ref != nil && ref.Resolved != nil {
+				if fn, ok := ref.Resolved.Declarations[0].(*ast.FunctionDeclaration); ok && ref.Resolved.Kind == "function" {
+					return c.info.Returns[fn]
+				}
+			} else if t, ok := calls[f.Name]; ok {
+				return t
+			}
+		case *ast.MemberExpression:
+			if c.isGlobal(f.Object, "Math") {
+				return Number
+			}
+		}
+		return Unknown
+	case *ast.NewExpression:
+		c.expression(n.Callee)
+		for _, arg := range n.Arguments {
+			c.expression(arg)
+		}
+		if c.isGlobal(n.Callee, "Array") {
+			return Array
+		}
+		return Object
+	}
+	return Unknown
+}
+
+// isGlobal reports whether an expression is the undeclared global name
+func (c *checker) isGlobal(node ast.Node, name string) bool {
+	id, ok := node.(*ast.Identifier)
+	if !ok || id.Name != name {
+		return false
+	}
+	ref := c.scopes.Reference(id)
+	return ref == nil || ref.Resolved == nil
+}
+
+// report lists the suspicious operations of a program once its types are known
+func (c *checker) report(program *ast.Program) {
+	ast.Inspect(program, func(node ast.Node) bool {
+		n, ok := node.(*ast.BinaryExpression)
+		if !ok {
+			return true
+		}
+		left, right := c.info.Types[n.Left], c.info.Types[n.Right]
+		if message := suspicious(n.Operator, left, right); message != "" {
+			c.info.Problems = append(c.info.Problems, Problem{Span: n.Span, Message: message})
+		}
+		return true
+	})
+}
+
+// suspicious describes what is wrong with applying an operator to operands of two types,
+// or returns "" if nothing is
+func suspicious(operator string, left, right Type) string {
+	if left == 0 || right == 0 || left == Unknown || right == Unknown {
+		return ""
+	}
+	switch operator {
+	case "===", "!==":
+		if left&right == 0 && left.Is(primitives) && right.Is(primitives) {
+			result := operator == "!=="
+			return fmt.Sprintf("Comparing %s with %s using '%s' is always %t", left, right, operator, result)
+		}
+	case "==", "!=", "<", ">", "<=", ">=":
+		if left.Is(String) && right.Is(Number) || left.Is(Number) && right.Is(String) {
+			return fmt.Sprintf("Comparing %s with %s using '%s' converts the string to a number", left, right, operator)
+		}
+	case "-", "*", "/", "%":
+		for _, t := range []Type{left, right} {
+			// Objects are left alone: their valueOf may give a number, as dates do
+			if t.Is(Undefined) {
+				return fmt.Sprintf("Arithmetic with '%s' on %s gives NaN", operator, t)
+			}
+			if t.Is(String) {
+				return fmt.Sprintf("Arithmetic with '%s' converts a string to a number", operator)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+// Package types infers the types of plain JavaScript programs
+// Infer gives every expression, variable and function return a Type, the set of kinds of
+// values it may have at run time, and reports operations whose coercions are likely mistakes
+package types
+
+import (
+	"strings"
+)
+
+// Type is a set of kinds of values: a union type
+// The zero Type, never, is the type of what has no value, like the result of a function
+// that never returns; Unknown holds every kind
+type Type uint16
+
+// Kinds of values, each a Type of its own
+const (
+	Undefined Type = 1 << iota
+	Null
+	Boolean
+	Number
+	String
+	Array
+	Function
+	Object // Objects other than arrays and functions
+
+	Unknown = Undefined | Null | Boolean | Number | String | Array | Function | Object
+)
+
+// kindNames are the names of the kinds, in the order String lists them: undefined and null
+// last, as TypeScript does
+var kindNames = []struct {
+	kind Type
+	name string
+}{
+	{Boolean, "boolean"}, {Number, "number"}, {String, "string"}, {Array, "array"},
+	{Function, "function"}, {Object, "object"}, {Undefined, "undefined"}, {Null, "null"},
+}
+
+// primitives are the kinds of values that are not objects
+const primitives = Undefined | Null | Boolean | Number | String
+
+// String formats a type like TypeScript: kinds joined by |, "unknown" for every kind and
+// "never" for none
+func (t Type) String() string {
+	switch t {
+	case 0:
+		return "never"
+	case Unknown:
+		return "unknown"
+	}
+	var names []string
+	for _, k := range kindNames {
+		if t&k.kind != 0 {
+			names = append(names, k.name)
+		}
+	}
+	return strings.Join(names, " | ")
+}
+
+// Is reports whether every value of t is of kind k, or of one of the kinds of k
+func (t Type) Is(k Type) bool {
+	return t != 0 && t&^k == 0
+}
+
+// May reports whether some values of t are of kind k, or of one of the kinds of k
+func (t Type) May(k Type) bool {
+	return t&k != 0
+}
+
+// kinds splits a type into its kinds
+func (t Type) kinds() []Type {
+	var kinds []Type
+	for k := Type(1); k <= Object; k <<= 1 {
+		if t&k != 0 {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds
+}
+
+// Plus returns the type of left + right
+// The operator concatenates strings as soon as one operand is a string once converted to a
+// primitive, which objects convert to unless their valueOf says otherwise, and adds numbers
+// otherwise: "a" + 1 is a string, true + 1 and null + 1 are numbers
+func Plus(left, right Type) Type {
+	var t Type
+	for _, l := range left.kinds() {
+		for _, r := range right.kinds() {
+			if (l|r)&^(primitives&^String) != 0 {
+				t |= String
+			} else {
+				t |= Number
+			}
+		}
+	}
+	return t
+}
+
+// Binary returns the type of a binary expression from the types of its operands
+// Arithmetic other than + always gives a number, comparisons a boolean and an assignment
+// the value assigned
+func Binary(operator string, left, right Type) Type {
+	if left == 0 || right == 0 {
+		return 0 // An operand never produces a value
+	}
+	switch operator {
+	case "+":
+		return Plus(left, right)
+	case "-", "*", "/", "%":
+		return Number
+	case "<", ">", "<=", ">=", "==", "!=", "===", "!==":
+		return Boolean
+	case "=":
+		return right
+	}
+	return Unknown
+}
@@ -0,0 +1,160 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"goast/ast"
+	"goast/parser"
+	"goast/scope"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		t    Type
+		want string
+	}{
+		{0, "never"},
+		{Unknown, "unknown"},
+		{Number, "number"},
+		{String | Number, "number | string"},
+		{Undefined | Null | Boolean, "boolean | undefined | null"},
+		{Array | Object | Function, "array | function | object"},
+	}
+	for _, test := range tests {
+		if got := test.t.String(); got != test.want {
+			t.Errorf("%d: %s, want %s", test.t, got, test.want)
+		}
+	}
+	if !Number.Is(Number|String) || (Number | String).Is(Number) || Type(0).Is(Number) {
+		t.Error("Is does not check that every kind is included")
+	}
+	if !(Number | String).May(String) || Number.May(String) {
+		t.Error("May does not check that some kind is included")
+	}
+}
+
+func TestBinary(t *testing.T) {
+	tests := []struct {
+		operator    string
+		left, right Type
+		want        Type
+	}{
+		{"+", Number, Number, Number},
+		{"+", String, Number, String},
+		{"+", Number, String, String},
+		{"+", Boolean, Null, Number},
+		{"+", Undefined, Number, Number},
+		{"+", Array, Number, String},
+		{"+", Object, Number, String},
+		{"+", Number | String, Number, Number | String},
+		{"-", String, String, Number},
+		{"%", Unknown, Unknown, Number},
+		{"<", String, Number, Boolean},
+		{"===", Number, Null, Boolean},
+		{"=", Number, String, String},
+		{"+", 0, Number, 0},
+		{"*", Number, 0, 0},
+	}
+	for _, test := range tests {
+		if got := Binary(test.operator, test.left, test.right); got != test.want {
+			t.Errorf("%s %s %s: %s, want %s", test.left, test.operator, test.right, got, test.want)
+		}
+	}
+}
+
+// program exercises inference through variables, calls, returns and defaults
+const program = `function add(a, b = 0) {
+  return a + b;
+}
+function label(n) {
+  if (n > 1) {
+    return "many";
+  }
+}
+function fail() {
+  return fail();
+}
+let count = 1;
+count = "one";
+var maybe;
+const list = [1, 2];
+const total = add(1, 2) - "x";
+const same = 1 === "1";
+const loose = "3" < 4;
+const nan = maybe * 2;
+const s = String(count) + 1;
+const obj = { a: 1 }.a;
+const when = new Date();
+const never = fail();
+`
+
+// infer parses and infers a program, failing the test on syntax errors
+func infer(t *testing.T, src string) (*ast.Program, *Info) {
+	t.Helper()
+	p, err := parser.ParseFile("types.js", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, Infer(p, scope.Analyze(p, nil))
+}
+
+func TestInfer(t *testing.T) {
+	p, info := infer(t, program)
+	got := map[string]string{}
+	for _, node := range p.Body {
+		switch n := node.(type) {
+		case *ast.FunctionDeclaration:
+			got[n.Name] = info.Signature(n)
+		case *ast.VariableDeclaration:
+			got[n.Name] = info.Types[n].String()
+		}
+	}
+	want := map[string]string{
+		"add":   "add(a: unknown, b: number): number | string", // a is unknown, so a + b may concatenate
+		"label": "label(n: unknown): string | undefined",       // Falls off its end
+		"fail":  "fail(): never",                               // Never returns
+		"count": "number | string",
+		"maybe": "undefined",
+		"list":  "array",
+		"total": "number",
+		"same":  "boolean",
+		"loose": "boolean",
+		"nan":   "number",
+		"s":     "string",
+		"obj":   "unknown",
+		"when":  "object",
+		"never": "never",
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s: %s, want %s", name, got[name], w)
+		}
+	}
+}
+
+func TestProblems(t *testing.T) {
+	_, info := infer(t, program)
+	lines := ast.NewLines(program)
+	var got []string
+	for _, p := range info.Problems {
+		line, column := lines.Position(p.Span.Start)
+		got = append(got, fmt.Sprintf("%d:%d: %s", line, column, p.Message))
+	}
+	want := []string{
+		"16:15: Arithmetic with '-' converts a string to a number",
+		"17:14: Comparing number with string using '===' is always false",
+		"18:15: Comparing string with number using '<' converts the string to a number",
+		"19:13: Arithmetic with '*' on undefined gives NaN",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Unknown operands and objects are given the benefit of the doubt
+	_, info = infer(t, "function f(x) {\n  return x - \"1\" + (new Date() - 1) + (x === 1);\n}\n")
+	if len(info.Problems) != 0 {
+		t.Errorf("got %v, want no problems", info.Problems)
+	}
+}
@@ -0,0 +1,80 @@
+package vm
+
+import (
+	"testing"
+
+	"goast/interp"
+	"goast/parser"
+)
+
+// benchmarks are programs timed on both engines; the language has no loops, so the work
+// is done by recursion
+var benchmarks = []struct {
+	name, src string
+}{
+	{"fib", `function fib(n) {
+  if (n < 2) {
+    return n;
+  }
+  return fib(n - 1) + fib(n - 2);
+}
+fib(20);
+`},
+	{"closures", `function counter() {
+  let count = 0;
+  function next() {
+    count = count + 1;
+    return count;
+  }
+  return next;
+}
+function repeat(f, n) {
+  if (n === 0) {
+    return 0;
+  }
+  f();
+  return repeat(f, n - 1);
+}
+repeat(counter(), 5000);
+`},
+	{"objects", `function build(n, acc) {
+  if (n === 0) {
+    return acc;
+  }
+  let item = { id: n, name: "item" + n, tags: [n, n * 2] };
+  acc.push(item);
+  return build(n - 1, acc);
+}
+build(2000, []);
+`},
+}
+
+// BenchmarkEngines runs each program on the tree-walking interpreter and on the VM, in a
+// fresh global scope every time; parsing and compiling are not timed
+// Compare the two with: go test -bench Engines ./vm
+func BenchmarkEngines(b *testing.B) {
+	for _, bench := range benchmarks {
+		program, err := parser.ParseFile(bench.name+".js", bench.src, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		code, err := Compile(program)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bench.name+"/interp", func(b *testing.B) {
+			for range b.N {
+				if _, err := interp.New(nil).Run(program); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(bench.name+"/vm", func(b *testing.B) {
+			for range b.N {
+				if _, err := New(nil).Run(code); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
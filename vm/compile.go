@@ -0,0 +1,600 @@
+// Package vm compiles programs to bytecode and runs them on a stack-based virtual machine
+// It is a faster alternative to the tree-walking interpreter of package interp, whose values
+// and coercions it shares: variables are resolved to numbered slots at compile time using
+// the scope analysis, so running code never looks names up except for globals
+package vm
+
+import (
+	"fmt"
+	"sort"
+
+	"goast/ast"
+	"goast/constant"
+	"goast/interp"
+	"goast/parser"
+	"goast/scope"
+)
+
+// maxOperand is the largest index or jump target an instruction can encode
+const maxOperand = 1<<16 - 1
+
+// Code is a compiled function, or the top level of a compiled program
+type Code struct {
+	Name         string
+	Params       int // Number of parameters, whose arguments arrive in the first local slots
+	Arity        int // Number of parameters before the first with a default value, the length
+	Instructions []byte
+	Constants    []interp.Value
+	Functions    []*Code   // Nested functions, instantiated by OpClosure
+	Locals       []Local   // Local variables by slot
+	Cells        []Local   // Local variables captured by nested functions, by cell index
+	Free         []Capture // Variables of enclosing functions the function uses, by OpGetFree index
+
+	spans []position // Source locations of the instructions that may fail, by offset
+	src   *source
+}
+
+// Local describes a local variable slot
+type Local struct {
+	Name    string
+	Lexical bool // let or const, unusable until its declaration runs
+}
+
+// Capture tells where a closure finds a variable of an enclosing function when it is created:
+// among the cells of the enclosing function, or among its own free variables when the
+// variable belongs to a function further out
+type Capture struct {
+	Name  string
+	Free  bool
+	Index int
+}
+
+// position maps an instruction offset to the source it was compiled from
+type position struct {
+	offset int
+	span   ast.Span
+}
+
+// source is the text a program was compiled from, for error positions
+type source struct {
+	filename string
+	text     string
+	lines    ast.Lines
+}
+
+// span returns the source location of the instruction at offset, if known
+func (c *Code) span(offset int) (ast.Span, bool) {
+	i := sort.Search(len(c.spans), func(i int) bool { return c.spans[i].offset >= offset })
+	if i < len(c.spans) && c.spans[i].offset == offset {
+		return c.spans[i].span, true
+	}
+	return ast.Span{}, false
+}
+
+// near returns the source location of the closest instruction at or before offset that has one,
+// to locate errors raised between the instructions that may fail, such as exhausted limits
+func (c *Code) near(offset int) (ast.Span, bool) {
+	i := sort.Search(len(c.spans), func(i int) bool { return c.spans[i].offset > offset })
+	if i == 0 {
+		return ast.Span{}, false
+	}
+	return c.spans[i-1].span, true
+}
+
+// CompileSource parses and compiles a program, keeping src to locate runtime errors
+// A syntax error fails the compilation with the parser's ErrorList, before any bytecode
+// is produced, whereas code the compiler rejects gives a SyntaxError *interp.Error
+func CompileSource(filename, src string) (*Code, error) {
+	program, err := parser.ParseFile(filename, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	return compile(program, &source{filename: filename, text: src, lines: ast.NewLines(src)})
+}
+
+// Compile compiles a program
+// Top-level declarations become globals of the VM running it; the code returns the value of
+// the last top-level expression statement, like interp.Interpreter.Run
+func Compile(program *ast.Program) (*Code, error) {
+	return compile(program, nil)
+}
+
+// compile compiles a program parsed from src
+// Problems are raised as *interp.Error panics while compiling and returned here
+func compile(program *ast.Program, src *source) (code *Code, err error) {
+	c := &compiler{analysis: scope.Analyze(program, nil), src: src}
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*interp.Error)
+			if !ok {
+				panic(r)
+			}
+			code, err = nil, e
+		}
+	}()
+	return c.program(program), nil
+}
+
+// compiler holds the state of a compilation
+type compiler struct {
+	analysis *scope.Analysis
+	src      *source
+	fn       *function // Function being compiled
+}
+
+// function is the compilation state of one Code
+type function struct {
+	code   *Code
+	parent *function
+	vars   map[*scope.Variable]location // Variables declared in the function
+	free   map[*scope.Variable]int      // Variables of enclosing functions, by free index
+	result int                          // Slot of the completion value at top level, -1 in functions
+
+	// With default values, parameters are in their dead zone until initialized in order, and
+	// need a slot of their own besides the one their argument arrives in
+	defaults bool
+}
+
+// location is where a variable lives at run time
+// The index is a slot, a cell, a free variable, or for globals the constant holding the name
+type location struct {
+	kind  locationKind
+	index int
+}
+
+type locationKind int
+
+const (
+	inGlobal locationKind = iota // Global variable, looked up by name
+	inLocal                      // Local slot
+	inCell                       // Cell of the function, shared with its closures
+	inFree                       // Cell of an enclosing function
+)
+
+// program compiles the top level of a program
+// Its own declarations are globals; those of the blocks of its if statements are locals
+func (c *compiler) program(program *ast.Program) *Code {
+	global := c.analysis.Global
+	c.fn = &function{
+		code: &Code{Name: "<main>", src: c.src},
+		vars: map[*scope.Variable]location{},
+		free: map[*scope.Variable]int{},
+	}
+	c.fn.result = c.local(Local{Name: "<result>"})
+	for _, child := range global.Children {
+		if child.Kind == scope.Block {
+			c.allocate(child)
+		}
+	}
+
+	// Hoisting: every global exists before the first statement runs, functions included
+	for _, v := range global.Variables {
+		kind := declareVar
+		switch v.Kind {
+		case "let":
+			kind = declareLet
+		case "const":
+			kind = declareConst
+		}
+		c.emit(OpDeclareGlobal, c.name(v.Name), int(kind))
+	}
+	c.statements(program.Body, global, true)
+
+	c.emit(OpGetLocal, c.fn.result)
+	c.emit(OpReturn)
+	return c.fn.code
+}
+
+// function compiles a function declaration
+// Arguments arrive in the first local slots; a prologue moves them to the parameters that
+// live elsewhere, replacing undefined by the default value
+func (c *compiler) function(decl *ast.FunctionDeclaration) *Code {
+	s := c.analysis.Scope(decl)
+	parent := c.fn
+	c.fn = &function{
+		code:   &Code{Name: decl.Name, Params: len(decl.Params), Arity: len(decl.Params), src: c.src},
+		parent: parent,
+		vars:   map[*scope.Variable]location{},
+		free:   map[*scope.Variable]int{},
+		result: -1,
+	}
+	defer func() { c.fn = parent }()
+
+	for i, param := range decl.Params {
+		c.local(Local{Name: param.Name})
+		if param.DefaultValue != nil && !c.fn.defaults {
+			c.fn.code.Arity = i
+		}
+		c.fn.defaults = c.fn.defaults || param.DefaultValue != nil
+	}
+	c.allocate(s)
+
+	for i, param := range decl.Params {
+		loc := c.fn.vars[s.Variable(param.Name)]
+		if loc == (location{inLocal, i}) {
+			continue // The argument slot is the parameter
+		}
+		c.emit(OpGetLocal, i)
+		if param.DefaultValue != nil {
+			c.emit(OpDup)
+			skip := c.emit(OpJumpIfNotUndefined, 0)
+			c.emit(OpPop)
+			c.expression(param.DefaultValue, s)
+			c.patch(skip)
+		}
+		c.store(loc, true, param.NameSpan)
+	}
+	c.statements(decl.Body, s, false)
+
+	c.emit(OpUndefined)
+	c.emit(OpReturn)
+	return c.fn.code
+}
+
+// allocate gives a slot or a cell to the variables of a function scope, or of a top-level
+// block, and of the blocks nested in it
+// A variable used by a nested function lives in a cell its closures share; parameters keep
+// the slot their argument arrives in unless they need a cell or have default values
+func (c *compiler) allocate(s *scope.Scope) {
+	for _, v := range s.Variables {
+		lexical := v.Kind == "let" || v.Kind == "const" || v.Kind == "parameter" && c.fn.defaults
+		switch {
+		case captured(v):
+			c.fn.code.Cells = append(c.fn.code.Cells, Local{Name: v.Name, Lexical: lexical})
+			c.fn.vars[v] = location{inCell, len(c.fn.code.Cells) - 1}
+		case v.Kind == "parameter" && !c.fn.defaults:
+			c.fn.vars[v] = location{inLocal, paramSlot(s, v.Name)}
+		default:
+			c.fn.vars[v] = location{inLocal, c.local(Local{Name: v.Name, Lexical: lexical})}
+		}
+	}
+	for _, child := range s.Children {
+		if child.Kind == scope.Block {
+			c.allocate(child)
+		}
+	}
+}
+
+// captured reports whether a variable is used by a function nested in the one declaring it
+func captured(v *scope.Variable) bool {
+	for _, ref := range v.References {
+		if ref.From.FunctionScope() != v.Scope.FunctionScope() {
+			return true
+		}
+	}
+	return false
+}
+
+// paramSlot returns the slot of a parameter, the last one of that name when it is repeated
+func paramSlot(s *scope.Scope, name string) int {
+	params := s.Node.(*ast.FunctionDeclaration).Params
+	for i := len(params) - 1; i >= 0; i-- {
+		if params[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// local adds a local slot and returns its index
+func (c *compiler) local(l Local) int {
+	c.fn.code.Locals = append(c.fn.code.Locals, l)
+	if len(c.fn.code.Locals) > maxOperand {
+		panic(c.errorf(nil, "too many local variables in %s", c.fn.code.Name))
+	}
+	return len(c.fn.code.Locals) - 1
+}
+
+// resolve returns where the variable v named name lives as seen from the function being
+// compiled, v being nil for undeclared globals
+// Variables of enclosing functions are captured, through the enclosing functions in between
+func (c *compiler) resolve(v *scope.Variable, name string) location {
+	if v == nil || v.Scope == c.analysis.Global {
+		return location{inGlobal, c.name(name)}
+	}
+	return c.resolveIn(c.fn, v)
+}
+
+func (c *compiler) resolveIn(fn *function, v *scope.Variable) location {
+	if loc, ok := fn.vars[v]; ok {
+		return loc
+	}
+	if i, ok := fn.free[v]; ok {
+		return location{inFree, i}
+	}
+	outer := c.resolveIn(fn.parent, v)
+	capture := Capture{Name: v.Name, Index: outer.index}
+	switch outer.kind {
+	case inFree:
+		capture.Free = true
+	case inCell:
+	default:
+		panic(fmt.Sprintf("vm: captured variable %s has no cell", v.Name)) // allocate gives them one
+	}
+	fn.code.Free = append(fn.code.Free, capture)
+	fn.free[v] = len(fn.code.Free) - 1
+	return location{inFree, len(fn.code.Free) - 1}
+}
+
+// statements compiles a statement list belonging to scope s
+// The functions it declares are created first, as JavaScript hoists them to the top of their block
+func (c *compiler) statements(list []ast.Node, s *scope.Scope, top bool) {
+	for _, stmt := range list {
+		if decl, ok := stmt.(*ast.FunctionDeclaration); ok {
+			c.emit(OpClosure, c.nested(decl))
+			c.store(c.resolve(s.Variable(decl.Name), decl.Name), true, decl.NameSpan)
+		}
+	}
+	for _, stmt := range list {
+		c.statement(stmt, s, top)
+	}
+}
+
+// nested compiles a function declared in the current one and returns its index
+func (c *compiler) nested(decl *ast.FunctionDeclaration) int {
+	code := c.fn.code
+	code.Functions = append(code.Functions, c.function(decl))
+	if len(code.Functions) > maxOperand {
+		panic(c.errorf(decl, "too many functions in %s", code.Name))
+	}
+	return len(code.Functions) - 1
+}
+
+// statement compiles one statement of scope s; top is set for the top level of the program,
+// where expression statements give the completion value
+func (c *compiler) statement(node ast.Node, s *scope.Scope, top bool) {
+	switch n := node.(type) {
+	case *ast.Comment, *ast.FunctionDeclaration:
+		// Functions were created at the start of their block
+
+	case *ast.VariableDeclaration:
+		if n.Value == nil {
+			if n.Kind == "var" {
+				break // Hoisted as undefined already
+			}
+			c.emit(OpUndefined)
+		} else {
+			c.expression(n.Value, s)
+		}
+		c.store(c.resolve(s.Lookup(n.Name), n.Name), true, n.NameSpan)
+
+	case *ast.IfStatement:
+		c.expression(n.Test, s)
+		skip := c.emit(OpJumpIfFalse, 0)
+		c.statements(n.Consequent, c.analysis.Scope(n), false)
+		c.patch(skip)
+
+	case *ast.ReturnStatement:
+		if n.Argument != nil {
+			c.expression(n.Argument, s)
+		} else {
+			c.emit(OpUndefined)
+		}
+		c.emit(OpReturn)
+
+	case *ast.ExpressionStatement:
+		c.expression(n.Expression, s)
+		if top {
+			c.emit(OpInitLocal, c.fn.result)
+		} else {
+			c.emit(OpPop)
+		}
+
+	case *ast.ErrorNode:
+		panic(c.errorf(n, "%s", n.Message))
+
+	default:
+		panic(c.errorf(node, "unsupported statement %s", node.Type()))
+	}
+}
+
+// expression compiles an expression of scope s, leaving its value on the stack
+func (c *compiler) expression(node ast.Node, s *scope.Scope) {
+	switch n := node.(type) {
+	case *ast.NumericLiteral:
+		c.emit(OpConstant, c.constant(interp.Number(constant.StringToNumber(n.Value))))
+	case *ast.StringLiteral:
+		c.emit(OpConstant, c.constant(interp.String(n.Value)))
+	case *ast.BooleanLiteral:
+		c.emit(OpConstant, c.constant(interp.Boolean(n.Value)))
+	case *ast.NullLiteral:
+		c.emit(OpConstant, c.constant(interp.Null{}))
+
+	case *ast.Identifier:
+		c.load(c.resolve(c.variable(n), n.Name), n.Span)
+
+	case *ast.BinaryExpression:
+		if member, ok := n.Left.(*ast.MemberExpression); ok && n.Operator == "=" {
+			c.expression(member.Object, s)
+			if member.Index != nil {
+				c.expression(member.Index, s)
+				c.expression(n.Right, s)
+				c.emitAt(member.Span, OpSetIndex)
+				break
+			}
+			c.expression(n.Right, s)
+			c.emitAt(member.Span, OpSetProperty, c.name(member.Property))
+			break
+		}
+		if n.Operator == "=" {
+			id, ok := n.Left.(*ast.Identifier)
+			if !ok {
+				panic(c.errorf(n.Left, "Invalid left-hand side in assignment"))
+			}
+			c.expression(n.Right, s)
+			c.emit(OpDup)
+			if v := c.variable(id); v != nil && v.Kind == "const" {
+				c.emitAt(id.Span, OpConstAssign, c.name(id.Name))
+				break
+			}
+			c.store(c.resolve(c.variable(id), id.Name), false, id.Span)
+			break
+		}
+		op, ok := binaryOpcodes[n.Operator]
+		if !ok {
+			panic(c.errorf(n, "unsupported operator %q", n.Operator))
+		}
+		c.expression(n.Left, s)
+		c.expression(n.Right, s)
+		c.emitAt(n.Span, op)
+
+	case *ast.CallExpression:
+		if len(n.Arguments) > 255 {
+			panic(c.errorf(n, "too many arguments"))
+		}
+		// A method call keeps the object below the callee as the receiver
+		member, method := n.Callee.(*ast.MemberExpression)
+		if method {
+			c.expression(member.Object, s)
+			c.emit(OpDup)
+			c.property(member, s)
+		} else {
+			c.expression(n.Callee, s)
+		}
+		for _, arg := range n.Arguments {
+			c.expression(arg, s)
+		}
+		if method {
+			c.emitAt(n.Callee.Range(), OpCallMethod, len(n.Arguments))
+		} else {
+			c.emitAt(n.Callee.Range(), OpCall, len(n.Arguments))
+		}
+
+	case *ast.MemberExpression:
+		c.expression(n.Object, s)
+		c.property(n, s)
+
+	case *ast.NewExpression:
+		if len(n.Arguments) > 255 {
+			panic(c.errorf(n, "too many arguments"))
+		}
+		c.expression(n.Callee, s)
+		for _, arg := range n.Arguments {
+			c.expression(arg, s)
+		}
+		c.emitAt(n.Callee.Range(), OpNew, len(n.Arguments))
+
+	case *ast.ArrayExpression:
+		if len(n.Elements) > maxOperand {
+			panic(c.errorf(n, "too many elements"))
+		}
+		for _, element := range n.Elements {
+			c.expression(element, s)
+		}
+		c.emitAt(n.Span, OpArray, len(n.Elements))
+
+	case *ast.ObjectExpression:
+		c.emitAt(n.Span, OpObject)
+		for _, p := range n.Properties {
+			c.expression(p.Value, s)
+			c.emitAt(p.Span, OpDefineProperty, c.name(p.Key))
+		}
+
+	case *ast.InvalidExpression:
+		panic(c.errorf(n, "invalid expression"))
+
+	default:
+		panic(c.errorf(node, "unsupported expression %s", node.Type()))
+	}
+}
+
+// property compiles the access of a member expression to a property of the object on the
+// stack, computing the key of obj[key]
+func (c *compiler) property(n *ast.MemberExpression, s *scope.Scope) {
+	if n.Index != nil {
+		c.expression(n.Index, s)
+		c.emitAt(n.Span, OpGetIndex)
+		return
+	}
+	c.emitAt(n.Span, OpGetProperty, c.name(n.Property))
+}
+
+// variable returns the variable an identifier refers to, nil for an undeclared global
+func (c *compiler) variable(id *ast.Identifier) *scope.Variable {
+	if ref := c.analysis.Reference(id); ref != nil {
+		return ref.Resolved
+	}
+	return nil
+}
+
+// Instructions reading, assigning and initializing variables, by location
+// Declarations always initialize variables of their own function, never free ones
+var (
+	getOps  = [...]Opcode{inGlobal: OpGetGlobal, inLocal: OpGetLocal, inCell: OpGetCell, inFree: OpGetFree}
+	setOps  = [...]Opcode{inGlobal: OpSetGlobal, inLocal: OpSetLocal, inCell: OpSetCell, inFree: OpSetFree}
+	initOps = [...]Opcode{inGlobal: OpInitGlobal, inLocal: OpInitLocal, inCell: OpInitCell, inFree: OpSetFree}
+)
+
+// load pushes a variable; span locates the identifier for dead zone and undefined errors
+func (c *compiler) load(loc location, span ast.Span) {
+	c.emitAt(span, getOps[loc.kind], loc.index)
+}
+
+// store pops the top of the stack into a variable; init is set for declarations, which end
+// the temporal dead zone instead of failing in it
+func (c *compiler) store(loc location, init bool, span ast.Span) {
+	if init {
+		c.emitAt(span, initOps[loc.kind], loc.index)
+	} else {
+		c.emitAt(span, setOps[loc.kind], loc.index)
+	}
+}
+
+// emit appends an instruction to the function being compiled and returns its offset
+func (c *compiler) emit(op Opcode, operands ...int) int {
+	code := c.fn.code
+	offset := len(code.Instructions)
+	code.Instructions = encode(code.Instructions, op, operands...)
+	return offset
+}
+
+// emitAt appends an instruction that may fail at run time, recording where it comes from
+func (c *compiler) emitAt(span ast.Span, op Opcode, operands ...int) int {
+	offset := c.emit(op, operands...)
+	if span.IsValid() {
+		c.fn.code.spans = append(c.fn.code.spans, position{offset, span})
+	}
+	return offset
+}
+
+// patch points the jump at offset to the next instruction
+func (c *compiler) patch(offset int) {
+	target := len(c.fn.code.Instructions)
+	if target > maxOperand {
+		panic(c.errorf(nil, "%s is too long", c.fn.code.Name))
+	}
+	// Encoding over the old instruction rewrites its operand in place
+	encode(c.fn.code.Instructions[:offset], Opcode(c.fn.code.Instructions[offset]), target)
+}
+
+// constant adds a value to the constant pool, unless it is there already, and returns its index
+func (c *compiler) constant(v interp.Value) int {
+	code := c.fn.code
+	for i, k := range code.Constants {
+		if k == v {
+			return i
+		}
+	}
+	code.Constants = append(code.Constants, v)
+	if len(code.Constants) > maxOperand+1 {
+		panic(c.errorf(nil, "too many constants in %s", code.Name))
+	}
+	return len(code.Constants) - 1
+}
+
+// name adds a variable name to the constant pool
+func (c *compiler) name(name string) int {
+	return c.constant(interp.String(name))
+}
+
+// errorf builds the SyntaxError of code that cannot be compiled, located at node
+func (c *compiler) errorf(node ast.Node, format string, args ...any) *interp.Error {
+	e := &interp.Error{Name: "SyntaxError", Message: fmt.Sprintf(format, args...)}
+	if c.src != nil {
+		e.Filename = c.src.filename
+		if node != nil && node.Range().IsValid() {
+			e.Line, e.Column = c.src.lines.Position(node.Range().Start)
+		}
+	}
+	return e
+}
@@ -0,0 +1,65 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"goast/interp"
+)
+
+// Disassemble lists the instructions of compiled code in readable form, followed by those of
+// its nested functions
+// Operands are explained in a comment: the constant, variable or function they refer to
+func Disassemble(code *Code) string {
+	var sb strings.Builder
+	disassemble(&sb, code, code.Name)
+	return sb.String()
+}
+
+// disassemble writes one function under a path naming it through its enclosing functions
+func disassemble(sb *strings.Builder, code *Code, path string) {
+	fmt.Fprintf(sb, "== %s (params %d, locals %d, cells %d, free %d) ==\n",
+		path, code.Params, len(code.Locals), len(code.Cells), len(code.Free))
+	for pc := 0; pc < len(code.Instructions); {
+		op := Opcode(code.Instructions[pc])
+		operands, next := decode(code.Instructions, pc)
+
+		line := fmt.Sprintf("%04d %s", pc, op)
+		for _, operand := range operands {
+			line += fmt.Sprintf(" %d", operand)
+		}
+		if comment := explain(code, op, operands); comment != "" {
+			line = fmt.Sprintf("%-32s ; %s", line, comment)
+		}
+		sb.WriteString(line + "\n")
+		pc = next
+	}
+	for _, fn := range code.Functions {
+		sb.WriteString("\n")
+		disassemble(sb, fn, path+"."+fn.Name)
+	}
+}
+
+// explain describes what the operands of an instruction refer to
+func explain(code *Code, op Opcode, operands []int) string {
+	switch op {
+	case OpConstant:
+		return interp.Inspect(code.Constants[operands[0]])
+	case OpGetLocal, OpSetLocal, OpInitLocal:
+		return code.Locals[operands[0]].Name
+	case OpGetCell, OpSetCell, OpInitCell:
+		return code.Cells[operands[0]].Name
+	case OpGetFree, OpSetFree:
+		return code.Free[operands[0]].Name
+	case OpGetGlobal, OpSetGlobal, OpInitGlobal, OpConstAssign:
+		return interp.ToString(code.Constants[operands[0]])
+	case OpGetProperty, OpSetProperty, OpDefineProperty:
+		return "." + interp.ToString(code.Constants[operands[0]])
+	case OpDeclareGlobal:
+		kind := [...]string{declareVar: "var", declareLet: "let", declareConst: "const"}[operands[1]]
+		return kind + " " + interp.ToString(code.Constants[operands[0]])
+	case OpClosure:
+		return "function " + code.Functions[operands[0]].Name
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package vm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"goast/interp"
+)
+
+// TestLimits runs programs exceeding each limit on the interpreter and on the VM and checks
+// that both stop them with the error of that limit
+func TestLimits(t *testing.T) {
+	recursion := "function f(n) {\n  return f(n + 1);\n}\nf(0);\n"
+	tests := []struct {
+		name   string
+		src    string
+		limits interp.Limits
+		want   error // nil for a program that runs to its end
+	}{
+		{"steps", "function f(n) {\n  if (n === 0) {\n    return 0;\n  }\n  return f(n - 1);\n}\nf(100);\n", interp.Limits{MaxSteps: 50}, interp.ErrStepLimit},
+		{"steps enough", "let a = 1;\nlet b = a + 1;\n", interp.Limits{MaxSteps: 1000}, nil},
+		{"string length", "let s = \"abc\";\nlet t = s + s;\nlet u = t + t;\n", interp.Limits{MaxStringLength: 10}, interp.ErrStringLimit},
+		{"string length enough", "let s = \"abc\";\nlet t = s + s;\n", interp.Limits{MaxStringLength: 10}, nil},
+		{"string repeat", "\"ab\".repeat(100);\n", interp.Limits{MaxStringLength: 10}, interp.ErrStringLimit},
+		{"depth", recursion, interp.Limits{MaxCallDepth: 100}, interp.ErrCallDepth},
+		{"default depth", recursion, interp.Limits{}, interp.ErrCallDepth},
+		{"huge depth", recursion, interp.Limits{MaxCallDepth: 1000000000}, interp.ErrCallDepth}, // Capped, not a Go stack overflow
+		{"depth enough", "function f(n) {\n  if (n === 0) {\n    return 0;\n  }\n  return f(n - 1);\n}\nf(99);\n", interp.Limits{MaxCallDepth: 101}, nil},
+	}
+	engines := []struct {
+		name string
+		run  func(src string, limits interp.Limits) error
+	}{
+		{"interp", func(src string, limits interp.Limits) error {
+			_, err := interp.New(&interp.Options{Limits: limits}).RunSource("limits.js", src)
+			return err
+		}},
+		{"vm", func(src string, limits interp.Limits) error {
+			code, err := CompileSource("limits.js", src)
+			if err != nil {
+				return err
+			}
+			_, err = New(&Options{Limits: limits}).Run(code)
+			return err
+		}},
+	}
+	for _, test := range tests {
+		for _, engine := range engines {
+			err := engine.run(test.src, test.limits)
+			switch {
+			case test.want == nil && err != nil:
+				t.Errorf("%s on %s: %v", test.name, engine.name, err)
+			case test.want != nil && !errors.Is(err, test.want):
+				t.Errorf("%s on %s: got %v, want %v", test.name, engine.name, err, test.want)
+			case test.want != nil && !strings.HasPrefix(err.Error(), "limits.js:"):
+				t.Errorf("%s on %s: %v has no position", test.name, engine.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Opcode is the first byte of an instruction
+type Opcode byte
+
+// Instructions of the virtual machine
+// Operands follow the opcode, big-endian, with the widths given by their definition
+const (
+	OpConstant  Opcode = iota // index: push a constant
+	OpUndefined               // push undefined
+	OpPop                     // discard the top of the stack
+	OpDup                     // push the top of the stack again
+
+	OpGetLocal  // slot: push a local variable
+	OpSetLocal  // slot: pop into a local variable, which must be initialized
+	OpInitLocal // slot: pop into a local variable, ending its temporal dead zone
+	OpGetCell   // cell: push a local variable captured by a closure
+	OpSetCell   // cell: pop into a captured local variable, which must be initialized
+	OpInitCell  // cell: pop into a captured local variable, ending its temporal dead zone
+	OpGetFree   // index: push a variable of an enclosing function
+	OpSetFree   // index: pop into a variable of an enclosing function
+
+	OpGetGlobal     // name: push a global variable
+	OpSetGlobal     // name: pop into a global variable, creating it if needed
+	OpInitGlobal    // name: pop into a global variable, ending its temporal dead zone
+	OpDeclareGlobal // name, kind: declare a global variable before the program runs
+	OpConstAssign   // name: throw the TypeError of an assignment to a constant
+
+	OpGetProperty // name: pop an object, push its property
+	OpSetProperty // name: pop a value and an object, assign the property and push the value back
+	OpGetIndex    // pop a key and an object, push the property the key names
+	OpSetIndex    // pop a value, a key and an object, assign the property and push the value back
+
+	OpAdd
+	OpSubtract
+	OpMultiply
+	OpDivide
+	OpRemainder
+	OpLess
+	OpGreater
+	OpLessEqual
+	OpGreaterEqual
+	OpEqual
+	OpNotEqual
+	OpStrictEqual
+	OpStrictNotEqual
+
+	OpJumpIfFalse        // target: pop, continue at target if the value is falsy
+	OpJumpIfNotUndefined // target: pop, continue at target unless the value is undefined
+
+	OpCall       // count: pop count arguments and the callee, push the result of the call
+	OpCallMethod // count: pop count arguments, the callee and its receiver, push the result of the call
+	OpReturn     // pop the result and return it to the caller
+	OpClosure    // function: push a closure of a nested function
+	OpNew        // count: pop count arguments and the constructor, push the object it creates
+
+	OpArray          // count: pop count elements, push an array of them
+	OpObject         // push an empty object
+	OpDefineProperty // name: pop a value and define it as a property of the object below
+)
+
+// Kinds of global declarations, the operand of OpDeclareGlobal
+const (
+	declareVar byte = iota
+	declareLet
+	declareConst
+)
+
+// definition describes the encoding of an opcode
+type definition struct {
+	name     string
+	operands []int // Width in bytes of each operand
+}
+
+var definitions = map[Opcode]definition{
+	OpConstant:  {"CONSTANT", []int{2}},
+	OpUndefined: {"UNDEFINED", nil},
+	OpPop:       {"POP", nil},
+	OpDup:       {"DUP", nil},
+
+	OpGetLocal:  {"GET_LOCAL", []int{2}},
+	OpSetLocal:  {"SET_LOCAL", []int{2}},
+	OpInitLocal: {"INIT_LOCAL", []int{2}},
+	OpGetCell:   {"GET_CELL", []int{2}},
+	OpSetCell:   {"SET_CELL", []int{2}},
+	OpInitCell:  {"INIT_CELL", []int{2}},
+	OpGetFree:   {"GET_FREE", []int{2}},
+	OpSetFree:   {"SET_FREE", []int{2}},
+
+	OpGetGlobal:     {"GET_GLOBAL", []int{2}},
+	OpSetGlobal:     {"SET_GLOBAL", []int{2}},
+	OpInitGlobal:    {"INIT_GLOBAL", []int{2}},
+	OpDeclareGlobal: {"DECLARE_GLOBAL", []int{2, 1}},
+	OpConstAssign:   {"CONST_ASSIGN", []int{2}},
+
+	OpGetProperty: {"GET_PROPERTY", []int{2}},
+	OpSetProperty: {"SET_PROPERTY", []int{2}},
+	OpGetIndex:    {"GET_INDEX", nil},
+	OpSetIndex:    {"SET_INDEX", nil},
+
+	OpAdd:            {"ADD", nil},
+	OpSubtract:       {"SUBTRACT", nil},
+	OpMultiply:       {"MULTIPLY", nil},
+	OpDivide:         {"DIVIDE", nil},
+	OpRemainder:      {"REMAINDER", nil},
+	OpLess:           {"LESS", nil},
+	OpGreater:        {"GREATER", nil},
+	OpLessEqual:      {"LESS_EQUAL", nil},
+	OpGreaterEqual:   {"GREATER_EQUAL", nil},
+	OpEqual:          {"EQUAL", nil},
+	OpNotEqual:       {"NOT_EQUAL", nil},
+	OpStrictEqual:    {"STRICT_EQUAL", nil},
+	OpStrictNotEqual: {"STRICT_NOT_EQUAL", nil},
+
+	OpJumpIfFalse:        {"JUMP_IF_FALSE", []int{2}},
+	OpJumpIfNotUndefined: {"JUMP_IF_NOT_UNDEFINED", []int{2}},
+
+	OpCall:       {"CALL", []int{1}},
+	OpCallMethod: {"CALL_METHOD", []int{1}},
+	OpReturn:     {"RETURN", nil},
+	OpClosure:    {"CLOSURE", []int{2}},
+	OpNew:        {"NEW", []int{1}},
+
+	OpArray:          {"ARRAY", []int{2}},
+	OpObject:         {"OBJECT", nil},
+	OpDefineProperty: {"DEFINE_PROPERTY", []int{2}},
+}
+
+// binaryOpcodes maps binary operators to the instruction applying them
+var binaryOpcodes = map[string]Opcode{
+	"+": OpAdd, "-": OpSubtract, "*": OpMultiply, "/": OpDivide, "%": OpRemainder,
+	"<": OpLess, ">": OpGreater, "<=": OpLessEqual, ">=": OpGreaterEqual,
+	"==": OpEqual, "!=": OpNotEqual, "===": OpStrictEqual, "!==": OpStrictNotEqual,
+}
+
+// binaryOperators maps the instructions applying binary operators back to the operator
+var binaryOperators [256]string
+
+func init() {
+	for operator, op := range binaryOpcodes {
+		binaryOperators[op] = operator
+	}
+}
+
+func (op Opcode) String() string {
+	if def, ok := definitions[op]; ok {
+		return def.name
+	}
+	return fmt.Sprintf("OP_%d", byte(op))
+}
+
+// encode appends an instruction to code
+func encode(code []byte, op Opcode, operands ...int) []byte {
+	code = append(code, byte(op))
+	for i, width := range definitions[op].operands {
+		switch width {
+		case 1:
+			code = append(code, byte(operands[i]))
+		case 2:
+			code = binary.BigEndian.AppendUint16(code, uint16(operands[i]))
+		}
+	}
+	return code
+}
+
+// decode reads the operands of the instruction at pc and returns them with the offset of the next one
+func decode(code []byte, pc int) ([]int, int) {
+	def := definitions[Opcode(code[pc])]
+	operands := make([]int, len(def.operands))
+	next := pc + 1
+	for i, width := range def.operands {
+		switch width {
+		case 1:
+			operands[i] = int(code[next])
+		case 2:
+			operands[i] = int(binary.BigEndian.Uint16(code[next:]))
+		}
+		next += width
+	}
+	return operands, next
+}
@@ -0,0 +1,528 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"goast/interp"
+)
+
+// Options configures a VM
+// A nil *Options is valid
+type Options struct {
+	// Stdout and Stderr receive the output of console.log and console.error,
+	// os.Stdout and os.Stderr when nil
+	Stdout, Stderr io.Writer
+
+	// Limits bounds the resources programs may use, as for the interpreter
+	Limits interp.Limits
+
+	// Now and Location are the clock and time zone of Date, as for the interpreter
+	Now      func() time.Time
+	Location *time.Location
+}
+
+// VM runs compiled code with a global scope kept from one run to the next
+type VM struct {
+	globals map[string]*global
+	depth   int // Nesting of function calls
+	meter   *interp.Meter
+	rt      *interp.Runtime
+	active  bool // Set while code runs, so calls back from native functions share its limits
+}
+
+// global is a global variable
+type global struct {
+	value       interp.Value
+	constant    bool
+	initialized bool // False in the temporal dead zone of let and const
+}
+
+// closure is a function with the cells of the enclosing functions it uses
+type closure struct {
+	code *Code
+	free []*cell
+}
+
+// cell holds a variable shared between a function and its closures
+type cell struct {
+	value interp.Value
+}
+
+// hole marks a let or const variable in its temporal dead zone
+type hole struct{}
+
+func (hole) TypeOf() string { return "undefined" }
+
+// New creates a VM with a fresh global scope
+func New(opts *Options) *VM {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	vm := &VM{globals: map[string]*global{}, meter: interp.NewMeter(o.Limits)}
+	vm.globals["undefined"] = &global{value: interp.Undefined{}, constant: true, initialized: true}
+	vm.globals["NaN"] = &global{value: interp.Number(math.NaN()), constant: true, initialized: true}
+	vm.globals["Infinity"] = &global{value: interp.Number(math.Inf(1)), constant: true, initialized: true}
+	vm.rt = interp.NewRuntime(&interp.Options{Stdout: o.Stdout, Stderr: o.Stderr, Now: o.Now, Location: o.Location}, vm.meter)
+	for name, v := range vm.rt.Globals {
+		vm.globals[name] = &global{value: v, initialized: true}
+	}
+	return vm
+}
+
+// Run runs the code of a program and returns its completion value
+// Runtime errors are *interp.Error values, as with the interpreter
+func (vm *VM) Run(code *Code) (_ interp.Value, err error) {
+	defer vm.begin()(&err)
+	return vm.execute(&closure{code: code}, nil)
+}
+
+// begin starts the counters of the limits over when code starts running from Go, and
+// returns the function that runs the promise jobs queued meanwhile at the end of the run
+// Their error is the run's if it had none
+func (vm *VM) begin() func(err *error) {
+	if vm.active {
+		return func(*error) {}
+	}
+	vm.meter.Reset()
+	vm.active = true
+	return func(err *error) {
+		defer func() { vm.active = false }()
+		if jobsErr := vm.rt.RunMicrotasks(); *err == nil {
+			*err = jobsErr
+		}
+	}
+}
+
+// SetLimits replaces the limits of Options, for the runs and calls that follow
+func (vm *VM) SetLimits(limits interp.Limits) {
+	vm.meter.SetLimits(limits)
+}
+
+// Get returns the value of a global variable, false if it does not exist or is not initialized yet
+func (vm *VM) Get(name string) (interp.Value, bool) {
+	g := vm.globals[name]
+	if g == nil || !g.initialized {
+		return nil, false
+	}
+	return g.value, true
+}
+
+// Set assigns a global variable, declaring it with var if needed
+func (vm *VM) Set(name string, v interp.Value) {
+	if g := vm.globals[name]; g != nil {
+		g.value, g.initialized = v, true
+		return
+	}
+	vm.globals[name] = &global{value: v, initialized: true}
+}
+
+// Define converts a Go value with interp.ToValue and assigns it to a global variable,
+// declaring it with var if needed
+// A Go function defined this way takes the name of the variable
+func (vm *VM) Define(name string, x any) error {
+	v, err := interp.ToValue(x)
+	if err != nil {
+		return err
+	}
+	if f, ok := v.(*interp.Function); ok && f.Name == "" {
+		f.Name = name
+	}
+	vm.Set(name, v)
+	return nil
+}
+
+// Invoke calls the global function name with arguments converted by interp.ToValue
+func (vm *VM) Invoke(name string, args ...any) (interp.Value, error) {
+	fn, ok := vm.Get(name)
+	if !ok {
+		return nil, &interp.Error{Name: "ReferenceError", Message: name + " is not defined"}
+	}
+	f, ok := fn.(*interp.Function)
+	if !ok {
+		return nil, &interp.Error{Name: "TypeError", Message: interp.Inspect(fn) + " is not a function"}
+	}
+	values := make([]interp.Value, len(args))
+	for i, arg := range args {
+		var err error
+		if values[i], err = interp.ToValue(arg); err != nil {
+			return nil, err
+		}
+	}
+	return f.Call(interp.Undefined{}, values...)
+}
+
+// function wraps a closure in a function value
+// Calling it from Go or from compiled code runs the closure on this VM
+func (vm *VM) function(c *closure) *interp.Function {
+	f := interp.NewNativeFunction(c.code.Name, func(this interp.Value, args []interp.Value) (_ interp.Value, err error) {
+		defer vm.begin()(&err) // Called from Go
+		if err := vm.meter.Call(vm.depth); err != nil {
+			return nil, err
+		}
+		vm.depth++
+		defer func() { vm.depth-- }()
+		return vm.execute(c, args)
+	})
+	f.Length = c.code.Arity
+	return f
+}
+
+// execute runs a closure in a new call frame and returns its result
+// The frame has the local slots of the function, parameters first, and its cells
+func (vm *VM) execute(c *closure, args []interp.Value) (result interp.Value, err error) {
+	code := c.code
+	ins := code.Instructions
+	locals := make([]interp.Value, len(code.Locals))
+	for i, l := range code.Locals {
+		switch {
+		case i < code.Params && i < len(args):
+			locals[i] = args[i]
+		case l.Lexical:
+			locals[i] = hole{}
+		default:
+			locals[i] = interp.Undefined{}
+		}
+	}
+	cells := make([]*cell, len(code.Cells))
+	for i, l := range code.Cells {
+		cells[i] = &cell{value: interp.Undefined{}}
+		if l.Lexical {
+			cells[i].value = hole{}
+		}
+	}
+	stack := make([]interp.Value, 0, 16)
+
+	pc := 0
+	// Errors raised without a position are located at the instruction that failed
+	defer func() {
+		if e, ok := err.(*interp.Error); ok && e.Line == 0 {
+			vm.locate(e, code, pc)
+		}
+	}()
+
+	for {
+		if err := vm.meter.Step(); err != nil {
+			return nil, err
+		}
+		op := Opcode(ins[pc])
+		start := pc
+		operand := 0
+		switch firstWidth[op] {
+		case 1:
+			operand = int(ins[pc+1])
+		case 2:
+			operand = int(ins[pc+1])<<8 | int(ins[pc+2])
+		}
+		pc += 1 + operandWidth[op]
+
+		switch op {
+		case OpConstant:
+			stack = append(stack, code.Constants[operand])
+		case OpUndefined:
+			stack = append(stack, interp.Undefined{})
+		case OpPop:
+			stack = stack[:len(stack)-1]
+		case OpDup:
+			stack = append(stack, stack[len(stack)-1])
+
+		case OpGetLocal:
+			v := locals[operand]
+			if _, ok := v.(hole); ok {
+				pc = start
+				return nil, deadZone(code.Locals[operand].Name)
+			}
+			stack = append(stack, v)
+		case OpSetLocal:
+			if _, ok := locals[operand].(hole); ok {
+				pc = start
+				return nil, deadZone(code.Locals[operand].Name)
+			}
+			locals[operand] = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		case OpInitLocal:
+			locals[operand] = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+		case OpGetCell, OpGetFree:
+			cl, name := vm.cell(op, operand, cells, c)
+			if _, ok := cl.value.(hole); ok {
+				pc = start
+				return nil, deadZone(name)
+			}
+			stack = append(stack, cl.value)
+		case OpSetCell, OpSetFree:
+			cl, name := vm.cell(op, operand, cells, c)
+			if _, ok := cl.value.(hole); ok {
+				pc = start
+				return nil, deadZone(name)
+			}
+			cl.value = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		case OpInitCell:
+			cells[operand].value = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+		case OpGetGlobal:
+			name := string(code.Constants[operand].(interp.String))
+			g := vm.globals[name]
+			switch {
+			case g == nil:
+				pc = start
+				return nil, &interp.Error{Name: "ReferenceError", Message: name + " is not defined"}
+			case !g.initialized:
+				pc = start
+				return nil, deadZone(name)
+			}
+			stack = append(stack, g.value)
+		case OpSetGlobal, OpInitGlobal:
+			name := string(code.Constants[operand].(interp.String))
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			g := vm.globals[name]
+			switch {
+			case g == nil:
+				vm.globals[name] = &global{value: v, initialized: true} // Implicit global, as in sloppy mode
+			case op == OpInitGlobal:
+				g.value, g.initialized = v, true
+			case !g.initialized:
+				pc = start
+				return nil, deadZone(name)
+			case g.constant:
+				pc = start
+				return nil, &interp.Error{Name: "TypeError", Message: "Assignment to constant variable."}
+			default:
+				g.value = v
+			}
+		case OpDeclareGlobal:
+			name := string(code.Constants[operand].(interp.String))
+			kind := ins[start+3]
+			if g := vm.globals[name]; kind == declareVar && g != nil {
+				break // Redeclaring a var keeps its value
+			}
+			g := &global{value: interp.Undefined{}, constant: kind == declareConst, initialized: kind == declareVar}
+			vm.globals[name] = g
+		case OpConstAssign:
+			pc = start
+			return nil, &interp.Error{Name: "TypeError", Message: "Assignment to constant variable."}
+
+		case OpAdd:
+			n := len(stack)
+			v := interp.Add(stack[n-2], stack[n-1])
+			if s, ok := v.(interp.String); ok {
+				if err := vm.meter.String(s); err != nil {
+					pc = start
+					return nil, err
+				}
+			}
+			stack[n-2] = v
+			stack = stack[:n-1]
+		case OpSubtract, OpMultiply, OpDivide, OpRemainder, OpLess, OpGreater, OpLessEqual,
+			OpGreaterEqual, OpEqual, OpNotEqual, OpStrictEqual, OpStrictNotEqual:
+			n := len(stack)
+			v, err := interp.BinaryOperation(binaryOperators[op], stack[n-2], stack[n-1])
+			if err != nil {
+				pc = start
+				return nil, err
+			}
+			stack[n-2] = v
+			stack = stack[:n-1]
+
+		case OpJumpIfFalse:
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !interp.ToBoolean(v) {
+				pc = operand
+			}
+		case OpJumpIfNotUndefined:
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if _, ok := v.(interp.Undefined); !ok {
+				pc = operand
+			}
+
+		case OpGetProperty:
+			n := len(stack)
+			v, err := vm.rt.GetProperty(stack[n-1], string(code.Constants[operand].(interp.String)))
+			if err != nil {
+				pc = start
+				return nil, err
+			}
+			stack[n-1] = v
+		case OpSetProperty:
+			n := len(stack)
+			key := string(code.Constants[operand].(interp.String))
+			if err := vm.meter.Grow(stack[n-2], key, stack[n-1]); err != nil {
+				pc = start
+				return nil, err
+			}
+			if err := interp.SetProperty(stack[n-2], key, stack[n-1]); err != nil {
+				pc = start
+				return nil, err
+			}
+			stack[n-2] = stack[n-1]
+			stack = stack[:n-1]
+
+		case OpGetIndex:
+			n := len(stack)
+			v, err := vm.rt.GetProperty(stack[n-2], interp.ToString(stack[n-1]))
+			if err != nil {
+				pc = start
+				return nil, err
+			}
+			stack[n-2] = v
+			stack = stack[:n-1]
+		case OpSetIndex:
+			n := len(stack)
+			key := interp.ToString(stack[n-2])
+			if err := vm.meter.Grow(stack[n-3], key, stack[n-1]); err != nil {
+				pc = start
+				return nil, err
+			}
+			if err := interp.SetProperty(stack[n-3], key, stack[n-1]); err != nil {
+				pc = start
+				return nil, err
+			}
+			stack[n-3] = stack[n-1]
+			stack = stack[:n-2]
+
+		case OpArray:
+			if err := vm.meter.Allocate(operand + 1); err != nil {
+				pc = start
+				return nil, err
+			}
+			n := len(stack)
+			a := interp.NewArray(stack[n-operand:]...)
+			stack = append(stack[:n-operand], a)
+		case OpObject:
+			if err := vm.meter.Allocate(1); err != nil {
+				pc = start
+				return nil, err
+			}
+			stack = append(stack, interp.NewObject())
+		case OpDefineProperty:
+			n := len(stack)
+			key := string(code.Constants[operand].(interp.String))
+			if err := vm.meter.Grow(stack[n-2], key, stack[n-1]); err != nil {
+				pc = start
+				return nil, err
+			}
+			stack[n-2].(*interp.Object).Set(key, stack[n-1])
+			stack = stack[:n-1]
+
+		case OpCall, OpCallMethod:
+			n := len(stack)
+			callee := stack[n-operand-1]
+			args := append([]interp.Value(nil), stack[n-operand:]...)
+			stack = stack[:n-operand-1]
+			var this interp.Value = interp.Undefined{}
+			if op == OpCallMethod {
+				this = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+			f, ok := callee.(*interp.Function)
+			if !ok {
+				pc = start
+				return nil, &interp.Error{Name: "TypeError", Message: vm.describe(code, start, callee) + " is not a function"}
+			}
+			v, err := f.Call(this, args...)
+			if err != nil {
+				pc = start
+				return nil, err
+			}
+			stack = append(stack, v)
+		case OpNew:
+			n := len(stack)
+			callee := stack[n-operand-1]
+			args := append([]interp.Value(nil), stack[n-operand:]...)
+			stack = stack[:n-operand-1]
+			f, ok := callee.(*interp.Function)
+			if !ok || f.Construct == nil {
+				pc = start
+				return nil, &interp.Error{Name: "TypeError", Message: vm.describe(code, start, callee) + " is not a constructor"}
+			}
+			if err := vm.meter.Call(vm.depth); err != nil {
+				pc = start
+				return nil, err
+			}
+			vm.depth++
+			v, err := f.Construct(args)
+			vm.depth--
+			if err != nil {
+				pc = start
+				return nil, err
+			}
+			stack = append(stack, v)
+		case OpReturn:
+			return stack[len(stack)-1], nil
+		case OpClosure:
+			if err := vm.meter.Allocate(1); err != nil {
+				pc = start
+				return nil, err
+			}
+			fn := code.Functions[operand]
+			nc := &closure{code: fn, free: make([]*cell, len(fn.Free))}
+			for i, capture := range fn.Free {
+				if capture.Free {
+					nc.free[i] = c.free[capture.Index]
+				} else {
+					nc.free[i] = cells[capture.Index]
+				}
+			}
+			stack = append(stack, vm.function(nc))
+
+		default:
+			return nil, fmt.Errorf("vm: unknown opcode %d at %d", op, start)
+		}
+	}
+}
+
+// Widths of the operands of each opcode, in arrays as the definitions map is too slow to consult
+// for every instruction: the first operand, and all of them together
+var firstWidth, operandWidth [256]int
+
+func init() {
+	for op, def := range definitions {
+		for i, width := range def.operands {
+			if i == 0 {
+				firstWidth[op] = width
+			}
+			operandWidth[op] += width
+		}
+	}
+}
+
+// cell returns the cell an instruction refers to with its variable name
+func (vm *VM) cell(op Opcode, index int, cells []*cell, c *closure) (*cell, string) {
+	if op == OpGetFree || op == OpSetFree {
+		return c.free[index], c.code.Free[index].Name
+	}
+	return cells[index], c.code.Cells[index].Name
+}
+
+// deadZone returns the error of using a let or const variable before its declaration
+func deadZone(name string) *interp.Error {
+	return &interp.Error{Name: "ReferenceError", Message: "Cannot access '" + name + "' before initialization"}
+}
+
+// describe names the callee of the call at offset for error messages, from the source when known
+func (vm *VM) describe(code *Code, offset int, callee interp.Value) string {
+	if span, ok := code.span(offset); ok && code.src != nil && span.End <= len(code.src.text) {
+		return code.src.text[span.Start:span.End]
+	}
+	return interp.Inspect(callee)
+}
+
+// locate sets the position of an error to the source of the instruction at offset
+func (vm *VM) locate(e *interp.Error, code *Code, offset int) {
+	if code.src == nil {
+		return
+	}
+	e.Filename = code.src.filename
+	if span, ok := code.near(offset); ok {
+		e.Line, e.Column = code.src.lines.Position(span.Start)
+	}
+}
@@ -0,0 +1,82 @@
+package vm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"goast/interp"
+)
+
+// expectedOutput returns the output listed in the comments after "// Expected output" at the end
+// of an example
+func expectedOutput(src string) (string, bool) {
+	_, after, ok := strings.Cut(src, "\n// Expected output")
+	if !ok {
+		return "", false
+	}
+	var out strings.Builder
+	for _, line := range strings.Split(after, "\n")[1:] {
+		if line == "" {
+			continue
+		}
+		out.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "//"), " ") + "\n")
+	}
+	return out.String(), true
+}
+
+// TestStdlibExamples runs each examples/stdlib program on the interpreter and on the VM and
+// compares what they print with the output listed in the program's comments
+// The clock is the one the examples document, 2024-05-01T12:00:00Z, in UTC
+func TestStdlibExamples(t *testing.T) {
+	files, err := filepath.Glob("../examples/stdlib/*.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no examples found")
+	}
+	now := func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	engines := []struct {
+		name string
+		run  func(filename, src string, out *strings.Builder) error
+	}{
+		{"interp", func(filename, src string, out *strings.Builder) error {
+			in := interp.New(&interp.Options{Stdout: out, Stderr: out, Now: now, Location: time.UTC})
+			_, err := in.RunSource(filename, src)
+			return err
+		}},
+		{"vm", func(filename, src string, out *strings.Builder) error {
+			code, err := CompileSource(filename, src)
+			if err != nil {
+				return err
+			}
+			_, err = New(&Options{Stdout: out, Stderr: out, Now: now, Location: time.UTC}).Run(code)
+			return err
+		}},
+	}
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, ok := expectedOutput(string(src))
+		if !ok {
+			t.Errorf("%s: no expected output", file)
+			continue
+		}
+		for _, engine := range engines {
+			var out strings.Builder
+			if err := engine.run(filepath.Base(file), string(src), &out); err != nil {
+				t.Errorf("%s on %s: %v", file, engine.name, err)
+				continue
+			}
+			if got := out.String(); got != want {
+				t.Errorf("%s on %s prints\n%s\nwant\n%s", file, engine.name, got, want)
+			}
+		}
+	}
+}